@@ -1,13 +1,37 @@
 package ast
 
+import (
+	"sort"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/token"
+)
+
 // Node is the base interface for all AST nodes.
 type Node interface {
 	TokenLiteral() string
+	Location() Loc
+}
+
+// Position is a location in GraphQL source.
+type Position = token.Position
+
+// Loc is the source span of a node. Nodes produced by the parser have both
+// positions set; nodes built in code have the zero Loc.
+type Loc struct {
+	Start Position // Position of the node's first character
+	End   Position // Position just past the node's last character
+}
+
+// Location returns the span, so that nodes embedding Loc implement Node.
+func (l Loc) Location() Loc {
+	return l
 }
 
 // Document represents a complete GraphQL document.
 // It contains a list of definitions (operations or type definitions).
 type Document struct {
+	Loc         // Source span
 	Definitions []Definition
 }
 
@@ -19,6 +43,28 @@ func (d *Document) TokenLiteral() string {
 	return ""
 }
 
+// defaultRootTypeNames maps operation types to the root type names used
+// when a document has no schema definition.
+var defaultRootTypeNames = map[string]string{
+	"query":        "Query",
+	"mutation":     "Mutation",
+	"subscription": "Subscription",
+}
+
+// RootTypeName returns the name of the root type for operation ("query",
+// "mutation" or "subscription"). It is taken from the document's schema
+// definition if there is one, and is empty if that definition does not
+// declare the operation; otherwise the conventional name such as "Query"
+// is returned.
+func (d *Document) RootTypeName(operation string) string {
+	for _, def := range d.Definitions {
+		if schema, ok := def.(*SchemaDefinition); ok {
+			return schema.RootTypeName(operation)
+		}
+	}
+	return defaultRootTypeNames[operation]
+}
+
 // Definition is an interface for all top-level definitions in a GraphQL document.
 type Definition interface {
 	Node
@@ -26,9 +72,11 @@ type Definition interface {
 
 // OperationDefinition represents a GraphQL operation (query, mutation, or subscription).
 type OperationDefinition struct {
+	Loc                                      // Source span
 	Operation           string               // "query", "mutation", or "subscription"
 	Name                string               // Optional operation name
 	VariableDefinitions []VariableDefinition // Variable definitions for this operation
+	Directives          []*Directive         // Directives applied to the operation
 	SelectionSet        *SelectionSet        // The fields to select
 }
 
@@ -40,10 +88,27 @@ func (op *OperationDefinition) TokenLiteral() string {
 	return op.Operation
 }
 
+// FragmentDefinition represents a named fragment
+// (e.g., "fragment UserFields on User { ... }").
+type FragmentDefinition struct {
+	Loc                         // Source span
+	Name          string        // Fragment name
+	TypeCondition string        // Type the fragment applies to
+	Directives    []*Directive  // Directives applied to the fragment
+	SelectionSet  *SelectionSet // The fields to select
+}
+
+// TokenLiteral returns the fragment name.
+func (f *FragmentDefinition) TokenLiteral() string {
+	return f.Name
+}
+
 // VariableDefinition represents a variable definition in an operation.
 type VariableDefinition struct {
-	Variable string // Variable name (without $)
-	Type     Type   // The type of the variable
+	Loc                 // Source span
+	Variable     string // Variable name (without $)
+	Type         Type   // The type of the variable
+	DefaultValue *Value // Default value, or nil if none is declared
 }
 
 // TokenLiteral returns the variable name.
@@ -53,14 +118,42 @@ func (v *VariableDefinition) TokenLiteral() string {
 
 // Type represents a GraphQL type (e.g., String, [Int!], User).
 type Type struct {
+	Loc            // Source span
 	Name    string // Base type name
 	NonNull bool   // Whether the type is non-nullable (!)
 	IsList  bool   // Whether the type is a list ([])
 	Elem    *Type  // Element type if this is a list
 }
 
+// NamedType returns the innermost named type, unwrapping lists.
+func (t *Type) NamedType() string {
+	for t != nil && t.IsList {
+		t = t.Elem
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name
+}
+
+// String renders the type in GraphQL notation (e.g., "[Int!]!").
+func (t *Type) String() string {
+	if t == nil {
+		return ""
+	}
+	s := t.Name
+	if t.IsList {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
 // SelectionSet represents a set of fields to select.
 type SelectionSet struct {
+	Loc        // Source span
 	Selections []Selection
 }
 
@@ -69,11 +162,48 @@ type Selection interface {
 	Node
 }
 
+// FragmentSpread represents a named fragment spread (e.g., "...UserFields").
+type FragmentSpread struct {
+	Loc                     // Source span
+	Name       string       // Name of the spread fragment
+	Directives []*Directive // Directives applied to the spread
+}
+
+// TokenLiteral returns the fragment name.
+func (fs *FragmentSpread) TokenLiteral() string {
+	return fs.Name
+}
+
+// InlineFragment represents an inline fragment (e.g., "... on Admin { ... }").
+// An empty TypeCondition applies to any type.
+type InlineFragment struct {
+	Loc                         // Source span
+	TypeCondition string        // Type the fragment applies to, if any
+	Directives    []*Directive  // Directives applied to the fragment
+	SelectionSet  *SelectionSet // Selections of the fragment
+}
+
+// TokenLiteral returns the inline fragment's type condition.
+func (i *InlineFragment) TokenLiteral() string {
+	return i.TypeCondition
+}
+
 // Field represents a single field selection in a GraphQL query.
+// In SDL type definitions it represents a field definition instead.
 type Field struct {
+	Loc                        // Source span
+	Alias        string        // Response key given in the query, or "" if none
 	Name         string        // Field name
 	Arguments    []Argument    // Field arguments
+	Directives   []*Directive  // Directives applied to the field or definition
 	SelectionSet *SelectionSet // Nested selections (if any)
+
+	// Definition metadata (SDL type definitions only)
+	Description         string                  // Description, or "" if none
+	Type                *Type                   // Declared field type
+	ArgumentDefinitions []*InputValueDefinition // Declared arguments
+	Deprecated          bool                    // Whether the field is marked @deprecated
+	DeprecationReason   string                  // Reason given by @deprecated, if deprecated
 }
 
 // TokenLiteral returns the field name.
@@ -81,8 +211,29 @@ func (f *Field) TokenLiteral() string {
 	return f.Name
 }
 
+// ResponseKey returns the key of the field in the response: its alias, or
+// its name if it has none.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Directive returns the directive named name applied to f, or nil if f has
+// none.
+func (f *Field) Directive(name string) *Directive {
+	for _, d := range f.Directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
 // Argument represents an argument passed to a field.
 type Argument struct {
+	Loc          // Source span
 	Name  string // Argument name
 	Value *Value // Argument value
 }
@@ -92,9 +243,78 @@ func (a *Argument) TokenLiteral() string {
 	return a.Name
 }
 
+// Directive represents a directive application such as @cost(weight: 5).
+type Directive struct {
+	Loc                  // Source span
+	Name      string     // Directive name (without @)
+	Arguments []Argument // Directive arguments
+}
+
+// TokenLiteral returns the directive name.
+func (d *Directive) TokenLiteral() string {
+	return d.Name
+}
+
+// String renders the directive application (e.g., "@cost(weight: 5)").
+func (d *Directive) String() string {
+	if len(d.Arguments) == 0 {
+		return "@" + d.Name
+	}
+	var args []string
+	for _, arg := range d.Arguments {
+		args = append(args, arg.Name+": "+arg.Value.String())
+	}
+	return "@" + d.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// DefaultDeprecationReason is the reason of a @deprecated directive that
+// does not give one.
+const DefaultDeprecationReason = "No longer supported"
+
+// Deprecation reports whether directives include @deprecated and, if so,
+// the reason it gives or DefaultDeprecationReason.
+func Deprecation(directives []*Directive) (bool, string) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		if reason := d.Argument("reason"); reason != nil && reason.Kind == "String" {
+			return true, reason.Literal
+		}
+		return true, DefaultDeprecationReason
+	}
+	return false, ""
+}
+
+// Argument returns the value of the named argument, or nil if it is absent.
+func (d *Directive) Argument(name string) *Value {
+	for _, arg := range d.Arguments {
+		if arg.Name == name {
+			return arg.Value
+		}
+	}
+	return nil
+}
+
+// InputValueDefinition represents a declared argument in an SDL field
+// definition or a field of an input object type.
+type InputValueDefinition struct {
+	Loc                 // Source span
+	Description  string // Description, or "" if none
+	Name         string // Argument name
+	Type         *Type  // Argument type
+	DefaultValue *Value // Default value, or nil if none is declared
+}
+
+// TokenLiteral returns the argument name.
+func (iv *InputValueDefinition) TokenLiteral() string {
+	return iv.Name
+}
+
 // Value represents a value in GraphQL (string, int, variable, object, array, etc.).
 type Value struct {
-	Kind         string            // "Int", "String", "Boolean", "Variable", "Enum", "Object", "Array"
+	Loc                            // Source span
+	Kind         string            // "Int", "Float", "String", "Boolean", "Null", "Variable", "Enum", "Object", "Array"
 	Literal      string            // The literal value
 	ObjectFields map[string]*Value // For object values
 	List         []*Value          // For array values
@@ -105,13 +325,215 @@ func (v *Value) TokenLiteral() string {
 	return v.Literal
 }
 
+// String renders the value as a GraphQL literal; a nil value renders as
+// null. Object fields are printed in alphabetical order because their
+// source order is not retained.
+func (v *Value) String() string {
+	if v == nil {
+		return "null"
+	}
+	switch v.Kind {
+	case "String":
+		return `"` + v.Literal + `"`
+	case "Variable":
+		return "$" + v.Literal
+	case "Object":
+		keys := make([]string, 0, len(v.ObjectFields))
+		for key := range v.ObjectFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var fields []string
+		for _, key := range keys {
+			fields = append(fields, key+": "+v.ObjectFields[key].String())
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case "Array":
+		var elems []string
+		for _, elem := range v.List {
+			elems = append(elems, elem.String())
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	default:
+		return v.Literal
+	}
+}
+
 // TypeDefinition represents a type definition in a GraphQL schema (e.g., "type Query { ... }").
 type TypeDefinition struct {
-	Name   string   // Type name
-	Fields []*Field // Fields in this type
+	Loc                  // Source span
+	Description string   // Description, or "" if none
+	Name        string   // Type name
+	Interfaces  []string // Names of the implemented interfaces
+	Fields      []*Field // Fields in this type
 }
 
 // TokenLiteral returns the type name.
 func (t *TypeDefinition) TokenLiteral() string {
 	return t.Name
 }
+
+// Field returns the field of t named name, or nil if t has none.
+func (t *TypeDefinition) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InterfaceTypeDefinition represents an interface type definition
+// (e.g., "interface Node { id: ID! }").
+type InterfaceTypeDefinition struct {
+	Loc                  // Source span
+	Description string   // Description, or "" if none
+	Name        string   // Type name
+	Interfaces  []string // Names of the implemented interfaces
+	Fields      []*Field // Fields of the interface
+}
+
+// TokenLiteral returns the type name.
+func (t *InterfaceTypeDefinition) TokenLiteral() string {
+	return t.Name
+}
+
+// TypeExtension represents a type extension (e.g., "extend type Query { ... }").
+// Definition holds what the extension adds, parsed like a type definition
+// of the same kind: a *TypeDefinition, *InterfaceTypeDefinition,
+// *UnionTypeDefinition, *EnumTypeDefinition, *InputObjectTypeDefinition or
+// *ScalarTypeDefinition. MergeExtensions folds extensions into the types
+// they extend.
+type TypeExtension struct {
+	Loc        // Source span
+	Definition Definition
+}
+
+// TokenLiteral returns the name of the extended type.
+func (e *TypeExtension) TokenLiteral() string {
+	return e.Definition.TokenLiteral()
+}
+
+// DirectiveDefinition represents a directive definition
+// (e.g., "directive @auth(role: String!) on FIELD_DEFINITION").
+type DirectiveDefinition struct {
+	Loc                                 // Source span
+	Description string                  // Description, or "" if none
+	Name        string                  // Directive name (without @)
+	Arguments   []*InputValueDefinition // Declared arguments
+	Repeatable  bool                    // Whether the directive may be repeated
+	Locations   []string                // Locations, e.g. "FIELD_DEFINITION"
+}
+
+// TokenLiteral returns the directive name.
+func (d *DirectiveDefinition) TokenLiteral() string {
+	return d.Name
+}
+
+// SchemaDefinition represents a schema definition declaring the root
+// operation types (e.g., "schema { query: RootQuery }").
+type SchemaDefinition struct {
+	Loc                                       // Source span
+	Description    string                     // Description, or "" if none
+	Directives     []*Directive               // Directives applied to the schema
+	OperationTypes []*OperationTypeDefinition // Declared root operation types
+}
+
+// TokenLiteral returns "schema".
+func (s *SchemaDefinition) TokenLiteral() string {
+	return "schema"
+}
+
+// RootTypeName returns the root type declared for operation, or "" if none is.
+func (s *SchemaDefinition) RootTypeName(operation string) string {
+	for _, ot := range s.OperationTypes {
+		if ot.Operation == operation {
+			return ot.Type
+		}
+	}
+	return ""
+}
+
+// OperationTypeDefinition represents a root operation type in a schema
+// definition (e.g., "query: RootQuery").
+type OperationTypeDefinition struct {
+	Loc              // Source span
+	Operation string // "query", "mutation", or "subscription"
+	Type      string // Name of the root type
+}
+
+// TokenLiteral returns the operation.
+func (o *OperationTypeDefinition) TokenLiteral() string {
+	return o.Operation
+}
+
+// ScalarTypeDefinition represents a custom scalar definition
+// (e.g., "scalar DateTime").
+type ScalarTypeDefinition struct {
+	Loc                      // Source span
+	Description string       // Description, or "" if none
+	Name        string       // Type name
+	Directives  []*Directive // Directives applied to the scalar
+}
+
+// TokenLiteral returns the type name.
+func (t *ScalarTypeDefinition) TokenLiteral() string {
+	return t.Name
+}
+
+// UnionTypeDefinition represents a union type definition
+// (e.g., "union SearchResult = User | Post").
+type UnionTypeDefinition struct {
+	Loc                  // Source span
+	Description string   // Description, or "" if none
+	Name        string   // Type name
+	Types       []string // Names of the member types
+}
+
+// TokenLiteral returns the type name.
+func (t *UnionTypeDefinition) TokenLiteral() string {
+	return t.Name
+}
+
+// EnumTypeDefinition represents an enum type definition
+// (e.g., "enum Role { ADMIN USER }").
+type EnumTypeDefinition struct {
+	Loc                                // Source span
+	Description string                 // Description, or "" if none
+	Name        string                 // Type name
+	Values      []*EnumValueDefinition // Values of the enum
+}
+
+// TokenLiteral returns the type name.
+func (t *EnumTypeDefinition) TokenLiteral() string {
+	return t.Name
+}
+
+// EnumValueDefinition represents a value declared by an enum type.
+type EnumValueDefinition struct {
+	Loc                            // Source span
+	Description       string       // Description, or "" if none
+	Name              string       // Value name
+	Directives        []*Directive // Directives applied to the value
+	Deprecated        bool         // Whether the value is marked @deprecated
+	DeprecationReason string       // Reason given by @deprecated, if deprecated
+}
+
+// TokenLiteral returns the value name.
+func (v *EnumValueDefinition) TokenLiteral() string {
+	return v.Name
+}
+
+// InputObjectTypeDefinition represents an input object type definition
+// (e.g., "input UpdateUserInput { name: String! }").
+type InputObjectTypeDefinition struct {
+	Loc                                 // Source span
+	Description string                  // Description, or "" if none
+	Name        string                  // Type name
+	Fields      []*InputValueDefinition // Input fields of the type
+}
+
+// TokenLiteral returns the type name.
+func (t *InputObjectTypeDefinition) TokenLiteral() string {
+	return t.Name
+}
@@ -0,0 +1,139 @@
+package ast
+
+import "reflect"
+
+// Clone returns a deep copy of the document that can be modified without
+// affecting d.
+func (d *Document) Clone() *Document { return clone(d) }
+
+// Clone returns a deep copy of the operation.
+func (op *OperationDefinition) Clone() *OperationDefinition { return clone(op) }
+
+// Clone returns a deep copy of the fragment definition.
+func (f *FragmentDefinition) Clone() *FragmentDefinition { return clone(f) }
+
+// Clone returns a deep copy of the selection set.
+func (ss *SelectionSet) Clone() *SelectionSet { return clone(ss) }
+
+// Clone returns a deep copy of the field.
+func (f *Field) Clone() *Field { return clone(f) }
+
+// Clone returns a deep copy of the value.
+func (v *Value) Clone() *Value { return clone(v) }
+
+// clone deep-copies an AST value. Nodes form a tree, so shared pointers
+// are not preserved.
+func clone[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src)
+	return dst.Interface().(T)
+}
+
+// deepCopy copies src into dst, which must be settable and of the same type.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			deepCopy(dst.Field(i), src.Field(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			deepCopy(elem, iter.Value())
+			dst.SetMapIndex(iter.Key(), elem)
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// locType is the type of the source spans embedded in nodes.
+var locType = reflect.TypeOf(Loc{})
+
+// Equal reports whether a and b are structurally equal nodes. Unlike
+// reflect.DeepEqual it ignores source positions and does not distinguish
+// nil from empty lists and maps, so a parsed document equals one built in
+// code or printed and parsed again.
+func Equal(a, b Node) bool {
+	return equal(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
+}
+
+// equal compares two values of the same type.
+func equal(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equal(a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equal(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).Type == locType {
+				continue
+			}
+			if !equal(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equal(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !equal(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
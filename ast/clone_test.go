@@ -0,0 +1,46 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestCloneAndEqual(t *testing.T) {
+	src := `query Q($id: ID = 1) { user(id: $id, where: {tags: ["a"]}) @include(if: true) { ...F ... on Admin { role } } }
+fragment F on User { name }
+type User { name: String @deprecated }`
+	doc := parser.New(lexer.New(src)).ParseDocument()
+
+	copied := doc.Clone()
+	if !ast.Equal(doc, copied) {
+		t.Fatal("expected the clone to equal the original")
+	}
+	user := copied.Definitions[0].(*ast.OperationDefinition).SelectionSet.Selections[0].(*ast.Field)
+	user.Name = "account"
+	user.Arguments[1].Value.ObjectFields["tags"].List[0].Literal = "b"
+	copied.Definitions[2].(*ast.TypeDefinition).Fields[0].Directives[0].Name = "beta"
+	if got := ast.Print(doc); got != ast.Print(parser.New(lexer.New(src)).ParseDocument()) {
+		t.Errorf("modifying the clone changed the original:\n%s", got)
+	}
+	if ast.Equal(doc, copied) {
+		t.Error("expected the modified clone to differ")
+	}
+
+	// Positions are ignored, so a reprinted document is equal.
+	reparsed := parser.New(lexer.New(ast.Print(doc))).ParseDocument()
+	if !ast.Equal(doc, reparsed) {
+		t.Error("expected the reparsed document to equal the original")
+	}
+
+	built := &ast.Field{Name: "me", Arguments: []ast.Argument{}}
+	parsed := parser.New(lexer.New(`{ me }`)).ParseDocument().Definitions[0].(*ast.OperationDefinition).SelectionSet.Selections[0]
+	if !ast.Equal(built, parsed) {
+		t.Error("expected empty and nil argument lists to be equal")
+	}
+	if ast.Equal(built, &ast.FragmentSpread{Name: "me"}) {
+		t.Error("expected nodes of different types to differ")
+	}
+}
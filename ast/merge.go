@@ -0,0 +1,172 @@
+package ast
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeExtensions combines the definitions of docs, such as SDL files of a
+// modular schema, into one document in which every type extension has been
+// folded into the type it extends. Extensions may appear before or after
+// the definition they extend and in any of the documents. It is an error to
+// extend an undefined type, to extend it with a definition of another kind,
+// or to redeclare an existing field, value or member. The input documents
+// are not modified.
+func MergeExtensions(docs ...*Document) (*Document, error) {
+	merged := &Document{}
+	index := make(map[string]int) // type name -> position in merged
+	var extensions []*TypeExtension
+	for _, doc := range docs {
+		for _, def := range doc.Definitions {
+			if ext, ok := def.(*TypeExtension); ok {
+				extensions = append(extensions, ext)
+				continue
+			}
+			if name, ok := typeName(def); ok {
+				if _, dup := index[name]; dup {
+					return nil, fmt.Errorf("type %s is defined more than once", name)
+				}
+				index[name] = len(merged.Definitions)
+				def = copyDefinition(def)
+			}
+			merged.Definitions = append(merged.Definitions, def)
+		}
+	}
+	for _, ext := range extensions {
+		name := ext.TokenLiteral()
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot extend undefined type %s", name)
+		}
+		if err := extend(merged.Definitions[i], ext.Definition); err != nil {
+			return nil, fmt.Errorf("cannot extend type %s: %w", name, err)
+		}
+	}
+	return merged, nil
+}
+
+// typeName returns the name of a named type definition.
+func typeName(def Definition) (string, bool) {
+	switch def := def.(type) {
+	case *TypeDefinition, *InterfaceTypeDefinition, *UnionTypeDefinition,
+		*EnumTypeDefinition, *InputObjectTypeDefinition, *ScalarTypeDefinition:
+		return def.TokenLiteral(), true
+	}
+	return "", false
+}
+
+// copyDefinition returns a shallow copy of a named type definition so that
+// extending it leaves the original untouched. Slices are reallocated on
+// append because they are clipped to their length.
+func copyDefinition(def Definition) Definition {
+	switch def := def.(type) {
+	case *TypeDefinition:
+		c := *def
+		c.Interfaces = c.Interfaces[:len(c.Interfaces):len(c.Interfaces)]
+		c.Fields = c.Fields[:len(c.Fields):len(c.Fields)]
+		return &c
+	case *InterfaceTypeDefinition:
+		c := *def
+		c.Interfaces = c.Interfaces[:len(c.Interfaces):len(c.Interfaces)]
+		c.Fields = c.Fields[:len(c.Fields):len(c.Fields)]
+		return &c
+	case *UnionTypeDefinition:
+		c := *def
+		c.Types = c.Types[:len(c.Types):len(c.Types)]
+		return &c
+	case *EnumTypeDefinition:
+		c := *def
+		c.Values = c.Values[:len(c.Values):len(c.Values)]
+		return &c
+	case *InputObjectTypeDefinition:
+		c := *def
+		c.Fields = c.Fields[:len(c.Fields):len(c.Fields)]
+		return &c
+	case *ScalarTypeDefinition:
+		c := *def
+		c.Directives = c.Directives[:len(c.Directives):len(c.Directives)]
+		return &c
+	}
+	return def
+}
+
+// extend adds what ext declares to def, which must be of the same kind.
+func extend(def, ext Definition) error {
+	switch def := def.(type) {
+	case *TypeDefinition:
+		e, ok := ext.(*TypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is an object type")
+		}
+		return extendFields(&def.Interfaces, &def.Fields, e.Interfaces, e.Fields)
+	case *InterfaceTypeDefinition:
+		e, ok := ext.(*InterfaceTypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is an interface")
+		}
+		return extendFields(&def.Interfaces, &def.Fields, e.Interfaces, e.Fields)
+	case *UnionTypeDefinition:
+		e, ok := ext.(*UnionTypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is a union")
+		}
+		for _, member := range e.Types {
+			if slices.Contains(def.Types, member) {
+				return fmt.Errorf("member %s already exists", member)
+			}
+			def.Types = append(def.Types, member)
+		}
+	case *EnumTypeDefinition:
+		e, ok := ext.(*EnumTypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is an enum")
+		}
+		for _, v := range e.Values {
+			for _, existing := range def.Values {
+				if existing.Name == v.Name {
+					return fmt.Errorf("value %s already exists", v.Name)
+				}
+			}
+			def.Values = append(def.Values, v)
+		}
+	case *InputObjectTypeDefinition:
+		e, ok := ext.(*InputObjectTypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is an input object type")
+		}
+		for _, f := range e.Fields {
+			for _, existing := range def.Fields {
+				if existing.Name == f.Name {
+					return fmt.Errorf("field %s already exists", f.Name)
+				}
+			}
+			def.Fields = append(def.Fields, f)
+		}
+	case *ScalarTypeDefinition:
+		e, ok := ext.(*ScalarTypeDefinition)
+		if !ok {
+			return fmt.Errorf("it is a scalar")
+		}
+		def.Directives = append(def.Directives, e.Directives...)
+	}
+	return nil
+}
+
+// extendFields adds interfaces and fields of an object or interface extension.
+func extendFields(interfaces *[]string, fields *[]*Field, newInterfaces []string, newFields []*Field) error {
+	for _, name := range newInterfaces {
+		if slices.Contains(*interfaces, name) {
+			return fmt.Errorf("interface %s is already implemented", name)
+		}
+		*interfaces = append(*interfaces, name)
+	}
+	for _, f := range newFields {
+		for _, existing := range *fields {
+			if existing.Name == f.Name {
+				return fmt.Errorf("field %s already exists", f.Name)
+			}
+		}
+		*fields = append(*fields, f)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package ast
+
+import "strings"
+
+// defaultLineWidth is the line width used when QueryPrinter.LineWidth is unset.
+const defaultLineWidth = 80
+
+// QueryPrinter renders operations and fragments as GraphQL source.
+// Anonymous queries without variables use the shorthand "{ ... }" form.
+// Argument and variable lists are kept on one line unless that line would
+// exceed the line width, in which case each entry goes on its own line.
+// The zero value uses two-space indentation and a line width of 80.
+type QueryPrinter struct {
+	// Indent is the string used for one level of indentation.
+	// It defaults to two spaces.
+	Indent string
+	// LineWidth is the width beyond which argument and variable lists are
+	// wrapped one per line. It defaults to 80.
+	LineWidth int
+}
+
+// Print renders every definition in doc as GraphQL source: operations and
+// fragments with the zero QueryPrinter and type system definitions with
+// the zero SchemaPrinter, separated by blank lines.
+func Print(doc *Document) string {
+	var defs []string
+	for _, def := range doc.Definitions {
+		s := QueryPrinter{}.PrintDefinition(def)
+		if s == "" {
+			s = SchemaPrinter{}.PrintDefinition(def)
+		}
+		if s != "" {
+			defs = append(defs, s)
+		}
+	}
+	return strings.Join(defs, "\n")
+}
+
+// Print renders the operations and fragments in doc. Type system
+// definitions are ignored.
+func (p QueryPrinter) Print(doc *Document) string {
+	var defs []string
+	for _, def := range doc.Definitions {
+		if s := p.PrintDefinition(def); s != "" {
+			defs = append(defs, s)
+		}
+	}
+	return strings.Join(defs, "\n")
+}
+
+// PrintDefinition renders an operation or fragment definition ending in a
+// newline. It returns "" for other definitions.
+func (p QueryPrinter) PrintDefinition(def Definition) string {
+	switch def := def.(type) {
+	case *OperationDefinition:
+		return p.printOperation(def)
+	case *FragmentDefinition:
+		return p.printFragment(def)
+	}
+	return ""
+}
+
+// String returns the source of the operation.
+func (op *OperationDefinition) String() string {
+	return strings.TrimSuffix(QueryPrinter{}.printOperation(op), "\n")
+}
+
+// String returns the source of the fragment definition.
+func (f *FragmentDefinition) String() string {
+	return strings.TrimSuffix(QueryPrinter{}.printFragment(f), "\n")
+}
+
+// indent returns the indentation for the given nesting level.
+func (p QueryPrinter) indent(level int) string {
+	unit := p.Indent
+	if unit == "" {
+		unit = "  "
+	}
+	return strings.Repeat(unit, level)
+}
+
+// lineWidth returns the maximum line width before lists are wrapped.
+func (p QueryPrinter) lineWidth() int {
+	if p.LineWidth <= 0 {
+		return defaultLineWidth
+	}
+	return p.LineWidth
+}
+
+// printOperation renders an operation definition.
+func (p QueryPrinter) printOperation(op *OperationDefinition) string {
+	var sb strings.Builder
+	if op.Operation != "query" || op.Name != "" || len(op.VariableDefinitions) > 0 || len(op.Directives) > 0 {
+		head := op.Operation
+		if op.Name != "" {
+			head += " " + op.Name
+		}
+		var vars []string
+		for _, v := range op.VariableDefinitions {
+			def := "$" + v.Variable + ": " + v.Type.String()
+			if v.DefaultValue != nil {
+				def += " = " + v.DefaultValue.String()
+			}
+			vars = append(vars, def)
+		}
+		sb.WriteString(head + p.printList(vars, len(head), 0) + printDirectives(op.Directives) + " ")
+	}
+	p.printSelectionSet(&sb, op.SelectionSet, 0)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// printFragment renders a fragment definition.
+func (p QueryPrinter) printFragment(frag *FragmentDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("fragment " + frag.Name + " on " + frag.TypeCondition + printDirectives(frag.Directives) + " ")
+	p.printSelectionSet(&sb, frag.SelectionSet, 0)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// printSelectionSet renders a selection set at the given nesting level.
+func (p QueryPrinter) printSelectionSet(sb *strings.Builder, ss *SelectionSet, level int) {
+	sb.WriteString("{\n")
+	if ss != nil {
+		for _, sel := range ss.Selections {
+			switch sel := sel.(type) {
+			case *FragmentSpread:
+				sb.WriteString(p.indent(level+1) + "..." + sel.Name + printDirectives(sel.Directives) + "\n")
+			case *InlineFragment:
+				sb.WriteString(p.indent(level+1) + "...")
+				if sel.TypeCondition != "" {
+					sb.WriteString(" on " + sel.TypeCondition)
+				}
+				sb.WriteString(printDirectives(sel.Directives) + " ")
+				p.printSelectionSet(sb, sel.SelectionSet, level+1)
+				sb.WriteString("\n")
+			case *Field:
+				prefix := p.indent(level+1) + sel.Name
+				if sel.Alias != "" {
+					prefix = p.indent(level+1) + sel.Alias + ": " + sel.Name
+				}
+				var args []string
+				for _, arg := range sel.Arguments {
+					args = append(args, arg.Name+": "+arg.Value.String())
+				}
+				sb.WriteString(prefix + p.printList(args, len(prefix), level+1) + printDirectives(sel.Directives))
+				if sel.SelectionSet != nil {
+					sb.WriteString(" ")
+					p.printSelectionSet(sb, sel.SelectionSet, level+1)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	sb.WriteString(p.indent(level) + "}")
+}
+
+// printList renders a parenthesized, comma-separated list that follows
+// offset characters on the current line, wrapping one item per line when it
+// would not fit.
+func (p QueryPrinter) printList(items []string, offset, level int) string {
+	if len(items) == 0 {
+		return ""
+	}
+	inline := "(" + strings.Join(items, ", ") + ")"
+	if offset+len(inline) <= p.lineWidth() {
+		return inline
+	}
+	var sb strings.Builder
+	sb.WriteString("(\n")
+	for _, item := range items {
+		sb.WriteString(p.indent(level+1) + item + "\n")
+	}
+	sb.WriteString(p.indent(level) + ")")
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestPrint(t *testing.T) {
+	input := `query Users($first: Int = 10, $filter: Filter) @cached { users(first: $first, filter: $filter) { id ...UserFields ... on Admin @include(if: true) { permissions } } }
+fragment UserFields on User { name friends(roles: [ADMIN, USER], where: {active: true}) { id } }
+{ me { id, handle: name } }
+type User { id: ID! }`
+	p := parser.New(lexer.New(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `query Users($first: Int = 10, $filter: Filter) @cached {
+  users(first: $first, filter: $filter) {
+    id
+    ...UserFields
+    ... on Admin @include(if: true) {
+      permissions
+    }
+  }
+}
+
+fragment UserFields on User {
+  name
+  friends(roles: [ADMIN, USER], where: {active: true}) {
+    id
+  }
+}
+
+{
+  me {
+    id
+    handle: name
+  }
+}
+
+type User {
+  id: ID!
+}
+`
+	if got := ast.Print(doc); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	reparsed := parser.New(lexer.New(want)).ParseDocument()
+	if got := ast.Print(reparsed); got != want {
+		t.Errorf("printing is not stable:\n%s", got)
+	}
+
+	if got, want := doc.Definitions[2].(*ast.OperationDefinition).String(), "{\n  me {\n    id\n    handle: name\n  }\n}"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	wrapped := ast.QueryPrinter{LineWidth: 20}.Print(doc)
+	if want := "query Users(\n  $first: Int = 10\n  $filter: Filter\n) @cached {"; wrapped[:len(want)] != want {
+		t.Errorf("expected wrapped variables, got:\n%s", wrapped)
+	}
+}
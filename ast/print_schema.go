@@ -0,0 +1,309 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+)
+
+// SchemaPrinter renders type system definitions as SDL: one blank line
+// between definitions, one field per line, and arguments inline unless
+// they have descriptions. The zero value prints in declaration order with
+// two-space indentation.
+type SchemaPrinter struct {
+	// Indent is the string used for one level of indentation.
+	// It defaults to two spaces.
+	Indent string
+	// SortFields orders fields and arguments alphabetically instead of
+	// keeping their declaration order.
+	SortFields bool
+}
+
+// PrintSchema renders the type system definitions in doc as SDL with the
+// zero SchemaPrinter. Operation and fragment definitions are ignored.
+func PrintSchema(doc *Document) string {
+	return SchemaPrinter{}.Print(doc)
+}
+
+// Print renders the type system definitions in doc as SDL. Operation and
+// fragment definitions are ignored.
+func (p SchemaPrinter) Print(doc *Document) string {
+	var defs []string
+	for _, def := range doc.Definitions {
+		if s := p.PrintDefinition(def); s != "" {
+			defs = append(defs, s)
+		}
+	}
+	return strings.Join(defs, "\n")
+}
+
+// PrintDefinition renders a type system definition, preceded by its
+// description, as SDL ending in a newline. It returns "" for other
+// definitions.
+func (p SchemaPrinter) PrintDefinition(def Definition) string {
+	s := p.printDefinition(def)
+	if s == "" {
+		return ""
+	}
+	return printDescription(description(def), "") + s
+}
+
+// String returns the SDL of the type definition.
+func (t *TypeDefinition) String() string { return printDefinition(t) }
+
+// String returns the SDL of the interface definition.
+func (t *InterfaceTypeDefinition) String() string { return printDefinition(t) }
+
+// String returns the SDL of the type extension.
+func (e *TypeExtension) String() string { return printDefinition(e) }
+
+// String returns the SDL of the directive definition.
+func (d *DirectiveDefinition) String() string { return printDefinition(d) }
+
+// String returns the SDL of the schema definition.
+func (s *SchemaDefinition) String() string { return printDefinition(s) }
+
+// String returns the SDL of the scalar definition.
+func (t *ScalarTypeDefinition) String() string { return printDefinition(t) }
+
+// String returns the SDL of the union definition.
+func (t *UnionTypeDefinition) String() string { return printDefinition(t) }
+
+// String returns the SDL of the enum definition.
+func (t *EnumTypeDefinition) String() string { return printDefinition(t) }
+
+// String returns the SDL of the input object definition.
+func (t *InputObjectTypeDefinition) String() string { return printDefinition(t) }
+
+// printDefinition renders def with the zero SchemaPrinter, without the
+// trailing newline.
+func printDefinition(def Definition) string {
+	return strings.TrimSuffix(SchemaPrinter{}.PrintDefinition(def), "\n")
+}
+
+// indent returns the indentation for the given nesting level.
+func (p SchemaPrinter) indent(level int) string {
+	unit := p.Indent
+	if unit == "" {
+		unit = "  "
+	}
+	return strings.Repeat(unit, level)
+}
+
+// description returns the description of a type system definition.
+func description(def Definition) string {
+	switch def := def.(type) {
+	case *TypeDefinition:
+		return def.Description
+	case *InterfaceTypeDefinition:
+		return def.Description
+	case *UnionTypeDefinition:
+		return def.Description
+	case *EnumTypeDefinition:
+		return def.Description
+	case *InputObjectTypeDefinition:
+		return def.Description
+	case *ScalarTypeDefinition:
+		return def.Description
+	case *DirectiveDefinition:
+		return def.Description
+	case *SchemaDefinition:
+		return def.Description
+	}
+	return ""
+}
+
+// printDefinition renders a type system definition without its
+// description, or returns "" for other definitions.
+func (p SchemaPrinter) printDefinition(def Definition) string {
+	switch def := def.(type) {
+	case *TypeDefinition:
+		return p.printFieldsDefinition("type", def.Name, def.Interfaces, def.Fields)
+	case *InterfaceTypeDefinition:
+		return p.printFieldsDefinition("interface", def.Name, def.Interfaces, def.Fields)
+	case *InputObjectTypeDefinition:
+		return p.printInputObjectTypeDefinition(def)
+	case *DirectiveDefinition:
+		return p.printDirectiveDefinition(def)
+	case *SchemaDefinition:
+		return p.printSchemaDefinition(def)
+	case *ScalarTypeDefinition:
+		return "scalar " + def.Name + printDirectives(def.Directives) + "\n"
+	case *UnionTypeDefinition:
+		return "union " + def.Name + " = " + strings.Join(def.Types, " | ") + "\n"
+	case *EnumTypeDefinition:
+		return p.printEnumTypeDefinition(def)
+	case *TypeExtension:
+		if s := p.printDefinition(def.Definition); s != "" {
+			return "extend " + s
+		}
+	}
+	return ""
+}
+
+// printDescription renders a description on its own line(s) at the given
+// indentation, as a block string unless it fits a plain string literal.
+func printDescription(description, indent string) string {
+	if description == "" {
+		return ""
+	}
+	if !strings.ContainsAny(description, "\"\\\n") {
+		return indent + `"` + description + `"` + "\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(strings.ReplaceAll(description, `"""`, `\"""`), "\n") {
+		if line != "" {
+			sb.WriteString(indent + line)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent + `"""` + "\n")
+	return sb.String()
+}
+
+// printArgumentDefinitions renders an argument definition list at the
+// given nesting level: inline, or one argument per line if any has a
+// description.
+func (p SchemaPrinter) printArgumentDefinitions(args []*InputValueDefinition, level int) string {
+	if len(args) == 0 {
+		return ""
+	}
+	described := false
+	var parts []string
+	for _, arg := range args {
+		described = described || arg.Description != ""
+		parts = append(parts, printInputValueDefinition(arg))
+	}
+	if !described {
+		return "(" + strings.Join(parts, ", ") + ")"
+	}
+	var sb strings.Builder
+	sb.WriteString("(\n")
+	for i, arg := range args {
+		sb.WriteString(printDescription(arg.Description, p.indent(level+1)))
+		sb.WriteString(p.indent(level+1) + parts[i] + "\n")
+	}
+	sb.WriteString(p.indent(level) + ")")
+	return sb.String()
+}
+
+// printDirectiveDefinition renders a directive definition.
+func (p SchemaPrinter) printDirectiveDefinition(def *DirectiveDefinition) string {
+	s := "directive @" + def.Name + p.printArgumentDefinitions(def.Arguments, 0)
+	if def.Repeatable {
+		s += " repeatable"
+	}
+	return s + " on " + strings.Join(def.Locations, " | ") + "\n"
+}
+
+// printSchemaDefinition renders a schema definition.
+func (p SchemaPrinter) printSchemaDefinition(def *SchemaDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("schema" + printDirectives(def.Directives) + " {\n")
+	for _, ot := range def.OperationTypes {
+		sb.WriteString(p.indent(1) + ot.Operation + ": " + ot.Type + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// printFieldsDefinition renders an object or interface type with the given
+// keyword, implemented interfaces and fields.
+func (p SchemaPrinter) printFieldsDefinition(keyword, name string, interfaces []string, fields []*Field) string {
+	var sb strings.Builder
+	sb.WriteString(keyword + " " + name)
+	if len(interfaces) > 0 {
+		sb.WriteString(" implements " + strings.Join(interfaces, " & "))
+	}
+	if len(fields) == 0 {
+		// The field list is optional, e.g. in extensions adding interfaces
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	if p.SortFields {
+		fields = append([]*Field(nil), fields...)
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	}
+	sb.WriteString(" {\n")
+	for _, f := range fields {
+		sb.WriteString(printDescription(f.Description, p.indent(1)))
+		sb.WriteString(p.indent(1) + p.printFieldDefinition(f) + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// printInputObjectTypeDefinition renders an input object type definition.
+func (p SchemaPrinter) printInputObjectTypeDefinition(def *InputObjectTypeDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("input " + def.Name + " {")
+	fields := def.Fields
+	if p.SortFields {
+		fields = append([]*InputValueDefinition(nil), fields...)
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	}
+	if len(fields) == 0 {
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+	sb.WriteString("\n")
+	for _, f := range fields {
+		sb.WriteString(printDescription(f.Description, p.indent(1)))
+		sb.WriteString(p.indent(1) + printInputValueDefinition(f) + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// printEnumTypeDefinition renders an enum type definition. Values keep
+// their declaration order even when sorting, since it is significant.
+func (p SchemaPrinter) printEnumTypeDefinition(def *EnumTypeDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("enum " + def.Name + " {")
+	if len(def.Values) == 0 {
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+	sb.WriteString("\n")
+	for _, v := range def.Values {
+		sb.WriteString(printDescription(v.Description, p.indent(1)))
+		sb.WriteString(p.indent(1) + v.Name + printDirectives(v.Directives) + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// printFieldDefinition renders a field definition with its arguments and type.
+func (p SchemaPrinter) printFieldDefinition(f *Field) string {
+	s := f.Name
+	if len(f.ArgumentDefinitions) > 0 {
+		args := f.ArgumentDefinitions
+		if p.SortFields {
+			args = append([]*InputValueDefinition(nil), args...)
+			sort.SliceStable(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+		}
+		s += p.printArgumentDefinitions(args, 1)
+	}
+	if f.Type != nil {
+		s += ": " + f.Type.String()
+	}
+	return s + printDirectives(f.Directives)
+}
+
+// printInputValueDefinition renders an argument or input field definition.
+func printInputValueDefinition(iv *InputValueDefinition) string {
+	s := iv.Name + ": " + iv.Type.String()
+	if iv.DefaultValue != nil {
+		s += " = " + iv.DefaultValue.String()
+	}
+	return s
+}
+
+// printDirectives renders directive applications, each preceded by a space.
+func printDirectives(directives []*Directive) string {
+	var sb strings.Builder
+	for _, d := range directives {
+		sb.WriteString(" " + d.String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestPrintSchema(t *testing.T) {
+	input := `schema { query: Query }
+"The root type." type Query implements Node { users(first: Int = 10, filter: Filter = {role: ADMIN}): [User!]! @auth id: ID! }
+interface Node { id: ID! }
+input Filter { role: Role }
+enum Role { ADMIN USER @deprecated(reason: "Use ADMIN.") }
+union Result = Query | User
+scalar DateTime @specifiedBy(url: "https://example.com")
+directive @auth(role: String) repeatable on FIELD_DEFINITION | OBJECT
+extend type User { email: String }
+query { users { id } }`
+	p := parser.New(lexer.New(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `schema {
+  query: Query
+}
+
+"The root type."
+type Query implements Node {
+  users(first: Int = 10, filter: Filter = {role: ADMIN}): [User!]! @auth
+  id: ID!
+}
+
+interface Node {
+  id: ID!
+}
+
+input Filter {
+  role: Role
+}
+
+enum Role {
+  ADMIN
+  USER @deprecated(reason: "Use ADMIN.")
+}
+
+union Result = Query | User
+
+scalar DateTime @specifiedBy(url: "https://example.com")
+
+directive @auth(role: String) repeatable on FIELD_DEFINITION | OBJECT
+
+extend type User {
+  email: String
+}
+`
+	if got := ast.PrintSchema(doc); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	reparsed := parser.New(lexer.New(want)).ParseDocument()
+	if got := ast.PrintSchema(reparsed); got != want {
+		t.Errorf("printing is not stable:\n%s", got)
+	}
+
+	if got, want := doc.Definitions[5].(*ast.UnionTypeDefinition).String(), "union Result = Query | User"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := doc.Definitions[1].(*ast.TypeDefinition).String(), "\"The root type.\"\ntype Query implements Node {\n  users(first: Int = 10, filter: Filter = {role: ADMIN}): [User!]! @auth\n  id: ID!\n}"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,164 @@
+// Package astutil provides common rewrites of executable GraphQL documents,
+// such as inlining fragments or removing fields, and their normalized
+// hashes, for use by gateways and caching layers. Rewrites return a copy
+// and leave their input document untouched; nodes that are not rewritten
+// are shared.
+package astutil
+
+import "github.com/Protocol-Lattice/graphql/ast"
+
+// Transform returns a copy of doc in which every field selected by its
+// operations and fragments has been passed to fn, outermost fields first.
+// fn receives the response path leading to the field and a copy of the
+// field that it may modify; returning false removes the field. Paths of
+// fields in fragment definitions start at the fragment. Fields and inline
+// fragments whose sub-selections are all removed are removed as well.
+func Transform(doc *ast.Document, fn func(path []string, field *ast.Field) bool) *ast.Document {
+	out := &ast.Document{Loc: doc.Loc}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			op := *def
+			op.SelectionSet = transformSelectionSet(def.SelectionSet, nil, fn)
+			out.Definitions = append(out.Definitions, &op)
+		case *ast.FragmentDefinition:
+			frag := *def
+			frag.SelectionSet = transformSelectionSet(def.SelectionSet, nil, fn)
+			out.Definitions = append(out.Definitions, &frag)
+		default:
+			out.Definitions = append(out.Definitions, def)
+		}
+	}
+	return out
+}
+
+// transformSelectionSet returns a copy of ss rewritten by fn, or nil if no
+// selections are left.
+func transformSelectionSet(ss *ast.SelectionSet, path []string, fn func(path []string, field *ast.Field) bool) *ast.SelectionSet {
+	if ss == nil {
+		return nil
+	}
+	out := &ast.SelectionSet{Loc: ss.Loc}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			field := *sel
+			field.Arguments = field.Arguments[:len(field.Arguments):len(field.Arguments)]
+			field.Directives = field.Directives[:len(field.Directives):len(field.Directives)]
+			fieldPath := append(path[:len(path):len(path)], sel.ResponseKey())
+			if !fn(fieldPath, &field) {
+				continue
+			}
+			if field.SelectionSet != nil {
+				if field.SelectionSet = transformSelectionSet(field.SelectionSet, fieldPath, fn); field.SelectionSet == nil {
+					continue
+				}
+			}
+			out.Selections = append(out.Selections, &field)
+		case *ast.InlineFragment:
+			inline := *sel
+			if inline.SelectionSet = transformSelectionSet(sel.SelectionSet, path, fn); inline.SelectionSet == nil {
+				continue
+			}
+			out.Selections = append(out.Selections, &inline)
+		default:
+			out.Selections = append(out.Selections, sel)
+		}
+	}
+	if len(out.Selections) == 0 {
+		return nil
+	}
+	return out
+}
+
+// StripField returns a copy of doc without any selection of the named
+// field, e.g. "__typename".
+func StripField(doc *ast.Document, name string) *ast.Document {
+	return Transform(doc, func(_ []string, field *ast.Field) bool {
+		return field.Name != name
+	})
+}
+
+// RenameFields returns a copy of doc in which every field selection whose
+// name is a key of names is renamed to the corresponding value.
+func RenameFields(doc *ast.Document, names map[string]string) *ast.Document {
+	return Transform(doc, func(_ []string, field *ast.Field) bool {
+		if name, ok := names[field.Name]; ok {
+			field.Name = name
+		}
+		return true
+	})
+}
+
+// Prune returns a copy of doc keeping only the fields for which keep
+// returns true. keep receives the response path of each field, e.g.
+// ["user", "friends", "name"], so selections can be limited to an
+// allowlist or a maximum depth; unlike with Transform, it should not
+// modify the field.
+func Prune(doc *ast.Document, keep func(path []string, field *ast.Field) bool) *ast.Document {
+	return Transform(doc, keep)
+}
+
+// InlineFragments returns a copy of doc in which every fragment spread has
+// been replaced by an inline fragment with the fragment's type condition
+// and selections, and the fragment definitions have been removed. Spreads
+// of unknown fragments and cyclic spreads are dropped.
+func InlineFragments(doc *ast.Document) *ast.Document {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name] = frag
+		}
+	}
+	out := &ast.Document{Loc: doc.Loc}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			continue
+		case *ast.OperationDefinition:
+			op := *def
+			op.SelectionSet = inlineSelectionSet(def.SelectionSet, fragments, make(map[string]bool))
+			out.Definitions = append(out.Definitions, &op)
+		default:
+			out.Definitions = append(out.Definitions, def)
+		}
+	}
+	return out
+}
+
+// inlineSelectionSet returns a copy of ss with fragment spreads inlined.
+// visiting holds the fragments being expanded, to break cycles.
+func inlineSelectionSet(ss *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visiting map[string]bool) *ast.SelectionSet {
+	if ss == nil {
+		return nil
+	}
+	out := &ast.SelectionSet{Loc: ss.Loc}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			field := *sel
+			field.SelectionSet = inlineSelectionSet(sel.SelectionSet, fragments, visiting)
+			out.Selections = append(out.Selections, &field)
+		case *ast.InlineFragment:
+			inline := *sel
+			inline.SelectionSet = inlineSelectionSet(sel.SelectionSet, fragments, visiting)
+			out.Selections = append(out.Selections, &inline)
+		case *ast.FragmentSpread:
+			frag, ok := fragments[sel.Name]
+			if !ok || visiting[sel.Name] {
+				continue
+			}
+			visiting[sel.Name] = true
+			out.Selections = append(out.Selections, &ast.InlineFragment{
+				Loc:           sel.Loc,
+				TypeCondition: frag.TypeCondition,
+				Directives:    sel.Directives,
+				SelectionSet:  inlineSelectionSet(frag.SelectionSet, fragments, visiting),
+			})
+			delete(visiting, sel.Name)
+		default:
+			out.Selections = append(out.Selections, sel)
+		}
+	}
+	return out
+}
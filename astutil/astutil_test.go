@@ -0,0 +1,107 @@
+package astutil
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func parse(t *testing.T, src string) *ast.Document {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return doc
+}
+
+func TestInlineFragments(t *testing.T) {
+	src := `{ user { ...UserFields @include(if: true) } } fragment UserFields on User { id ...Friends } fragment Friends on User { friends { id } }`
+	doc := parse(t, src)
+	want := `{
+  user {
+    ... on User @include(if: true) {
+      id
+      ... on User {
+        friends {
+          id
+        }
+      }
+    }
+  }
+}
+`
+	if got := ast.Print(InlineFragments(doc)); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if got := ast.Print(doc); got != ast.Print(parse(t, src)) {
+		t.Errorf("input document was modified:\n%s", got)
+	}
+
+	cyclic := parse(t, `{ ...A } fragment A on Query { a ...B } fragment B on Query { b ...A }`)
+	want = `{
+  ... on Query {
+    a
+    ... on Query {
+      b
+    }
+  }
+}
+`
+	if got := ast.Print(InlineFragments(cyclic)); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestStripField(t *testing.T) {
+	doc := parse(t, `{ __typename user { __typename id } meta { __typename } ... on Query { __typename } } fragment F on User { __typename name }`)
+	want := `{
+  user {
+    id
+  }
+}
+
+fragment F on User {
+  name
+}
+`
+	if got := ast.Print(StripField(doc, "__typename")); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRenameFields(t *testing.T) {
+	doc := parse(t, `{ user(id: 1) { fullName } }`)
+	want := `{
+  account(id: 1) {
+    name
+  }
+}
+`
+	if got := ast.Print(RenameFields(doc, map[string]string{"user": "account", "fullName": "name"})); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if name := doc.Definitions[0].(*ast.OperationDefinition).SelectionSet.Selections[0].(*ast.Field).Name; name != "user" {
+		t.Errorf("input document was modified: field renamed to %s", name)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	doc := parse(t, `{ user { id friends { id friends { id } } } }`)
+	want := `{
+  user {
+    id
+    friends {
+      id
+    }
+  }
+}
+`
+	maxDepth := func(path []string, _ *ast.Field) bool { return len(path) <= 3 }
+	if got := ast.Print(Prune(doc, maxDepth)); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
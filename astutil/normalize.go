@@ -0,0 +1,174 @@
+package astutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// Normalize returns a canonical single-line form of the named operation in
+// doc, together with the fragments it uses, such that documents differing
+// only in whitespace, commas, argument and variable order, or the order and
+// presence of unrelated definitions normalize to the same text. Fragments
+// follow the operation in name order. operationName may be empty if doc
+// contains exactly one operation.
+func Normalize(doc *ast.Document, operationName string) (string, error) {
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return "", err
+	}
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name] = frag
+		}
+	}
+	n := &normalizer{fragments: fragments, used: make(map[string]bool)}
+
+	var sb strings.Builder
+	sb.WriteString(op.Operation)
+	if op.Name != "" {
+		sb.WriteString(" " + op.Name)
+	}
+	if len(op.VariableDefinitions) > 0 {
+		vars := make([]string, len(op.VariableDefinitions))
+		for i, v := range op.VariableDefinitions {
+			vars[i] = "$" + v.Variable + ":" + v.Type.String()
+			if v.DefaultValue != nil {
+				vars[i] += "=" + v.DefaultValue.String()
+			}
+		}
+		sort.Strings(vars)
+		sb.WriteString("(" + strings.Join(vars, ",") + ")")
+	}
+	sb.WriteString(normalizeDirectives(op.Directives))
+	n.selectionSet(&sb, op.SelectionSet)
+
+	// Fragments are collected while printing, including those only used
+	// by other fragments.
+	var printed []string
+	for len(n.pending) > 0 {
+		frag := n.pending[0]
+		n.pending = n.pending[1:]
+		var fb strings.Builder
+		fb.WriteString(" fragment " + frag.Name + " on " + frag.TypeCondition + normalizeDirectives(frag.Directives))
+		n.selectionSet(&fb, frag.SelectionSet)
+		printed = append(printed, fb.String())
+	}
+	sort.Strings(printed)
+	for _, s := range printed {
+		sb.WriteString(s)
+	}
+	return sb.String(), nil
+}
+
+// Hash returns the hex-encoded SHA-256 digest of the normalized form of the
+// named operation in doc, for use as a key of persisted queries, caches and
+// metrics.
+func Hash(doc *ast.Document, operationName string) (string, error) {
+	normalized, err := Normalize(doc, operationName)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// selectOperation returns the operation of doc named name, or its only
+// operation if name is empty.
+func selectOperation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name != "" {
+			if op.Name == name {
+				return op, nil
+			}
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("document contains several operations; an operation name is required")
+		}
+		found = op
+	}
+	if found == nil {
+		if name != "" {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		return nil, fmt.Errorf("document contains no operation")
+	}
+	return found, nil
+}
+
+// normalizer prints selection sets in normalized form and tracks the
+// fragments they spread.
+type normalizer struct {
+	fragments map[string]*ast.FragmentDefinition
+	used      map[string]bool           // fragments already queued
+	pending   []*ast.FragmentDefinition // fragments still to print
+}
+
+// selectionSet writes ss in normalized form.
+func (n *normalizer) selectionSet(sb *strings.Builder, ss *ast.SelectionSet) {
+	if ss == nil {
+		return
+	}
+	sb.WriteString("{")
+	for i, sel := range ss.Selections {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Alias != "" {
+				sb.WriteString(sel.Alias + ":")
+			}
+			sb.WriteString(sel.Name + normalizeArguments(sel.Arguments) + normalizeDirectives(sel.Directives))
+			n.selectionSet(sb, sel.SelectionSet)
+		case *ast.FragmentSpread:
+			sb.WriteString("..." + sel.Name + normalizeDirectives(sel.Directives))
+			if frag, ok := n.fragments[sel.Name]; ok && !n.used[sel.Name] {
+				n.used[sel.Name] = true
+				n.pending = append(n.pending, frag)
+			}
+		case *ast.InlineFragment:
+			sb.WriteString("...")
+			if sel.TypeCondition != "" {
+				sb.WriteString("on " + sel.TypeCondition)
+			}
+			sb.WriteString(normalizeDirectives(sel.Directives))
+			n.selectionSet(sb, sel.SelectionSet)
+		}
+	}
+	sb.WriteString("}")
+}
+
+// normalizeArguments renders arguments sorted by name.
+func normalizeArguments(args []ast.Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Name + ":" + arg.Value.String()
+	}
+	sort.Strings(parts)
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// normalizeDirectives renders directives in source order, which is
+// significant, with their arguments sorted by name.
+func normalizeDirectives(directives []*ast.Directive) string {
+	var sb strings.Builder
+	for _, d := range directives {
+		sb.WriteString("@" + d.Name + normalizeArguments(d.Arguments))
+	}
+	return sb.String()
+}
@@ -0,0 +1,47 @@
+package astutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	a := parse(t, `query Users($b: Int, $a: String = "x") {
+  users(first: $b, after: $a) @include(if: true) { ...UserFields, ... on Admin { role } }
+}
+fragment UserFields on User { id ...Names }
+fragment Names on User { name }
+fragment Unused on User { email }
+query Other { me { id } }`)
+	b := parse(t, `fragment Names on User{name} query Users($a:String="x" $b:Int){users(after:$a first:$b)@include(if:true){...UserFields ...on Admin{role}}} fragment UserFields on User{id,...Names}`)
+
+	want := `query Users($a:String="x",$b:Int){users(after:$a,first:$b)@include(if:true){...UserFields ...on Admin{role}}} fragment Names on User{name} fragment UserFields on User{id ...Names}`
+	got, err := Normalize(a, "Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	hashA, err := Hash(a, "Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err := Hash(b, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA != hashB || len(hashA) != 64 {
+		t.Errorf("expected equal SHA-256 hashes, got %s and %s", hashA, hashB)
+	}
+	if other, _ := Hash(a, "Other"); other == hashA {
+		t.Error("expected different operations to hash differently")
+	}
+
+	for name, wantErr := range map[string]string{"": "several operations", "Missing": "unknown operation"} {
+		if _, err := Normalize(a, name); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Normalize(%q): expected error containing %q, got %v", name, wantErr, err)
+		}
+	}
+}
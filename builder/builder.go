@@ -0,0 +1,338 @@
+// Package builder composes GraphQL operations programmatically:
+//
+//	q := builder.Query("user").
+//		Variable("id", "ID!").
+//		Arg("id", builder.Var("id")).
+//		Select("name", builder.Field("friends").Select("name"))
+//	src, err := q.Build()
+//
+// The result is an ast.Document or its canonical source text, so services
+// building dynamic queries do not need to concatenate strings.
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/format"
+)
+
+// Variable references an operation variable in an argument value.
+type Variable string
+
+// Var returns a reference to the variable $name.
+func Var(name string) Variable {
+	return Variable(name)
+}
+
+// EnumValue is an unquoted enum literal in an argument value.
+type EnumValue string
+
+// Enum returns the enum literal value.
+func Enum(value string) EnumValue {
+	return EnumValue(value)
+}
+
+// FieldBuilder builds a field selection. Builders returned by Query,
+// Mutation and Subscription additionally carry the enclosing operation.
+type FieldBuilder struct {
+	field *ast.Field
+	op    *operation
+	err   error
+}
+
+// operation holds the operation-level state of a root builder.
+type operation struct {
+	kind string
+	name string
+	vars []ast.VariableDefinition
+}
+
+// Query starts a query operation selecting the root field name.
+func Query(name string) *FieldBuilder {
+	return root("query", name)
+}
+
+// Mutation starts a mutation operation selecting the root field name.
+func Mutation(name string) *FieldBuilder {
+	return root("mutation", name)
+}
+
+// Subscription starts a subscription operation selecting the root field name.
+func Subscription(name string) *FieldBuilder {
+	return root("subscription", name)
+}
+
+// root creates a builder for the root field of a new operation.
+func root(kind, name string) *FieldBuilder {
+	f := Field(name)
+	f.op = &operation{kind: kind}
+	return f
+}
+
+// Field creates a builder for a nested field selection.
+func Field(name string) *FieldBuilder {
+	return &FieldBuilder{field: &ast.Field{Name: name}}
+}
+
+// Name sets the operation name. It only applies to root builders.
+func (f *FieldBuilder) Name(name string) *FieldBuilder {
+	if f.op == nil {
+		f.setErr(fmt.Errorf("Name(%q) called on nested field %q", name, f.field.Name))
+		return f
+	}
+	f.op.name = name
+	return f
+}
+
+// Variable declares the operation variable $name of the given GraphQL type
+// (e.g., "ID!" or "[String]"). It only applies to root builders.
+func (f *FieldBuilder) Variable(name, typ string) *FieldBuilder {
+	if f.op == nil {
+		f.setErr(fmt.Errorf("Variable(%q) called on nested field %q", name, f.field.Name))
+		return f
+	}
+	t, err := parseType(typ)
+	if err != nil {
+		f.setErr(fmt.Errorf("variable $%s: %v", name, err))
+		return f
+	}
+	f.op.vars = append(f.op.vars, ast.VariableDefinition{Variable: name, Type: *t})
+	return f
+}
+
+// Arg adds an argument to the field. The value may be a Go scalar, slice,
+// map with string keys, Variable, EnumValue or *ast.Value.
+func (f *FieldBuilder) Arg(name string, value interface{}) *FieldBuilder {
+	v, err := toValue(value)
+	if err != nil {
+		f.setErr(fmt.Errorf("argument %q of field %q: %v", name, f.field.Name, err))
+		return f
+	}
+	f.field.Arguments = append(f.field.Arguments, ast.Argument{Name: name, Value: v})
+	return f
+}
+
+// Select adds sub-selections to the field. Each entry is either a field
+// name or a *FieldBuilder for a field with its own arguments or selections.
+func (f *FieldBuilder) Select(fields ...interface{}) *FieldBuilder {
+	if f.field.SelectionSet == nil {
+		f.field.SelectionSet = &ast.SelectionSet{}
+	}
+	for _, sel := range fields {
+		switch s := sel.(type) {
+		case string:
+			f.field.SelectionSet.Selections = append(f.field.SelectionSet.Selections, &ast.Field{Name: s})
+		case *FieldBuilder:
+			if s.err != nil {
+				f.setErr(s.err)
+			}
+			if s.op != nil {
+				f.setErr(fmt.Errorf("cannot select root field %q inside %q", s.field.Name, f.field.Name))
+				continue
+			}
+			f.field.SelectionSet.Selections = append(f.field.SelectionSet.Selections, s.field)
+		default:
+			f.setErr(fmt.Errorf("unsupported selection %T in field %q", sel, f.field.Name))
+		}
+	}
+	return f
+}
+
+// Document returns the operation as a parsed document. Every variable
+// referenced in an argument must have been declared with Variable.
+func (f *FieldBuilder) Document() (*ast.Document, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.op == nil {
+		return nil, fmt.Errorf("field %q is not the root of an operation", f.field.Name)
+	}
+	declared := make(map[string]bool)
+	for _, v := range f.op.vars {
+		declared[v.Variable] = true
+	}
+	if err := checkVariables(f.field, declared); err != nil {
+		return nil, err
+	}
+	op := &ast.OperationDefinition{
+		Operation:           f.op.kind,
+		Name:                f.op.name,
+		VariableDefinitions: f.op.vars,
+		SelectionSet:        &ast.SelectionSet{Selections: []ast.Selection{f.field}},
+	}
+	return &ast.Document{Definitions: []ast.Definition{op}}, nil
+}
+
+// Build returns the operation as canonical GraphQL source.
+func (f *FieldBuilder) Build() (string, error) {
+	doc, err := f.Document()
+	if err != nil {
+		return "", err
+	}
+	return format.Query(doc, format.Options{}), nil
+}
+
+// String returns the operation source, or the build error prefixed with
+// "!" if the operation is invalid.
+func (f *FieldBuilder) String() string {
+	s, err := f.Build()
+	if err != nil {
+		return "!" + err.Error()
+	}
+	return s
+}
+
+// setErr records the first error encountered while building.
+func (f *FieldBuilder) setErr(err error) {
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+// checkVariables reports the first variable used in field that is not declared.
+func checkVariables(field *ast.Field, declared map[string]bool) error {
+	for _, arg := range field.Arguments {
+		if name, ok := undeclaredVariable(arg.Value, declared); ok {
+			return fmt.Errorf("variable $%s is used but not declared", name)
+		}
+	}
+	if field.SelectionSet != nil {
+		for _, sel := range field.SelectionSet.Selections {
+			if sub, ok := sel.(*ast.Field); ok {
+				if err := checkVariables(sub, declared); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// undeclaredVariable finds a variable reference in val that is not declared.
+func undeclaredVariable(val *ast.Value, declared map[string]bool) (string, bool) {
+	switch val.Kind {
+	case "Variable":
+		if !declared[val.Literal] {
+			return val.Literal, true
+		}
+	case "Object":
+		keys := make([]string, 0, len(val.ObjectFields))
+		for key := range val.ObjectFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if name, ok := undeclaredVariable(val.ObjectFields[key], declared); ok {
+				return name, true
+			}
+		}
+	case "Array":
+		for _, elem := range val.List {
+			if name, ok := undeclaredVariable(elem, declared); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// toValue converts a Go value into an AST value literal.
+func toValue(value interface{}) (*ast.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return &ast.Value{Kind: "Null", Literal: "null"}, nil
+	case *ast.Value:
+		return v, nil
+	case Variable:
+		return &ast.Value{Kind: "Variable", Literal: string(v)}, nil
+	case EnumValue:
+		return &ast.Value{Kind: "Enum", Literal: string(v)}, nil
+	case string:
+		return &ast.Value{Kind: "String", Literal: v}, nil
+	case bool:
+		return &ast.Value{Kind: "Boolean", Literal: strconv.FormatBool(v)}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &ast.Value{Kind: "Int", Literal: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &ast.Value{Kind: "Int", Literal: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &ast.Value{Kind: "Float", Literal: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.Slice, reflect.Array:
+		list := make([]*ast.Value, rv.Len())
+		for i := range list {
+			elem, err := toValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return &ast.Value{Kind: "Array", List: list}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys must be strings, got %s", rv.Type().Key())
+		}
+		fields := make(map[string]*ast.Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem, err := toValue(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			fields[iter.Key().String()] = elem
+		}
+		return &ast.Value{Kind: "Object", ObjectFields: fields}, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// parseType parses a GraphQL type reference such as "[ID!]!".
+func parseType(s string) (*ast.Type, error) {
+	t, rest, err := parseTypeRef(s)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("invalid type %q", s)
+	}
+	return t, nil
+}
+
+// parseTypeRef parses a type from the start of s and returns the remainder.
+func parseTypeRef(s string) (*ast.Type, string, error) {
+	var t *ast.Type
+	switch {
+	case s == "":
+		return nil, "", fmt.Errorf("empty type")
+	case s[0] == '[':
+		elem, rest, err := parseTypeRef(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		if rest == "" || rest[0] != ']' {
+			return nil, "", fmt.Errorf("missing ']' in type")
+		}
+		t, s = &ast.Type{IsList: true, Elem: elem}, rest[1:]
+	default:
+		i := 0
+		for i < len(s) && (s[i] == '_' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z' || i > 0 && s[i] >= '0' && s[i] <= '9') {
+			i++
+		}
+		if i == 0 {
+			return nil, "", fmt.Errorf("invalid type name in %q", s)
+		}
+		t, s = &ast.Type{Name: s[:i]}, s[i:]
+	}
+	if s != "" && s[0] == '!' {
+		t.NonNull = true
+		s = s[1:]
+	}
+	return t, s, nil
+}
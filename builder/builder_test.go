@@ -0,0 +1,42 @@
+package builder
+
+import "testing"
+
+func TestBuild(t *testing.T) {
+	q := Query("user").
+		Name("GetUser").
+		Variable("id", "ID!").
+		Arg("id", Var("id")).
+		Select("name", Field("friends").Arg("first", 2).Arg("order", Enum("ASC")).Select("name"))
+
+	got, err := q.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `query GetUser($id: ID!) {
+  user(id: $id) {
+    name
+    friends(first: 2, order: ASC) {
+      name
+    }
+  }
+}
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuildUndeclaredVariable(t *testing.T) {
+	_, err := Query("user").Arg("filter", map[string]interface{}{"id": Var("id")}).Select("name").Build()
+	if err == nil || err.Error() != "variable $id is used but not declared" {
+		t.Errorf("expected undeclared variable error, got %v", err)
+	}
+}
+
+func TestBuildInvalidVariableType(t *testing.T) {
+	_, err := Query("users").Variable("ids", "[ID!").Select("id").Build()
+	if err == nil {
+		t.Error("expected error for malformed variable type")
+	}
+}
@@ -0,0 +1,306 @@
+// Package cli implements the graphql command-line tool. It is a library so
+// services can build their own binary around an executor with registered
+// resolvers; cmd/graphql is the stock build that only offers mocked execution.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/format"
+	"github.com/Protocol-Lattice/graphql/introspection"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+	"github.com/Protocol-Lattice/graphql/schemadiff"
+	"github.com/Protocol-Lattice/graphql/validator"
+)
+
+const usage = `usage: graphql <command> [flags] [file]
+
+Commands:
+  validate    validate a query document against an SDL schema
+  format      print a schema or query document in canonical style
+  execute     run a query against a schema with mock or registered resolvers
+  introspect  print the introspection JSON for an SDL schema
+  diff        list the changes between two SDL schemas; fails on breaking ones
+
+Documents are read from the named file, or from standard input if omitted.
+Run "graphql <command> -h" for the flags of a command.
+`
+
+// errInvalid signals that a command already reported its failures.
+var errInvalid = errors.New("invalid document")
+
+// command is a CLI subcommand.
+type command struct {
+	exec   *executor.Executor
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run executes the command line in args (without the program name) and
+// returns the process exit code. If exec is non-nil, "execute" uses its
+// registered resolvers unless -mock is given; otherwise results are mocked
+// from the schema.
+func Run(args []string, exec *executor.Executor, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	c := &command{exec: exec, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	var err error
+	switch args[0] {
+	case "validate":
+		err = c.validate(args[1:])
+	case "format":
+		err = c.format(args[1:])
+	case "execute":
+		err = c.execute(args[1:])
+	case "introspect":
+		err = c.introspect(args[1:])
+	case "diff":
+		err = c.diff(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "graphql: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errInvalid):
+		return 1
+	default:
+		fmt.Fprintf(stderr, "graphql %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// flagSet creates a flag set for a subcommand that reports to stderr.
+func (c *command) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// validate implements "graphql validate -schema FILE [query-file]".
+func (c *command) validate(args []string) error {
+	fs := c.flagSet("validate")
+	schemaPath := fs.String("schema", "", "path to the SDL schema `file` (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return errors.New("-schema is required")
+	}
+	schema, err := c.loadDocument(*schemaPath)
+	if err != nil {
+		return err
+	}
+	doc, err := c.loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.report(fs.Arg(0), validator.Validate(schema, doc))
+}
+
+// format implements "graphql format [-sort] [file]".
+func (c *command) format(args []string) error {
+	fs := c.flagSet("format")
+	sortFields := fs.Bool("sort", false, "sort schema fields and arguments alphabetically")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	doc, err := c.loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var operations, types int
+	for _, def := range doc.Definitions {
+		switch def.(type) {
+		case *ast.OperationDefinition, *ast.FragmentDefinition:
+			operations++
+		default: // type system definitions
+			types++
+		}
+	}
+	switch {
+	case operations > 0 && types > 0:
+		return errors.New("cannot format a document mixing operations and type definitions")
+	case types > 0:
+		fmt.Fprint(c.stdout, format.Schema(doc, format.Options{SortFields: *sortFields}))
+	default:
+		fmt.Fprint(c.stdout, format.Query(doc, format.Options{}))
+	}
+	return nil
+}
+
+// execute implements "graphql execute [-schema FILE] [-variables JSON] [-mock] [query-file]".
+func (c *command) execute(args []string) error {
+	fs := c.flagSet("execute")
+	schemaPath := fs.String("schema", "", "path to the SDL schema `file` (required for mocking)")
+	variablesJSON := fs.String("variables", "", "query variables as a JSON `object`")
+	mock := fs.Bool("mock", c.exec == nil, "resolve fields with mock values generated from the schema")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	variables := map[string]interface{}{}
+	if *variablesJSON != "" {
+		if err := json.Unmarshal([]byte(*variablesJSON), &variables); err != nil {
+			return fmt.Errorf("invalid -variables JSON: %v", err)
+		}
+	}
+
+	var schema *ast.Document
+	if *schemaPath != "" {
+		var err error
+		if schema, err = c.loadDocument(*schemaPath); err != nil {
+			return err
+		}
+	}
+	doc, err := c.loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		if err := c.report(fs.Arg(0), validator.Validate(schema, doc)); err != nil {
+			return err
+		}
+	}
+
+	var result map[string]interface{}
+	switch {
+	case *mock:
+		if schema == nil {
+			return errors.New("-schema is required for mocked execution")
+		}
+		data, err := mockExecute(schema, doc)
+		if err != nil {
+			return err
+		}
+		result = map[string]interface{}{"data": data}
+	case c.exec == nil:
+		return errors.New("no registered resolvers available; use -mock")
+	default:
+		if result, err = c.exec.Execute(doc, variables); err != nil {
+			return err
+		}
+	}
+	return c.writeJSON(result)
+}
+
+// introspect implements "graphql introspect -schema FILE".
+func (c *command) introspect(args []string) error {
+	fs := c.flagSet("introspect")
+	schemaPath := fs.String("schema", "", "path to the SDL schema `file` (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return errors.New("-schema is required")
+	}
+	schema, err := c.loadDocument(*schemaPath)
+	if err != nil {
+		return err
+	}
+	return c.writeJSON(map[string]interface{}{
+		"data": map[string]interface{}{"__schema": introspection.Schema(schema)},
+	})
+}
+
+// diff implements "graphql diff OLD-FILE NEW-FILE". It fails if any change
+// is breaking.
+func (c *command) diff(args []string) error {
+	fs := c.flagSet("diff")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("expected an old and a new schema file")
+	}
+	oldSchema, err := c.loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newSchema, err := c.loadDocument(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes, err := schemadiff.Diff(oldSchema, newSchema)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		fmt.Fprintln(c.stdout, change)
+	}
+	if schemadiff.HasBreaking(changes) {
+		return errInvalid
+	}
+	return nil
+}
+
+// loadDocument reads and parses the document at path, or standard input if
+// path is empty or "-". Syntax errors are reported and cause errInvalid.
+func (c *command) loadDocument(path string) (*ast.Document, error) {
+	src := c.stdin
+	if path == "" || path == "-" {
+		path = "<stdin>"
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+	l := lexer.NewFromReader(src)
+	p := parser.New(l)
+	doc := p.ParseDocument()
+	if err := l.Err(); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, msg := range p.Errors() {
+		errs = append(errs, errors.New(msg))
+	}
+	if err := c.report(path, errs); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// report prints errs prefixed with the document name and returns errInvalid
+// if there were any.
+func (c *command) report(path string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if path == "" || path == "-" {
+		path = "<stdin>"
+	}
+	for _, err := range errs {
+		fmt.Fprintf(c.stderr, "%s: %v\n", path, err)
+	}
+	return errInvalid
+}
+
+// writeJSON writes v to stdout as indented JSON.
+func (c *command) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSchema = `type Query {
+  user(id: ID!): User
+}
+
+type User {
+  id: ID!
+  name: String!
+  friends: [User]
+}
+`
+
+func writeSchema(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	if err := os.WriteFile(path, []byte(testSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateReportsUnknownField(t *testing.T) {
+	schema := writeSchema(t)
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader(`{ user(id: "1") { id email } }`)
+	code := Run([]string{"validate", "-schema", schema}, nil, stdin, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `cannot query field "email" on type "User"`) {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+}
+
+func TestExecuteMock(t *testing.T) {
+	schema := writeSchema(t)
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader(`{ user(id: "1") { name friends { id } } }`)
+	code := Run([]string{"execute", "-schema", schema}, nil, stdin, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	var result struct {
+		Data struct {
+			User struct {
+				Name    string
+				Friends []struct{ ID string }
+			}
+		}
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if result.Data.User.Name != "Hello World" {
+		t.Errorf("expected mock name, got %q", result.Data.User.Name)
+	}
+	if len(result.Data.User.Friends) != mockListLength {
+		t.Errorf("expected %d mock friends, got %d", mockListLength, len(result.Data.User.Friends))
+	}
+}
+
+func TestExecuteMockFragments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	schema := strings.Replace(testSchema, "  user(id: ID!): User\n", "  user(id: ID!): User\n  node(id: ID!): Node\n  search: [SearchResult]\n", 1) + `
+interface Node { id: ID! }
+type Post implements Node { id: ID! title: String }
+union SearchResult = User | Post
+`
+	if err := os.WriteFile(path, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	query := `{
+  user(id: "1") { ...Person friends { id } ... on User { friends { name } } }
+  node(id: "1") { id ... on Post { title } ... on User { name } }
+  search { __typename ... on User { name } }
+}
+fragment Person on User { name }`
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"execute", "-schema", path}, nil, strings.NewReader(query), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	var result struct {
+		Data map[string]interface{}
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	out, _ := json.Marshal(result.Data)
+	want := `{"node":{"id":"1","title":"Hello World"},` +
+		`"search":[{"__typename":"User","name":"Hello World"},{"__typename":"User","name":"Hello World"}],` +
+		`"user":{"friends":[{"id":"1","name":"Hello World"},{"id":"1","name":"Hello World"}],"name":"Hello World"}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestDiffFailsOnBreakingChanges(t *testing.T) {
+	oldSchema := writeSchema(t)
+	newSchema := filepath.Join(t.TempDir(), "new.graphql")
+	changed := strings.Replace(testSchema, "  friends: [User]\n", "", 1)
+	if err := os.WriteFile(newSchema, []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"diff", oldSchema, newSchema}, nil, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "BREAKING: field User.friends was removed\n" {
+		t.Errorf("unexpected stdout: %q", got)
+	}
+}
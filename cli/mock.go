@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// mockListLength is the number of items generated for list fields.
+const mockListLength = 2
+
+// mockScalars holds the placeholder values used for built-in scalars.
+var mockScalars = map[string]interface{}{
+	"String":  "Hello World",
+	"Int":     42,
+	"Float":   3.14,
+	"Boolean": true,
+	"ID":      "1",
+}
+
+// mocker builds mock results against the types of a schema.
+type mocker struct {
+	types     map[string]*ast.TypeDefinition
+	members   map[string][]string // object types of each interface and union
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool // fragments being spread, to break cycles
+}
+
+// mockExecute resolves the first operation in doc using placeholder values
+// derived from the field types declared in schema. Fields of interface and
+// union types are mocked as their first object type.
+func mockExecute(schema, doc *ast.Document) (map[string]interface{}, error) {
+	m := &mocker{
+		types:     make(map[string]*ast.TypeDefinition),
+		members:   make(map[string][]string),
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+	for _, def := range schema.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			m.types[def.Name] = def
+			for _, iface := range def.Interfaces {
+				m.members[iface] = append(m.members[iface], def.Name)
+			}
+		case *ast.UnionTypeDefinition:
+			m.members[def.Name] = append(m.members[def.Name], def.Types...)
+		}
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			m.fragments[frag.Name] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || op.SelectionSet == nil {
+			continue
+		}
+		root, ok := m.types[schema.RootTypeName(op.Operation)]
+		if !ok {
+			return nil, fmt.Errorf("schema has no root type for %s operations", op.Operation)
+		}
+		return m.selectionSet(root, op.SelectionSet), nil
+	}
+	return nil, fmt.Errorf("no operation found")
+}
+
+// selectionSet builds a mock result for ss selected on parent.
+func (m *mocker) selectionSet(parent *ast.TypeDefinition, ss *ast.SelectionSet) map[string]interface{} {
+	result := make(map[string]interface{})
+	m.collect(result, parent, ss)
+	return result
+}
+
+// collect adds the mock values of the fields selected by ss on parent to
+// result, including those of the fragments that apply to parent.
+func (m *mocker) collect(result map[string]interface{}, parent *ast.TypeDefinition, ss *ast.SelectionSet) {
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Name == "__typename" {
+				result[sel.ResponseKey()] = parent.Name
+				continue
+			}
+			var fieldType *ast.Type
+			if def := parent.Field(sel.Name); def != nil {
+				fieldType = def.Type
+			}
+			key := sel.ResponseKey()
+			result[key] = merge(result[key], m.value(fieldType, sel.SelectionSet))
+		case *ast.FragmentSpread:
+			frag, ok := m.fragments[sel.Name]
+			if !ok || m.visiting[sel.Name] || frag.SelectionSet == nil || !m.applies(frag.TypeCondition, parent) {
+				continue
+			}
+			m.visiting[sel.Name] = true
+			m.collect(result, parent, frag.SelectionSet)
+			delete(m.visiting, sel.Name)
+		case *ast.InlineFragment:
+			if sel.SelectionSet != nil && (sel.TypeCondition == "" || m.applies(sel.TypeCondition, parent)) {
+				m.collect(result, parent, sel.SelectionSet)
+			}
+		}
+	}
+}
+
+// applies reports whether a fragment with typeCondition applies to the
+// object type parent: the condition names it, or an interface or union it
+// belongs to.
+func (m *mocker) applies(typeCondition string, parent *ast.TypeDefinition) bool {
+	if typeCondition == parent.Name {
+		return true
+	}
+	for _, member := range m.members[typeCondition] {
+		if member == parent.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// merge returns value merged into existing, the value already selected
+// under the same response key, such as by another fragment: the fields of
+// objects and the items of lists are merged, and other values replaced.
+func merge(existing, value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if object, ok := existing.(map[string]interface{}); ok {
+			for k, v := range value {
+				object[k] = merge(object[k], v)
+			}
+			return object
+		}
+	case []interface{}:
+		if list, ok := existing.([]interface{}); ok && len(list) == len(value) {
+			for i, v := range value {
+				list[i] = merge(list[i], v)
+			}
+			return list
+		}
+	}
+	return value
+}
+
+// value builds a mock value of type t, recursing into object selections.
+// Interfaces and unions are mocked as their first object type.
+func (m *mocker) value(t *ast.Type, ss *ast.SelectionSet) interface{} {
+	if t == nil {
+		return nil
+	}
+	if t.IsList {
+		list := make([]interface{}, mockListLength)
+		for i := range list {
+			list[i] = m.value(t.Elem, ss)
+		}
+		return list
+	}
+	name := t.Name
+	if members := m.members[name]; len(members) > 0 {
+		name = members[0]
+	}
+	if def, ok := m.types[name]; ok {
+		if ss == nil {
+			return nil
+		}
+		return m.selectionSet(def, ss)
+	}
+	if v, ok := mockScalars[name]; ok {
+		return v
+	}
+	return name
+}
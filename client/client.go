@@ -0,0 +1,163 @@
+// Package client is a minimal GraphQL-over-HTTP client that decodes
+// responses into typed Go values.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/variables"
+)
+
+// Client sends GraphQL requests to a single endpoint.
+type Client struct {
+	// HTTPClient is used to send requests. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Header is added to every request.
+	Header http.Header
+
+	endpoint string
+}
+
+// New creates a Client for the GraphQL endpoint URL.
+func New(endpoint string) *Client {
+	return &Client{endpoint: endpoint, Header: make(http.Header)}
+}
+
+// Request is a single GraphQL operation.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+
+	// Header is added to this request only.
+	Header http.Header `json:"-"`
+}
+
+// NewRequest creates a Request for the given query source.
+func NewRequest(query string) *Request {
+	return &Request{Query: query}
+}
+
+// SetVariables replaces the request variables with v, which may be a map or
+// a struct converted with variables.Marshal.
+func (r *Request) SetVariables(v interface{}) error {
+	vars, err := variables.Marshal(v)
+	if err != nil {
+		return err
+	}
+	r.Variables = vars
+	return nil
+}
+
+// Error is a single entry of a GraphQL response's errors array.
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Location is a position in the query source.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error returns the error message.
+func (e Error) Error() string {
+	return e.Message
+}
+
+// Errors is the errors array of a GraphQL response. Do returns it when the
+// server reports errors; any data received alongside is still decoded.
+type Errors []Error
+
+// Error joins the messages of all errors.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// HTTPError is returned when the server responds with a non-GraphQL error.
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error describes the HTTP status and response body.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("graphql: server returned %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+// response is the wire format of a GraphQL response.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// Do sends req and decodes the response's data object into result, which
+// must be a pointer (or nil to discard the data). Struct fields are matched
+// to response keys by the alias or field name in their `graphql` tag, then
+// their `json` tag, then case-insensitively by Go field name. If the
+// response contains errors, Do returns them as Errors.
+func (c *Client) Do(ctx context.Context, req *Request, result interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	for key, values := range c.Header {
+		httpReq.Header[key] = values
+	}
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var out response
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		if resp.StatusCode >= 300 {
+			return &HTTPError{StatusCode: resp.StatusCode, Body: respBody}
+		}
+		return fmt.Errorf("graphql: invalid response: %v", err)
+	}
+	if result != nil && len(out.Data) > 0 && string(out.Data) != "null" {
+		if err := Decode(out.Data, result); err != nil {
+			return err
+		}
+	}
+	if len(out.Errors) > 0 {
+		return out.Errors
+	}
+	if resp.StatusCode >= 300 {
+		return &HTTPError{StatusCode: resp.StatusCode, Body: respBody}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if req.Variables["id"] != "1" {
+			t.Errorf("expected variable id=1, got %v", req.Variables["id"])
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("expected Authorization header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"me":{"id":"1","full_name":"Ada","Age":36},"friends":[{"name":"Bob"}]},
+			"errors":[{"message":"friends partially unavailable","path":["friends"]}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	c.Header.Set("Authorization", "Bearer token")
+	req := NewRequest(`query ($id: ID!) { me: user(id: $id) { id full_name: name age } friends { name } }`)
+	if err := req.SetVariables(struct {
+		ID string `json:"id"`
+	}{"1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Me struct {
+			ID   string
+			Name string `graphql:"full_name: name"`
+			Age  int
+		} `graphql:"me: user(id: $id)"`
+		Friends []*struct {
+			Name string `json:"name"`
+		}
+	}
+	err := c.Do(context.Background(), req, &result)
+
+	var gqlErrs Errors
+	if !errors.As(err, &gqlErrs) || len(gqlErrs) != 1 || gqlErrs[0].Message != "friends partially unavailable" {
+		t.Fatalf("expected typed errors, got %v", err)
+	}
+	if result.Me.ID != "1" || result.Me.Name != "Ada" || result.Me.Age != 36 {
+		t.Errorf("unexpected user: %+v", result.Me)
+	}
+	if len(result.Friends) != 1 || result.Friends[0].Name != "Bob" {
+		t.Errorf("unexpected friends: %+v", result.Friends)
+	}
+}
+
+func TestDoHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL).Do(context.Background(), NewRequest("{ hello }"), nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected HTTPError with status 500, got %v", err)
+	}
+}
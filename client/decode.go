@@ -0,0 +1,154 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Decode unmarshals a JSON response object into v using the same field
+// matching rules as Client.Do.
+func Decode(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("graphql: decode target must be a non-nil pointer, got %T", v)
+	}
+	return decode(data, rv.Elem())
+}
+
+// decode unmarshals raw into the settable value rv.
+func decode(raw json.RawMessage, rv reflect.Value) error {
+	if rv.CanAddr() && rv.Addr().Type().Implements(unmarshalerType) {
+		return json.Unmarshal(raw, rv.Addr().Interface())
+	}
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if string(raw) == "null" {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(raw, rv.Elem())
+	case reflect.Struct:
+		if string(raw) == "null" {
+			return nil
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return err
+		}
+		return decodeStruct(obj, rv)
+	case reflect.Slice:
+		if string(raw) == "null" {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return json.Unmarshal(raw, rv.Addr().Interface())
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decode(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	}
+	return json.Unmarshal(raw, rv.Addr().Interface())
+}
+
+// decodeStruct assigns the entries of obj to the fields of the struct rv.
+// Untagged embedded structs and inline fragment fields ("... on Type") are
+// decoded from the same object.
+func decodeStruct(obj map[string]json.RawMessage, rv reflect.Value) error {
+	typ := rv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		key, inline := responseKey(sf)
+		if key == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if inline || sf.Anonymous && key == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						fv.Set(reflect.New(ft))
+					}
+					fv = fv.Elem()
+				}
+				if err := decodeStruct(obj, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		raw, ok := lookupKey(obj, key, sf.Name)
+		if !ok {
+			continue
+		}
+		if err := decode(raw, fv); err != nil {
+			return fmt.Errorf("graphql: decoding field %q: %v", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// responseKey returns the response key declared by a field's `graphql` or
+// `json` tag (empty if untagged), and whether the field is an inline fragment.
+func responseKey(sf reflect.StructField) (key string, inline bool) {
+	if tag, ok := sf.Tag.Lookup("graphql"); ok {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "...") {
+			return "", true
+		}
+		if i := strings.IndexAny(tag, "(@ {"); i >= 0 {
+			if colon := strings.Index(tag, ":"); colon < 0 || colon > i {
+				tag = tag[:i]
+			}
+		}
+		if colon := strings.Index(tag, ":"); colon >= 0 {
+			tag = tag[:colon] // alias
+		}
+		return strings.TrimSpace(tag), false
+	}
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		return strings.Split(tag, ",")[0], false
+	}
+	return "", false
+}
+
+// lookupKey finds the entry for key (or, if untagged, the Go field name
+// matched case-insensitively).
+func lookupKey(obj map[string]json.RawMessage, key, fieldName string) (json.RawMessage, bool) {
+	if key != "" {
+		raw, ok := obj[key]
+		return raw, ok
+	}
+	if raw, ok := obj[fieldName]; ok {
+		return raw, true
+	}
+	for k, raw := range obj {
+		if strings.EqualFold(k, fieldName) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,23 @@
+// Command graphql validates, formats and executes GraphQL documents.
+//
+// Usage:
+//
+//	graphql validate -schema schema.graphql query.graphql
+//	graphql format query.graphql
+//	graphql execute -schema schema.graphql -variables '{"id": "1"}' query.graphql
+//	graphql introspect -schema schema.graphql
+//
+// This build has no resolvers of its own, so "execute" returns mock data
+// generated from the schema. To run queries against real resolvers, call
+// cli.Run from a program that registers them.
+package main
+
+import (
+	"os"
+
+	"github.com/Protocol-Lattice/graphql/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], nil, os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,274 @@
+// Package complexity estimates the cost of GraphQL operations so servers
+// can reject expensive queries before executing them.
+//
+// Costs are declared next to the schema with the @cost directive:
+//
+//	type Query {
+//	  users(limit: Int): [User] @cost(weight: 5, multipliers: ["limit"])
+//	}
+//
+// A field costs its weight plus the cost of its selections multiplied by
+// the values of its multiplier arguments (list arguments count their
+// length), so the query above with limit: 10 selecting two scalar fields
+// costs 5 + 10*2 = 25. Negative multipliers count as zero, and costs too
+// large for an int saturate at math.MaxInt.
+//
+// Handlers enforce a cost limit with handler.Config.Complexity and
+// handler.Config.MaxCost.
+package complexity
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/executor"
+)
+
+// Analyzer computes operation costs against a schema.
+type Analyzer struct {
+	// DefaultCost is the weight of fields without a cost. It defaults to 1.
+	DefaultCost int
+	// Costs sets weights keyed by "Type.field" for fields whose definition
+	// has no @cost directive.
+	Costs map[string]int
+
+	schema *ast.Document
+	types  map[string]*ast.TypeDefinition
+}
+
+// New creates an Analyzer for the type definitions in schema.
+func New(schema *ast.Document) *Analyzer {
+	a := &Analyzer{DefaultCost: 1, schema: schema, types: make(map[string]*ast.TypeDefinition)}
+	for _, def := range schema.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			a.types[def.Name] = def
+		case *ast.InterfaceTypeDefinition:
+			// Fields selected on an interface are looked up like an object's.
+			a.types[def.Name] = &ast.TypeDefinition{Name: def.Name, Fields: def.Fields}
+		case *ast.UnionTypeDefinition:
+			// Unions have no fields of their own; members are selected
+			// through fragments.
+			a.types[def.Name] = &ast.TypeDefinition{Name: def.Name}
+		}
+	}
+	return a
+}
+
+// costing is the state of a single cost computation.
+type costing struct {
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+	costs     map[string]int // costs of the fragments costed so far
+}
+
+// Cost returns the estimated cost of op in doc with the given variables.
+// Fragment spreads count the cost of the fragment's selections, as defined
+// in doc, and variables op declares but variables omits count their
+// default values.
+func (a *Analyzer) Cost(doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}) int {
+	return a.cost(op, fragmentDefinitions(doc), variables)
+}
+
+// fragmentDefinitions returns the fragments defined in doc by name.
+func fragmentDefinitions(doc *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name] = frag
+		}
+	}
+	return fragments
+}
+
+// cost returns the estimated cost of op, with variables coerced and
+// defaulted as the executor does.
+func (a *Analyzer) cost(op *ast.OperationDefinition, fragments map[string]*ast.FragmentDefinition, variables map[string]interface{}) int {
+	if op.SelectionSet == nil {
+		return 0
+	}
+	c := &costing{
+		variables: executor.VariableValues(op, variables),
+		fragments: fragments,
+		visiting:  make(map[string]bool),
+		costs:     make(map[string]int),
+	}
+	return a.selectionSetCost(a.types[a.schema.RootTypeName(op.Operation)], op.SelectionSet, c)
+}
+
+// Check returns an *errcode.Error if any operation in doc costs more than
+// limit. Fragment spreads count the cost of the fragment's selections.
+func (a *Analyzer) Check(doc *ast.Document, variables map[string]interface{}, limit int) error {
+	fragments := fragmentDefinitions(doc)
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if cost := a.cost(op, fragments, variables); cost > limit {
+			return errcode.New(errcode.CostLimitExceeded, "cost", cost, "limit", limit)
+		}
+	}
+	return nil
+}
+
+// selectionSetCost sums the cost of the fields selected on parent, which is
+// nil if the type is unknown.
+func (a *Analyzer) selectionSetCost(parent *ast.TypeDefinition, ss *ast.SelectionSet, c *costing) int {
+	total := 0
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			total = addCost(total, a.fieldCost(parent, sel, c))
+		case *ast.FragmentSpread:
+			total = addCost(total, a.fragmentCost(sel.Name, c))
+		case *ast.InlineFragment:
+			typeDef := parent
+			if sel.TypeCondition != "" {
+				typeDef = a.types[sel.TypeCondition]
+			}
+			if sel.SelectionSet != nil {
+				total = addCost(total, a.selectionSetCost(typeDef, sel.SelectionSet, c))
+			}
+		}
+	}
+	return total
+}
+
+// fragmentCost returns the cost of the selections of the fragment named
+// name. It is computed once per operation, however often the fragment is
+// spread; spreads of a fragment within itself cost nothing.
+func (a *Analyzer) fragmentCost(name string, c *costing) int {
+	if cost, ok := c.costs[name]; ok {
+		return cost
+	}
+	frag, ok := c.fragments[name]
+	if !ok || c.visiting[name] || frag.SelectionSet == nil {
+		return 0
+	}
+	c.visiting[name] = true
+	cost := a.selectionSetCost(a.types[frag.TypeCondition], frag.SelectionSet, c)
+	delete(c.visiting, name)
+	c.costs[name] = cost
+	return cost
+}
+
+// fieldCost returns the cost of a selected field including its selections.
+func (a *Analyzer) fieldCost(parent *ast.TypeDefinition, field *ast.Field, c *costing) int {
+	variables := c.variables
+	var def *ast.Field
+	if parent != nil {
+		def = parent.Field(field.Name)
+	}
+	weight := a.DefaultCost
+	if parent != nil {
+		if w, ok := a.Costs[parent.Name+"."+field.Name]; ok {
+			weight = w
+		}
+	}
+	var costDirective *ast.Directive
+	if def != nil {
+		costDirective = def.Directive("cost")
+	}
+	if costDirective != nil {
+		if w, ok := intValue(costDirective.Argument("weight"), variables); ok {
+			weight = w
+		}
+	}
+	weight = max(weight, 0)
+	if field.SelectionSet == nil {
+		return weight
+	}
+
+	var child *ast.TypeDefinition
+	if def != nil && def.Type != nil {
+		child = a.types[def.Type.NamedType()]
+	}
+	multiplier := 1
+	if costDirective != nil {
+		if names := costDirective.Argument("multipliers"); names != nil {
+			for _, name := range names.List {
+				if n, ok := multiplierValue(field, name.Literal, variables); ok {
+					// A negative multiplier would make the cost negative.
+					multiplier = mulCost(multiplier, max(n, 0))
+				}
+			}
+		}
+	}
+	return addCost(weight, mulCost(multiplier, a.selectionSetCost(child, field.SelectionSet, c)))
+}
+
+// addCost returns a+b for non-negative costs, saturating at math.MaxInt.
+func addCost(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// mulCost returns a*b for non-negative costs, saturating at math.MaxInt.
+func mulCost(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// multiplierValue returns the numeric value of the named argument of field:
+// an integer, or the length of a list.
+func multiplierValue(field *ast.Field, name string, variables map[string]interface{}) (int, bool) {
+	for _, arg := range field.Arguments {
+		if arg.Name != name {
+			continue
+		}
+		if arg.Value != nil && arg.Value.Kind == "Array" {
+			return len(arg.Value.List), true
+		}
+		if arg.Value != nil && arg.Value.Kind == "Variable" {
+			if list, ok := variables[arg.Value.Literal].([]interface{}); ok {
+				return len(list), true
+			}
+		}
+		return intValue(arg.Value, variables)
+	}
+	return 0, false
+}
+
+// intValue converts an Int or numeric String literal, or a variable holding
+// a number, to an int.
+func intValue(val *ast.Value, variables map[string]interface{}) (int, bool) {
+	if val == nil {
+		return 0, false
+	}
+	switch val.Kind {
+	case "Int", "String":
+		n, err := strconv.Atoi(val.Literal)
+		// Out of range values are clamped to the nearest int.
+		return n, err == nil || errors.Is(err, strconv.ErrRange)
+	case "Variable":
+		switch n := variables[val.Literal].(type) {
+		case int:
+			return n, true
+		case int64:
+			return int(n), true
+		case json.Number:
+			// Numbers too large for a float64 stay json.Number.
+			i, err := strconv.Atoi(n.String())
+			return i, err == nil || errors.Is(err, strconv.ErrRange)
+		case float64:
+			switch {
+			case n >= math.MaxInt:
+				return math.MaxInt, true
+			case n <= math.MinInt:
+				return math.MinInt, true
+			}
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package complexity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+const testSchema = `type Query {
+  users(limit: Int, ids: [ID]): [User] @cost(weight: 5, multipliers: ["limit", "ids"])
+  me: User
+}
+
+type User {
+  id: ID!
+  name: String
+  posts: [Post] @cost(weight: "2")
+}
+
+type Post {
+  title: String
+}
+`
+
+func parse(t *testing.T, src string) *ast.Document {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return doc
+}
+
+func TestCost(t *testing.T) {
+	a := New(parse(t, testSchema))
+	tests := []struct {
+		query string
+		vars  map[string]interface{}
+		want  int
+	}{
+		{`{ me { id name } }`, nil, 1 + 2},
+		{`{ users(limit: 10) { id name } }`, nil, 5 + 10*2},
+		{`query($n: Int) { users(limit: $n) { id } }`, map[string]interface{}{"n": float64(3)}, 5 + 3*1},
+		{`{ users(limit: 2, ids: ["a", "b", "c"]) { id } }`, nil, 5 + 6*1},
+		{`{ users { posts { title } } }`, nil, 5 + (2 + 1)},
+	}
+	for _, tt := range tests {
+		doc := parse(t, tt.query)
+		op := doc.Definitions[0].(*ast.OperationDefinition)
+		if got := a.Cost(doc, op, tt.vars); got != tt.want {
+			t.Errorf("%s: expected cost %d, got %d", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestCostsFallbackAndCheck(t *testing.T) {
+	a := New(parse(t, testSchema))
+	a.Costs = map[string]int{"Query.me": 10, "Query.users": 99}
+	doc := parse(t, `{ me { id } users(limit: 1) { id } }`)
+	op := doc.Definitions[0].(*ast.OperationDefinition)
+	// Query.users keeps its @cost weight; Query.me uses the Go-side cost.
+	if got, want := a.Cost(doc, op, nil), (10+1)+(5+1); got != want {
+		t.Errorf("expected cost %d, got %d", want, got)
+	}
+	if err := a.Check(doc, nil, 16); err == nil || !strings.Contains(err.Error(), "exceeds the limit of 16") {
+		t.Errorf("expected limit error, got %v", err)
+	}
+	if err := a.Check(doc, nil, 17); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckExpandsFragments(t *testing.T) {
+	a := New(parse(t, testSchema))
+	doc := parse(t, `{ users(limit: 10) { ...U } } fragment U on User { id ...U }`)
+	if err := a.Check(doc, nil, 14); err == nil || !strings.Contains(err.Error(), "operation cost 15") {
+		t.Errorf("expected fragment fields to be counted, got %v", err)
+	}
+}
+
+func TestCostResolvesFragments(t *testing.T) {
+	a := New(parse(t, testSchema))
+	doc := parse(t, `{ users(limit: 10) { ...U } } fragment U on User { id name }`)
+	if got, want := a.Cost(doc, doc.Definitions[0].(*ast.OperationDefinition), nil), 5+10*2; got != want {
+		t.Errorf("expected cost %d, got %d", want, got)
+	}
+}
+
+func TestCostCountsVariableDefaults(t *testing.T) {
+	a := New(parse(t, testSchema))
+	doc := parse(t, `query($limit: Int = 1000000) { users(limit: $limit) { id name } }`)
+	op := doc.Definitions[0].(*ast.OperationDefinition)
+	if got, want := a.Cost(doc, op, nil), 5+1000000*2; got != want {
+		t.Errorf("expected cost %d, got %d", want, got)
+	}
+	if got, want := a.Cost(doc, op, map[string]interface{}{"limit": float64(3)}), 5+3*2; got != want {
+		t.Errorf("expected a provided variable to override its default, cost %d, got %d", want, got)
+	}
+	if err := a.Check(doc, nil, 100); err == nil {
+		t.Error("expected a defaulted multiplier to exceed the limit")
+	}
+}
+
+func TestCostSpreadsFragmentsOnce(t *testing.T) {
+	// Each fragment spreads the next twice, so expanding every spread
+	// would cost the last fragment 2^25 times.
+	var sb strings.Builder
+	sb.WriteString(`{ me { ...F0 } }`)
+	for i := 0; i < 26; i++ {
+		fmt.Fprintf(&sb, " fragment F%d on User { posts { title }", i)
+		if i < 25 {
+			fmt.Fprintf(&sb, " ...F%d ...F%d", i+1, i+1)
+		}
+		sb.WriteString(" }")
+	}
+	doc := parse(t, sb.String())
+	a := New(parse(t, testSchema))
+	start := time.Now()
+	cost := a.Cost(doc, doc.Definitions[0].(*ast.OperationDefinition), nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected each fragment to be costed once, took %v", elapsed)
+	}
+	// F25 costs 2+1 for posts and its title, and every other fragment
+	// that plus twice the next one.
+	want := 3
+	for i := 0; i < 25; i++ {
+		want = 3 + 2*want
+	}
+	if cost != 1+want {
+		t.Errorf("expected cost %d, got %d", 1+want, cost)
+	}
+}
+
+func TestCostCannotBeBypassed(t *testing.T) {
+	a := New(parse(t, testSchema))
+	tests := []struct {
+		query string
+		vars  map[string]interface{}
+	}{
+		// Negative multipliers must not make the cost negative.
+		{`{ me { id } users(limit: -1000) { id name } users(limit: -1000) { id name } }`, nil},
+		// Large multipliers must not overflow.
+		{`{ users(limit: 99999999999) { posts { title } } a: users(limit: 9223372036854775807) { id } }`, nil},
+		{`query($n: Int) { users(limit: $n) { id } }`, map[string]interface{}{"n": 1e300}},
+		{`query($n: Int) { users(limit: $n) { id } }`, map[string]interface{}{"n": json.Number("99999999999999999999")}},
+	}
+	for _, tt := range tests {
+		doc := parse(t, tt.query)
+		if cost := a.Cost(doc, doc.Definitions[0].(*ast.OperationDefinition), tt.vars); cost < 5 {
+			t.Errorf("%s: expected a non-negative cost, got %d", tt.query, cost)
+		}
+	}
+	doc := parse(t, `{ users(limit: 9223372036854775807) { posts { title } } }`)
+	if err := a.Check(doc, nil, 100); err == nil {
+		t.Error("expected an overflowing cost to exceed the limit")
+	}
+	doc = parse(t, `{ users(limit: -1000) { name name name } }`)
+	if a.Cost(doc, doc.Definitions[0].(*ast.OperationDefinition), nil) != 5 {
+		t.Error("expected a negative multiplier to count as zero")
+	}
+}
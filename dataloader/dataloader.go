@@ -0,0 +1,304 @@
+// Package dataloader batches and caches the loads of a request so that
+// resolving a field of every item in a list costs one backend call instead
+// of one per item.
+//
+// Loaders are request-scoped: the executor gives every operation a fresh
+// scope, and For returns the loader of a name within it. Resolvers return
+// the thunk of LoadThunk, so that the executor schedules the loads of every
+// item in a list before waiting for any of them:
+//
+//	func (p *Post) Author(ctx context.Context) func() (*User, error) {
+//		return dataloader.For(ctx, "users", store.UsersByIDs).LoadThunk(ctx, p.AuthorID)
+//	}
+//
+// A resolver that blocks in Load or LoadMany instead does not batch with
+// the other items of its list, since the executor resolves them one after
+// another.
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads the values of keys in a single call. It returns the
+// values in the order of keys, and either no errors, a single error that
+// fails the whole batch, or one error per key.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// DefaultWait is how long a loader collects keys before dispatching a
+// batch.
+const DefaultWait = time.Millisecond
+
+// Option configures a Loader.
+type Option func(*options)
+
+type options struct {
+	maxBatch int
+	wait     time.Duration
+	noCache  bool
+}
+
+// WithMaxBatch limits the number of keys passed to the batch function at
+// once; a full batch is dispatched immediately. Zero means no limit.
+func WithMaxBatch(n int) Option {
+	return func(o *options) {
+		o.maxBatch = n
+	}
+}
+
+// WithWait sets how long keys are collected before a batch is dispatched.
+// It defaults to DefaultWait.
+func WithWait(d time.Duration) Option {
+	return func(o *options) {
+		o.wait = d
+	}
+}
+
+// WithoutCache disables caching, so every load of a key is dispatched.
+func WithoutCache() Option {
+	return func(o *options) {
+		o.noCache = true
+	}
+}
+
+// Loader batches the loads of keys made within a short window into calls
+// of its batch function and caches the results by key. It is safe for
+// concurrent use.
+type Loader[K comparable, V any] struct {
+	fn      BatchFunc[K, V]
+	opts    options
+	ctx     context.Context // request context batches run in, if scoped
+	name    string          // name in the request scope, if scoped
+	observe Observer        // observer of the request scope, if any
+
+	mu    sync.Mutex
+	cache map[K]*result[V]
+	batch *batch[K, V] // batch collecting keys, if any
+}
+
+// result is the outcome of loading one key, available once done is closed.
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// batch is a set of keys dispatched together.
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+	full    chan struct{} // closed when the batch reaches the maximum size
+}
+
+// New creates a Loader that loads keys with fn.
+func New[K comparable, V any](fn BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	l := &Loader[K, V]{fn: fn, opts: options{wait: DefaultWait}, cache: make(map[K]*result[V])}
+	for _, opt := range opts {
+		opt(&l.opts)
+	}
+	return l
+}
+
+// Load returns the value of key, waiting for the batch that loads it.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.LoadThunk(ctx, key)()
+}
+
+// LoadMany returns the values of keys in order. errs is nil if every key
+// loaded, and otherwise holds the error of each key.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (values []V, errs []error) {
+	thunks := make([]func() (V, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.LoadThunk(ctx, key)
+	}
+	values = make([]V, len(keys))
+	for i, thunk := range thunks {
+		var err error
+		if values[i], err = thunk(); err != nil {
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[i] = err
+		}
+	}
+	return values, errs
+}
+
+// LoadThunk schedules key to be loaded and returns a function that waits
+// for its value. Scheduling several keys before waiting on any of them lets
+// them share a batch.
+func (l *Loader[K, V]) LoadThunk(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.cache[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		if !l.opts.noCache {
+			l.cache[key] = r
+		}
+		l.schedule(ctx, key, r)
+	}
+	return func() (V, error) {
+		<-r.done
+		return r.value, r.err
+	}
+}
+
+// Prime caches value for key unless the key is already cached.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; !ok && !l.opts.noCache {
+		r := &result[V]{done: make(chan struct{}), value: value}
+		close(r.done)
+		l.cache[key] = r
+	}
+}
+
+// Clear removes key from the cache, so it is loaded again next time.
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+// schedule adds key to the batch collecting keys, starting one if needed.
+// l.mu must be held.
+//
+// Batches run in the context of the request scope rather than that of the
+// load that starts them, which may be cancelled before the batch runs, such
+// as a field timeout ending as soon as the field returns a thunk. Outside
+// of a request scope, they run in the load's context without cancellation.
+func (l *Loader[K, V]) schedule(ctx context.Context, key K, r *result[V]) {
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{full: make(chan struct{})}
+		l.batch = b
+		if l.ctx != nil {
+			ctx = l.ctx
+		} else {
+			ctx = context.WithoutCancel(ctx)
+		}
+		go l.dispatch(ctx, b)
+	}
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if l.opts.maxBatch > 0 && len(b.keys) >= l.opts.maxBatch {
+		l.batch = nil
+		close(b.full)
+	}
+}
+
+// dispatch waits for b to fill or for the wait to elapse, then loads it.
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	timer := time.NewTimer(l.opts.wait)
+	select {
+	case <-timer.C:
+	case <-b.full:
+		timer.Stop()
+	}
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	if l.observe != nil {
+		l.observe(l.name, len(b.keys))
+	}
+	values, errs := l.call(ctx, b.keys)
+	for i, r := range b.results {
+		switch {
+		case len(errs) == 1:
+			r.err = errs[0]
+		case len(errs) == len(b.keys):
+			r.err = errs[i]
+		}
+		if r.err == nil && len(values) != len(b.keys) {
+			r.err = fmt.Errorf("dataloader: batch function returned %d values for %d keys", len(values), len(b.keys))
+		}
+		if r.err == nil {
+			r.value = values[i]
+		} else {
+			// Errors are not cached, so a later load retries the key.
+			l.mu.Lock()
+			if l.cache[b.keys[i]] == r {
+				delete(l.cache, b.keys[i])
+			}
+			l.mu.Unlock()
+		}
+		close(r.done)
+	}
+}
+
+// call runs the batch function, converting a panic into an error for
+// every key.
+func (l *Loader[K, V]) call(ctx context.Context, keys []K) (values []V, errs []error) {
+	defer func() {
+		if p := recover(); p != nil {
+			values, errs = nil, []error{fmt.Errorf("dataloader: batch function panicked: %v", p)}
+		}
+	}()
+	return l.fn(ctx, keys)
+}
+
+// Observer is called with the name of a request-scoped loader and the
+// number of keys of every batch it dispatches, such as to collect
+// statistics.
+type Observer func(loader string, keys int)
+
+type observerKey struct{}
+
+// WithObserver returns a context whose request scopes, created by
+// NewContext, report the batches of their loaders to observe.
+func WithObserver(ctx context.Context, observe Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, observe)
+}
+
+// scope holds the loaders of a request.
+type scope struct {
+	ctx     context.Context // context of the request
+	mu      sync.Mutex
+	loaders map[string]interface{}
+}
+
+type scopeKey struct{}
+
+// NewContext returns a context carrying a fresh request scope for loaders,
+// or ctx itself if it already carries one.
+func NewContext(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return ctx
+	}
+	s := &scope{loaders: make(map[string]interface{})}
+	s.ctx = context.WithValue(ctx, scopeKey{}, s)
+	return s.ctx
+}
+
+// For returns the loader named name in the request scope of ctx, creating
+// it with fn and opts on first use. Outside of a request scope it returns a
+// new loader every time. It panics if the loader named name has different
+// key or value types.
+func For[K comparable, V any](ctx context.Context, name string, fn BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return New(fn, opts...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.loaders[name]; ok {
+		l, ok := existing.(*Loader[K, V])
+		if !ok {
+			panic(fmt.Sprintf("dataloader: loader %q has type %T", name, existing))
+		}
+		return l
+	}
+	l := New(fn, opts...)
+	l.ctx, l.name = s.ctx, name
+	l.observe, _ = s.ctx.Value(observerKey{}).(Observer)
+	s.loaders[name] = l
+	return l
+}
@@ -0,0 +1,170 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a batch function that records the batches it is called with.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) load(ctx context.Context, keys []int) ([]string, []error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]int(nil), keys...))
+	r.mu.Unlock()
+	values := make([]string, len(keys))
+	var errs []error
+	for i, key := range keys {
+		if key < 0 {
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[i] = fmt.Errorf("no user %d", key)
+			continue
+		}
+		values[i] = fmt.Sprintf("user%d", key)
+	}
+	return values, errs
+}
+
+func TestConcurrentLoadsShareABatch(t *testing.T) {
+	rec := &recorder{}
+	l := New(rec.load)
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := l.Load(context.Background(), i); err != nil || v != fmt.Sprintf("user%d", i) {
+				t.Errorf("Load(%d) = %q, %v", i, v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(rec.batches) != 1 {
+		t.Fatalf("expected one batch, got %v", rec.batches)
+	}
+	sort.Ints(rec.batches[0])
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(rec.batches[0], want) {
+		t.Errorf("expected batch %v, got %v", want, rec.batches[0])
+	}
+}
+
+func TestLoadManyBatchesCachesAndSplits(t *testing.T) {
+	rec := &recorder{}
+	l := New(rec.load, WithMaxBatch(2))
+	ctx := context.Background()
+
+	values, errs := l.LoadMany(ctx, []int{1, 2, 3, -1})
+	if want := []string{"user1", "user2", "user3", ""}; !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+	if errs == nil || errs[0] != nil || errs[3] == nil {
+		t.Errorf("expected an error for the last key only, got %v", errs)
+	}
+	// Full batches are dispatched concurrently.
+	sort.Slice(rec.batches, func(i, j int) bool { return rec.batches[i][0] < rec.batches[j][0] })
+	if want := [][]int{{1, 2}, {3, -1}}; !reflect.DeepEqual(rec.batches, want) {
+		t.Errorf("expected batches %v, got %v", want, rec.batches)
+	}
+
+	// Values are cached; errors are not.
+	l.Load(ctx, 2)
+	l.Load(ctx, -1)
+	if want := [][]int{{1, 2}, {3, -1}, {-1}}; !reflect.DeepEqual(rec.batches, want) {
+		t.Errorf("expected batches %v, got %v", want, rec.batches)
+	}
+
+	l.Prime(7, "primed")
+	if v, _ := l.Load(ctx, 7); v != "primed" {
+		t.Errorf("expected primed value, got %q", v)
+	}
+	l.Clear(7)
+	if v, _ := l.Load(ctx, 7); v != "user7" {
+		t.Errorf("expected reloaded value, got %q", v)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	ctx := context.Background()
+	failed := errors.New("backend down")
+	l := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		return nil, []error{failed}
+	})
+	if _, err := l.Load(ctx, 1); !errors.Is(err, failed) {
+		t.Errorf("expected the batch error, got %v", err)
+	}
+
+	short := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		return nil, nil
+	})
+	if _, err := short.Load(ctx, 1); err == nil {
+		t.Error("expected an error for a missing value")
+	}
+
+	panicking := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		panic("boom")
+	})
+	if _, err := panicking.Load(ctx, 1); err == nil {
+		t.Error("expected an error for a panicking batch function")
+	}
+}
+
+func TestForIsRequestScoped(t *testing.T) {
+	rec := &recorder{}
+	ctx := NewContext(context.Background())
+	if NewContext(ctx) != ctx {
+		t.Error("expected NewContext to keep an existing scope")
+	}
+	if For(ctx, "users", rec.load) != For(ctx, "users", rec.load) {
+		t.Error("expected the same loader within a request")
+	}
+	if For(ctx, "users", rec.load) == For(NewContext(context.Background()), "users", rec.load) {
+		t.Error("expected a different loader in another request")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for mismatched loader types")
+		}
+	}()
+	For(ctx, "users", func(ctx context.Context, keys []string) ([]int, []error) { return nil, nil })
+}
+
+func TestBatchesOutliveTheFirstLoad(t *testing.T) {
+	var batchErr error
+	load := func(ctx context.Context, keys []int) ([]int, []error) {
+		batchErr = ctx.Err()
+		return keys, nil
+	}
+	scoped := NewContext(context.Background())
+	for _, l := range []*Loader[int, int]{For(scoped, "users", load), New(load)} {
+		// A field timeout context ends once the field returns its thunk.
+		fieldCtx, cancel := context.WithTimeout(scoped, time.Second)
+		thunk := l.LoadThunk(fieldCtx, 1)
+		cancel()
+		if _, err := thunk(); err != nil || batchErr != nil {
+			t.Errorf("expected the batch to run uncancelled, got %v, %v", err, batchErr)
+		}
+	}
+}
+
+func TestObserverSeesBatches(t *testing.T) {
+	var observed []string
+	ctx := NewContext(WithObserver(context.Background(), func(loader string, keys int) {
+		observed = append(observed, fmt.Sprintf("%s:%d", loader, keys))
+	}))
+	rec := &recorder{}
+	For(ctx, "users", rec.load).LoadMany(ctx, []int{1, 2})
+	if want := []string{"users:2"}; !reflect.DeepEqual(observed, want) {
+		t.Errorf("expected %v, got %v", want, observed)
+	}
+}
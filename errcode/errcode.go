@@ -0,0 +1,185 @@
+// Package errcode defines structured codes for the errors the framework
+// itself raises (parse, validation and limit errors) and a message catalog
+// for presenting them in the client's language.
+//
+// Every coded error renders an English message by default. Handlers
+// localize it for the request's locale and expose the code in the error's
+// extensions, so clients can also translate messages themselves:
+//
+//	errcode.Default.Register("de", map[errcode.Code]string{
+//		errcode.FieldNotFound: "Feld {field} existiert nicht auf Typ {type}",
+//	})
+package errcode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Code identifies a kind of framework error. Codes are stable and safe to
+// match on in clients.
+type Code string
+
+// Parse errors.
+const (
+	SyntaxError Code = "GRAPHQL_SYNTAX_ERROR"
+)
+
+// Validation errors.
+const (
+	OperationNotSupported Code = "OPERATION_NOT_SUPPORTED"
+	FieldNotFound         Code = "FIELD_NOT_FOUND"
+	SelectionRequired     Code = "SELECTION_REQUIRED"
+	SelectionNotAllowed   Code = "SELECTION_NOT_ALLOWED"
+	ArgumentNotFound      Code = "ARGUMENT_NOT_FOUND"
+	ArgumentRequired      Code = "ARGUMENT_REQUIRED"
+	ArgumentNull          Code = "ARGUMENT_NULL"
+	VariableNotDefined    Code = "VARIABLE_NOT_DEFINED"
+	InvalidVariable       Code = "INVALID_VARIABLE"
+	FragmentNotFound      Code = "FRAGMENT_NOT_FOUND"
+	UnknownType           Code = "UNKNOWN_TYPE"
+)
+
+// Request errors.
+const (
+	Unauthenticated      Code = "UNAUTHENTICATED"
+	IdempotencyKeyReused Code = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// Persisted document errors.
+const (
+	PersistedDocumentNotFound Code = "PERSISTED_DOCUMENT_NOT_FOUND"
+	PersistedDocumentRequired Code = "PERSISTED_DOCUMENT_REQUIRED"
+)
+
+// Limit errors.
+const (
+	CostLimitExceeded    Code = "COST_LIMIT_EXCEEDED"
+	DepthLimitExceeded   Code = "DEPTH_LIMIT_EXCEEDED"
+	NodeLimitExceeded    Code = "NODE_LIMIT_EXCEEDED"
+	ResponseSizeExceeded Code = "RESPONSE_SIZE_EXCEEDED"
+)
+
+// english holds the default message for every code. Placeholders in braces
+// are replaced by the error's parameters.
+var english = map[Code]string{
+	SyntaxError:               "Syntax error: {detail}",
+	OperationNotSupported:     "schema is not configured for {operation} operations",
+	FieldNotFound:             `cannot query field "{field}" on type "{type}"`,
+	SelectionRequired:         `field "{field}" of type "{type}" must have a selection of subfields`,
+	SelectionNotAllowed:       `field "{field}" must not have a selection since type "{type}" has no subfields`,
+	ArgumentNotFound:          `unknown argument "{argument}" on field "{type}.{field}"`,
+	ArgumentRequired:          `field "{field}" argument "{argument}" of type "{type}" is required but not provided`,
+	ArgumentNull:              `field "{field}" argument "{argument}" of type "{type}" must not be null`,
+	VariableNotDefined:        `variable "${variable}" is not defined`,
+	InvalidVariable:           "invalid value at {path}: {detail}",
+	FragmentNotFound:          `unknown fragment "{fragment}"`,
+	UnknownType:               `unknown type "{type}"`,
+	Unauthenticated:           "not authenticated: {detail}",
+	IdempotencyKeyReused:      "idempotency key was used for a different request",
+	PersistedDocumentNotFound: `persisted document "{id}" not found`,
+	PersistedDocumentRequired: "only persisted documents may be executed",
+	CostLimitExceeded:         "operation cost {cost} exceeds the limit of {limit}",
+	DepthLimitExceeded:        "operation depth {depth} exceeds the limit of {limit}",
+	NodeLimitExceeded:         "response exceeds the limit of {limit} nodes and was truncated",
+	ResponseSizeExceeded:      "response exceeds the limit of {limit} bytes and was truncated",
+}
+
+// Error is a framework error with a code and the parameters of its message.
+type Error struct {
+	Code   Code
+	Params map[string]interface{}
+}
+
+// New creates an Error with params given as alternating names and values.
+func New(code Code, params ...interface{}) *Error {
+	e := &Error{Code: code, Params: make(map[string]interface{}, len(params)/2)}
+	for i := 0; i+1 < len(params); i += 2 {
+		e.Params[fmt.Sprint(params[i])] = params[i+1]
+	}
+	return e
+}
+
+// Error returns the English message.
+func (e *Error) Error() string {
+	return e.Localize(Default, "")
+}
+
+// Localize renders the message for locale from catalog, falling back to
+// the English message.
+func (e *Error) Localize(catalog *Catalog, locale string) string {
+	template, ok := catalog.lookup(locale, e.Code)
+	if !ok {
+		template, ok = english[e.Code]
+	}
+	if !ok {
+		template = string(e.Code)
+	}
+	return expand(template, e.Params)
+}
+
+// expand replaces {name} placeholders in template with params.
+func expand(template string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return template
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(params[name]))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Catalog holds translated message templates by locale. It is safe for
+// concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[Code]string
+}
+
+// Default is the catalog used by the HTTP handlers.
+var Default = NewCatalog()
+
+// NewCatalog creates an empty catalog; untranslated codes use English.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[Code]string)}
+}
+
+// Register adds message templates for a locale such as "de" or "pt-BR".
+func (c *Catalog) Register(locale string, messages map[Code]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	locale = strings.ToLower(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[Code]string)
+	}
+	for code, msg := range messages {
+		c.messages[locale][code] = msg
+	}
+}
+
+// lookup finds the template for code in locale, trying the base language
+// ("pt" for "pt-BR") when the region has no translation.
+func (c *Catalog) lookup(locale string, code Code) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locale = strings.ToLower(locale)
+	if msg, ok := c.messages[locale][code]; ok {
+		return msg, true
+	}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		msg, ok := c.messages[base][code]
+		return msg, ok
+	}
+	return "", false
+}
@@ -0,0 +1,26 @@
+package errcode
+
+import "testing"
+
+func TestLocalize(t *testing.T) {
+	c := NewCatalog()
+	c.Register("de", map[Code]string{FieldNotFound: "Feld {field} existiert nicht auf Typ {type}"})
+	err := New(FieldNotFound, "field", "email", "type", "User")
+
+	tests := []struct {
+		locale, want string
+	}{
+		{"", `cannot query field "email" on type "User"`},
+		{"de", "Feld email existiert nicht auf Typ User"},
+		{"de-AT", "Feld email existiert nicht auf Typ User"},
+		{"fr", `cannot query field "email" on type "User"`},
+	}
+	for _, tt := range tests {
+		if got := err.Localize(c, tt.locale); got != tt.want {
+			t.Errorf("locale %q: expected %q, got %q", tt.locale, tt.want, got)
+		}
+	}
+	if err.Error() != tests[0].want {
+		t.Errorf("expected English Error(), got %q", err.Error())
+	}
+}
@@ -0,0 +1,53 @@
+//go:build js && wasm
+
+// Command wasm exposes the GraphQL executor to JavaScript when compiled with
+// GOOS=js GOARCH=wasm. It registers a global graphqlExecute(query, variables)
+// function that returns the JSON-encoded result.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	graphql "github.com/Protocol-Lattice/graphql"
+)
+
+func main() {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("hello", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "Hello from WebAssembly!", nil
+	})
+
+	js.Global().Set("graphqlExecute", js.FuncOf(func(this js.Value, argv []js.Value) interface{} {
+		if len(argv) == 0 {
+			return errorJSON("missing query argument")
+		}
+		variables := map[string]interface{}{}
+		if len(argv) > 1 && argv[1].Type() == js.TypeString {
+			if err := json.Unmarshal([]byte(argv[1].String()), &variables); err != nil {
+				return errorJSON("invalid variables JSON: " + err.Error())
+			}
+		}
+		doc := graphql.NewParser(graphql.NewLexer(argv[0].String())).ParseDocument()
+		result, err := exec.Execute(doc, variables)
+		if err != nil {
+			return errorJSON(err.Error())
+		}
+		out, err := json.Marshal(result)
+		if err != nil {
+			return errorJSON(err.Error())
+		}
+		return string(out)
+	}))
+
+	// Keep the Go runtime alive so JavaScript can keep calling in.
+	select {}
+}
+
+// errorJSON renders a single GraphQL-style error as a JSON string.
+func errorJSON(msg string) string {
+	out, _ := json.Marshal(map[string]interface{}{
+		"errors": []map[string]interface{}{{"message": msg}},
+	})
+	return string(out)
+}
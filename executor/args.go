@@ -0,0 +1,31 @@
+package executor
+
+// Args gives access to the arguments of a field as passed to resolvers,
+// telling arguments that were left out, or set to a variable that was not
+// provided, from arguments explicitly set to null:
+//
+//	func(source interface{}, args map[string]interface{}) (interface{}, error) {
+//		if executor.Args(args).Has("nickname") {
+//			user.Nickname, _ = args["nickname"].(string) // null clears it
+//		}
+//		...
+//	}
+type Args map[string]interface{}
+
+// Has reports whether the argument name was given, even if as null.
+func (a Args) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// IsNull reports whether the argument name was explicitly set to null.
+func (a Args) IsNull(name string) bool {
+	value, ok := a[name]
+	return ok && value == nil
+}
+
+// Get returns the value of the argument name and whether it was given.
+func (a Args) Get(name string) (interface{}, bool) {
+	value, ok := a[name]
+	return value, ok
+}
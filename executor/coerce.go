@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// coercion converts input values to the Go types resolvers expect. Its zero
+// value only converts built-in scalars.
+type coercion struct {
+	inputs map[string]*ast.InputObjectTypeDefinition // input object types by name
+	enums  map[string]*enumType                      // registered enums by name
+	scalar func(name string) (Scalar, bool)          // custom scalars, if any
+}
+
+// value converts v, as decoded from JSON or built from a literal, to the Go
+// type resolvers expect for an input of type t: int for Int, float64 for
+// Float, string for ID, the registered Go value for enums and the parsed
+// value for custom scalars, recursing into lists and input objects. Scalars
+// that cannot be converted without losing information, such as 1.5 for an
+// Int, are returned as is. Invalid enum and custom scalar values are an
+// error, as are input objects with fields their type does not declare or
+// without a required field; omitted fields with a default get the default.
+// Errors are *inputError values locating the invalid value.
+func (c coercion) value(t *ast.Type, v interface{}) (interface{}, error) {
+	if t == nil || v == nil {
+		return v, nil
+	}
+	if t.IsList {
+		list, ok := v.([]interface{})
+		if !ok {
+			// A single value is accepted where a list is expected.
+			item, err := c.value(t.Elem, v)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		out := make([]interface{}, len(list))
+		for i, item := range list {
+			var err error
+			if out[i], err = c.value(t.Elem, item); err != nil {
+				return nil, atPath(strconv.Itoa(i), err)
+			}
+		}
+		return out, nil
+	}
+	if enum, ok := c.enums[t.Name]; ok {
+		return enum.parse(v)
+	}
+	if c.scalar != nil {
+		if scalar, ok := c.scalar(t.Name); ok && scalar.ParseValue != nil {
+			return scalar.ParseValue(v)
+		}
+	}
+	switch t.Name {
+	case "Int":
+		switch n := v.(type) {
+		case float64:
+			if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), nil
+			}
+		case json.Number:
+			if i, err := strconv.Atoi(n.String()); err == nil {
+				return i, nil
+			}
+		}
+	case "Float":
+		switch n := v.(type) {
+		case int:
+			return float64(n), nil
+		case json.Number:
+			if f, err := n.Float64(); err == nil {
+				return f, nil
+			}
+		}
+	case "ID":
+		switch n := v.(type) {
+		case int:
+			return strconv.Itoa(n), nil
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+				return strconv.FormatInt(int64(n), 10), nil
+			}
+		case json.Number:
+			return n.String(), nil
+		}
+	default:
+		if def, ok := c.inputs[t.Name]; ok {
+			return c.inputObject(def, v)
+		}
+	}
+	return v, nil
+}
+
+// inputObject coerces v to the input object type def.
+func (c coercion) inputObject(def *ast.InputObjectTypeDefinition, v interface{}) (interface{}, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, &inputError{err: fmt.Errorf("%v is not an object of input type %s", v, def.Name)}
+	}
+	declared := make(map[string]bool, len(def.Fields))
+	out := make(map[string]interface{}, len(def.Fields))
+	for _, field := range def.Fields {
+		declared[field.Name] = true
+		value, ok := obj[field.Name]
+		if !ok && field.DefaultValue != nil {
+			value, ok = buildValue(field.DefaultValue, nil), true
+		}
+		if value == nil && field.Type.NonNull {
+			if ok {
+				return nil, atPath(field.Name, fmt.Errorf("null for non-null type %s", field.Type))
+			}
+			return nil, atPath(field.Name, fmt.Errorf("required field of type %s not provided", field.Type))
+		}
+		if !ok {
+			continue
+		}
+		coerced, err := c.value(field.Type, value)
+		if err != nil {
+			return nil, atPath(field.Name, err)
+		}
+		out[field.Name] = coerced
+	}
+	for name := range obj {
+		if !declared[name] {
+			return nil, atPath(name, fmt.Errorf("field is not defined by input type %s", def.Name))
+		}
+	}
+	return out, nil
+}
+
+// inputError is an invalid input value, located by the input object field
+// names and list indices leading to it from an argument or variable.
+type inputError struct {
+	path []string
+	err  error
+}
+
+func (e *inputError) Error() string {
+	if len(e.path) == 0 {
+		return e.err.Error()
+	}
+	return strings.Join(e.path, ".") + ": " + e.err.Error()
+}
+
+func (e *inputError) Unwrap() error {
+	return e.err
+}
+
+// atPath prefixes the location of err with segment.
+func atPath(segment string, err error) error {
+	ie, ok := err.(*inputError)
+	if !ok {
+		return &inputError{path: []string{segment}, err: err}
+	}
+	return &inputError{path: append([]string{segment}, ie.path...), err: ie.err}
+}
+
+// coerceArgs coerces the arguments of field, selected on the named type, to
+// the argument types declared by the schema. It does nothing if no schema is
+// set or the field is not defined by it.
+func (e *Executor) coerceArgs(typeName string, field *ast.Field, args map[string]interface{}) error {
+	def := e.fields[typeName][field.Name]
+	if def == nil {
+		return nil
+	}
+	c := coercion{inputs: e.inputTypes, enums: e.enums, scalar: e.scalar}
+	for _, arg := range def.ArgumentDefinitions {
+		value, ok := args[arg.Name]
+		if !ok {
+			continue
+		}
+		coerced, err := c.value(arg.Type, value)
+		if err != nil {
+			return fmt.Errorf("argument %w", atPath(arg.Name, err))
+		}
+		args[arg.Name] = coerced
+	}
+	return nil
+}
+
+// checkVariables coerces the variables op declares, as returned by
+// VariableValues, to their types in the schema and reports the first
+// invalid one as an errcode.InvalidVariable error located like
+// "variables.input.address.zip". Variables of non-null types must be
+// provided, or have a default, and must not be null.
+func (e *Executor) checkVariables(op *ast.OperationDefinition, variables map[string]interface{}) error {
+	c := coercion{inputs: e.inputTypes, enums: e.enums, scalar: e.scalar}
+	for _, def := range op.VariableDefinitions {
+		value, provided := variables[def.Variable]
+		if def.Type.NonNull && value == nil {
+			err := fmt.Errorf("null for non-null type %s", def.Type.String())
+			if !provided {
+				err = fmt.Errorf("variable of required type %s was not provided", def.Type.String())
+			}
+			return errcode.New(errcode.InvalidVariable, "path", "variables."+def.Variable, "detail", err)
+		}
+		if _, err := c.value(&def.Type, value); err != nil {
+			ie := atPath("variables", atPath(def.Variable, err)).(*inputError)
+			return errcode.New(errcode.InvalidVariable, "path", strings.Join(ie.path, "."), "detail", ie.err)
+		}
+	}
+	return nil
+}
+
+// inputTypes indexes the input object types of schema by name.
+func inputTypes(schema *ast.Document) map[string]*ast.InputObjectTypeDefinition {
+	index := make(map[string]*ast.InputObjectTypeDefinition)
+	for _, def := range schema.Definitions {
+		if input, ok := def.(*ast.InputObjectTypeDefinition); ok {
+			index[input.Name] = input
+		}
+	}
+	return index
+}
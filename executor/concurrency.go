@@ -0,0 +1,91 @@
+package executor
+
+import "sync/atomic"
+
+// DefaultMaxGoroutines is the default number of resolver goroutines an
+// operation may have running at once.
+const DefaultMaxGoroutines = 1000
+
+// DefaultWorkerPool is the default number of resolver goroutines all
+// operations of an executor may have running at once.
+const DefaultWorkerPool = 10000
+
+// SetMaxGoroutines limits the goroutines each operation may have running
+// at once to call resolvers, such as those bounded by SetTimeout and
+// SetFieldTimeout. Resolvers beyond the budget are called on the executing
+// goroutine, so a timeout cannot cut them short, and counted as saturation
+// in PoolStats. It defaults to DefaultMaxGoroutines; zero means no limit.
+func (e *Executor) SetMaxGoroutines(n int) {
+	e.maxGoroutines = n
+}
+
+// SetWorkerPool limits the resolver goroutines all operations of the
+// executor may have running at once, like SetMaxGoroutines does for each
+// operation. It defaults to DefaultWorkerPool; zero means no limit. It
+// must not be called while operations are executing.
+func (e *Executor) SetWorkerPool(n int) {
+	e.workers.sem = nil
+	if n > 0 {
+		e.workers.sem = make(chan struct{}, n)
+	}
+}
+
+// PoolStats describes the resolver goroutines of an executor.
+type PoolStats struct {
+	Running   int64 // Goroutines running now
+	Peak      int64 // Most goroutines running at once
+	Saturated int64 // Resolver calls made without a goroutine because a limit was reached
+}
+
+// PoolStats returns the current statistics of the executor's resolver
+// goroutines.
+func (e *Executor) PoolStats() PoolStats {
+	return PoolStats{
+		Running:   e.workers.running.Load(),
+		Peak:      e.workers.peak.Load(),
+		Saturated: e.workers.saturated.Load(),
+	}
+}
+
+// workerPool bounds and counts the resolver goroutines of an executor.
+type workerPool struct {
+	sem       chan struct{} // one slot per running goroutine; nil for no limit
+	running   atomic.Int64
+	peak      atomic.Int64
+	saturated atomic.Int64
+}
+
+// startWorker reserves a goroutine for a resolver call of ex. It returns
+// false if the operation's budget or the worker pool is exhausted;
+// otherwise the goroutine must call release when it is done.
+func (e *Executor) startWorker(ex *execution) (release func(), ok bool) {
+	pool := e.workers
+	if e.maxGoroutines > 0 && ex.goroutines.Load() >= int64(e.maxGoroutines) {
+		pool.saturated.Add(1)
+		return nil, false
+	}
+	sem := pool.sem
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		default:
+			pool.saturated.Add(1)
+			return nil, false
+		}
+	}
+	ex.goroutines.Add(1)
+	running := pool.running.Add(1)
+	for {
+		peak := pool.peak.Load()
+		if running <= peak || pool.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	return func() {
+		ex.goroutines.Add(-1)
+		pool.running.Add(-1)
+		if sem != nil {
+			<-sem
+		}
+	}, true
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Debug collects per-request execution statistics that are reported in the
+// extensions.debug block of a response. It is safe for concurrent use.
+type Debug struct {
+	mu          sync.Mutex
+	parse       time.Duration
+	validate    time.Duration
+	execute     time.Duration
+	fields      []fieldTiming
+	cacheHits   int
+	cacheMisses int
+	loaders     map[string]*loaderStats
+}
+
+// fieldTiming is the duration of a single field resolution.
+type fieldTiming struct {
+	path     []interface{}
+	duration time.Duration
+}
+
+// loaderStats aggregates the batches dispatched by one data loader.
+type loaderStats struct {
+	batches int
+	keys    int
+}
+
+// debugKey is the context key for the request's *Debug.
+type debugKey struct{}
+
+// WithDebug returns a context that collects execution statistics into d.
+func WithDebug(ctx context.Context, d *Debug) context.Context {
+	return context.WithValue(ctx, debugKey{}, d)
+}
+
+// DebugFromContext returns the collector attached to ctx, or nil if
+// debugging is not enabled for the request. All recording methods are
+// no-ops on a nil *Debug.
+func DebugFromContext(ctx context.Context) *Debug {
+	d, _ := ctx.Value(debugKey{}).(*Debug)
+	return d
+}
+
+// RecordParse records the time spent parsing the document.
+func (d *Debug) RecordParse(dur time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.parse += dur
+	d.mu.Unlock()
+}
+
+// RecordValidate records the time spent validating the document.
+func (d *Debug) RecordValidate(dur time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.validate += dur
+	d.mu.Unlock()
+}
+
+// recordExecute records the time spent executing the operation.
+func (d *Debug) recordExecute(dur time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.execute += dur
+	d.mu.Unlock()
+}
+
+// recordField records the resolution time of the field at path.
+func (d *Debug) recordField(path []interface{}, dur time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.fields = append(d.fields, fieldTiming{path: path, duration: dur})
+	d.mu.Unlock()
+}
+
+// RecordCacheHit records a lookup served from a request or resolver cache.
+func (d *Debug) RecordCacheHit() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.cacheHits++
+	d.mu.Unlock()
+}
+
+// RecordCacheMiss records a cache lookup that fell through to a resolver.
+func (d *Debug) RecordCacheMiss() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.cacheMisses++
+	d.mu.Unlock()
+}
+
+// RecordBatch records a batch of keys dispatched by the named data loader.
+func (d *Debug) RecordBatch(loader string, keys int) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if d.loaders == nil {
+		d.loaders = make(map[string]*loaderStats)
+	}
+	stats, ok := d.loaders[loader]
+	if !ok {
+		stats = &loaderStats{}
+		d.loaders[loader] = stats
+	}
+	stats.batches++
+	stats.keys += keys
+	d.mu.Unlock()
+}
+
+// Extension returns the collected statistics in the form reported under
+// extensions.debug. Durations are in nanoseconds.
+func (d *Debug) Extension() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ext := map[string]interface{}{
+		"parse":   map[string]interface{}{"durationNs": d.parse.Nanoseconds()},
+		"execute": map[string]interface{}{"durationNs": d.execute.Nanoseconds()},
+		"cache":   map[string]interface{}{"hits": d.cacheHits, "misses": d.cacheMisses},
+	}
+	if d.validate > 0 {
+		ext["validate"] = map[string]interface{}{"durationNs": d.validate.Nanoseconds()}
+	}
+
+	resolvers := make([]interface{}, len(d.fields))
+	for i, f := range d.fields {
+		resolvers[i] = map[string]interface{}{"path": f.path, "durationNs": f.duration.Nanoseconds()}
+	}
+	ext["resolvers"] = resolvers
+
+	names := make([]string, 0, len(d.loaders))
+	for name := range d.loaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	loaders := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		loaders[name] = map[string]interface{}{"batches": d.loaders[name].batches, "keys": d.loaders[name].keys}
+	}
+	ext["dataloaders"] = loaders
+	return ext
+}
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// DeprecatedFieldUsage describes the selection of a field that the schema
+// marks @deprecated.
+type DeprecatedFieldUsage struct {
+	Type   string // Name of the type declaring the field
+	Field  string // Field name
+	Reason string // Deprecation reason
+}
+
+// DeprecationHandler is called before an operation executes, once for each
+// deprecated field it selects. It can be used to log or report usage of
+// fields that are due to be removed.
+type DeprecationHandler func(ctx context.Context, usage DeprecatedFieldUsage)
+
+// SetDeprecationHandler installs a handler for deprecated field usage.
+// Deprecations are read from the schema set with SetSchema, so the handler
+// is only called when a schema is set. A nil handler disables reporting.
+func (e *Executor) SetDeprecationHandler(handler DeprecationHandler) {
+	e.deprecationHandler = handler
+}
+
+// reportDeprecations calls the deprecation handler for every deprecated
+// field op selects, skipping selections excluded by @skip or @include.
+// Fields are reported once per operation even if they are selected in
+// several places.
+func (e *Executor) reportDeprecations(ctx context.Context, op *ast.OperationDefinition, ex *execution) {
+	if e.deprecationHandler == nil || e.schema == nil {
+		return
+	}
+	fields := e.fields
+	seen := make(map[DeprecatedFieldUsage]bool)
+	var walk func(typeName string, ss *ast.SelectionSet, visiting map[string]bool)
+	walk = func(typeName string, ss *ast.SelectionSet, visiting map[string]bool) {
+		if ss == nil {
+			return
+		}
+		for _, sel := range ss.Selections {
+			switch sel := sel.(type) {
+			case *ast.Field:
+				def := fields[typeName][sel.Name]
+				if def == nil || !ex.included(sel.Directives) {
+					continue
+				}
+				if usage := (DeprecatedFieldUsage{Type: typeName, Field: sel.Name, Reason: def.DeprecationReason}); def.Deprecated && !seen[usage] {
+					seen[usage] = true
+					e.deprecationHandler(ctx, usage)
+				}
+				if def.Type != nil {
+					walk(def.Type.NamedType(), sel.SelectionSet, visiting)
+				}
+			case *ast.FragmentSpread:
+				frag, ok := ex.fragments[sel.Name]
+				if !ok || visiting[sel.Name] || !ex.included(sel.Directives) {
+					continue
+				}
+				visiting[sel.Name] = true
+				walk(frag.TypeCondition, frag.SelectionSet, visiting)
+				delete(visiting, sel.Name)
+			case *ast.InlineFragment:
+				if !ex.included(sel.Directives) {
+					continue
+				}
+				if sel.TypeCondition != "" {
+					walk(sel.TypeCondition, sel.SelectionSet, visiting)
+				} else {
+					walk(typeName, sel.SelectionSet, visiting)
+				}
+			}
+		}
+	}
+	walk(e.schema.RootTypeName(op.Operation), op.SelectionSet, make(map[string]bool))
+}
+
+// schemaFields indexes the field definitions of the object and interface
+// types in schema by type and field name.
+func schemaFields(schema *ast.Document) map[string]map[string]*ast.Field {
+	index := make(map[string]map[string]*ast.Field)
+	add := func(typeName string, fields []*ast.Field) {
+		if index[typeName] == nil {
+			index[typeName] = make(map[string]*ast.Field)
+		}
+		for _, f := range fields {
+			index[typeName][f.Name] = f
+		}
+	}
+	for _, def := range schema.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			add(def.Name, def.Fields)
+		case *ast.InterfaceTypeDefinition:
+			add(def.Name, def.Fields)
+		case *ast.TypeExtension:
+			switch ext := def.Definition.(type) {
+			case *ast.TypeDefinition:
+				add(ext.Name, ext.Fields)
+			case *ast.InterfaceTypeDefinition:
+				add(ext.Name, ext.Fields)
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// SetMaxDepth limits how deeply the fields of an operation may nest, root
+// fields being at depth 1. Deeper operations are rejected with an
+// errcode.DepthLimitExceeded error before any field is resolved. Zero, the
+// default, means no limit.
+func (e *Executor) SetMaxDepth(n int) {
+	e.maxDepth = n
+}
+
+// CheckDepth returns an *errcode.Error if any operation in doc nests fields
+// deeper than limit. Fragment spreads count the depth of the fragment's
+// selections, each fragment being measured once, and fields nested past
+// limit are not walked.
+func CheckDepth(doc *ast.Document, limit int) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if err := checkDepth(op, fragments, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDepth returns an *errcode.Error if op nests fields deeper than limit.
+func checkDepth(op *ast.OperationDefinition, fragments map[string]*ast.FragmentDefinition, limit int) error {
+	c := &depthCheck{
+		fragments: fragments,
+		limit:     limit,
+		visiting:  make(map[string]bool),
+		depths:    make(map[string]int),
+	}
+	if depth := c.selectionDepth(op.SelectionSet, 0); depth > limit {
+		return errcode.New(errcode.DepthLimitExceeded, "depth", depth, "limit", limit)
+	}
+	return nil
+}
+
+// depthCheck measures how deeply the fields of an operation nest.
+type depthCheck struct {
+	fragments map[string]*ast.FragmentDefinition
+	limit     int
+	visiting  map[string]bool // fragments being measured, to break cycles
+	depths    map[string]int  // depths of the fragments measured so far
+}
+
+// selectionDepth returns the deepest nesting of fields in ss, whose fields
+// are at depth level+1. Fields past the limit are not descended into, so
+// an operation exceeding it is reported at depth limit+1.
+func (c *depthCheck) selectionDepth(ss *ast.SelectionSet, level int) int {
+	if ss == nil {
+		return 0
+	}
+	depth := 0
+	for _, sel := range ss.Selections {
+		d := 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			d = 1
+			if level+1 <= c.limit {
+				d += c.selectionDepth(sel.SelectionSet, level+1)
+			}
+		case *ast.FragmentSpread:
+			d = c.fragmentDepth(sel.Name, level)
+		case *ast.InlineFragment:
+			d = c.selectionDepth(sel.SelectionSet, level)
+		}
+		depth = max(depth, d)
+		if level+depth > c.limit {
+			break
+		}
+	}
+	return depth
+}
+
+// fragmentDepth returns the depth of the selections of the fragment named
+// name, spread at depth level. It is measured once however often the
+// fragment is spread; a fragment spread within itself adds no depth.
+func (c *depthCheck) fragmentDepth(name string, level int) int {
+	if depth, ok := c.depths[name]; ok {
+		return depth
+	}
+	frag, ok := c.fragments[name]
+	if !ok || c.visiting[name] {
+		return 0
+	}
+	c.visiting[name] = true
+	depth := c.selectionDepth(frag.SelectionSet, level)
+	delete(c.visiting, name)
+	c.depths[name] = depth
+	return depth
+}
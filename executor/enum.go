@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// enumType maps the values of a registered enum between their GraphQL names
+// and Go values.
+type enumType struct {
+	name   string
+	values map[string]interface{} // name -> Go value
+	names  map[interface{}]string // Go value -> name
+}
+
+// RegisterEnum maps the values of the enum type name to Go values, e.g.
+// map[string]interface{}{"ADMIN": RoleAdmin}. Enum arguments, including
+// those in lists and input objects, are passed to resolvers as the mapped
+// Go values, and mapped Go values returned by resolvers are serialized as
+// their names; values outside the mapping are field errors. The Go values
+// must be comparable. Mapping requires a schema set with SetSchema that
+// declares the argument and field types.
+func (e *Executor) RegisterEnum(name string, values map[string]interface{}) {
+	enum := &enumType{
+		name:   name,
+		values: make(map[string]interface{}, len(values)),
+		names:  make(map[interface{}]string, len(values)),
+	}
+	for valueName, value := range values {
+		enum.values[valueName] = value
+		enum.names[value] = valueName
+	}
+	if e.enums == nil {
+		e.enums = make(map[string]*enumType)
+	}
+	e.enums[name] = enum
+}
+
+// parse converts an input enum name to its Go value.
+func (t *enumType) parse(v interface{}) (interface{}, error) {
+	if name, ok := v.(string); ok {
+		if value, ok := t.values[name]; ok {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("%v is not a value of enum %s", v, t.name)
+}
+
+// serialize converts a Go value returned by a resolver to its enum name.
+// Names of the enum are accepted as they are.
+func (t *enumType) serialize(v interface{}) (string, error) {
+	if reflect.TypeOf(v).Comparable() {
+		if name, ok := t.names[v]; ok {
+			return name, nil
+		}
+	}
+	if name, ok := v.(string); ok {
+		if _, ok := t.values[name]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("enum %s cannot represent value %v", t.name, v)
+}
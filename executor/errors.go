@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/gqlerrors"
+)
+
+// Error is a field error raised while executing an operation. Its Err is
+// the underlying resolver error, if any.
+type Error = gqlerrors.Error
+
+// Location is a position in the query document.
+type Location = gqlerrors.Location
+
+// SetDevMode enables or disables development mode. In development mode,
+// errors raised by resolvers (including recovered panics) carry the stack
+// trace and the dotted field path in their extensions; otherwise both are
+// omitted so internals are not leaked to clients.
+func (e *Executor) SetDevMode(enabled bool) {
+	e.devMode = enabled
+}
+
+// DevMode reports whether development mode is enabled.
+func (e *Executor) DevMode() bool {
+	return e.devMode
+}
+
+// ErrorPresenter converts an error into the error a client sees. Use it to
+// mask internal details, such as SQL text, while logging the original.
+// Path and locations are kept from err if the presented error has none.
+type ErrorPresenter func(ctx context.Context, err error) *Error
+
+// SetErrorPresenter installs the presenter applied to the errors of every
+// response. A nil presenter, the default, presents errors as they are.
+func (e *Executor) SetErrorPresenter(presenter ErrorPresenter) {
+	e.errorPresenter = presenter
+}
+
+// presenterKey is the context key for a presenter set with
+// WithErrorPresenter.
+type presenterKey struct{}
+
+// WithErrorPresenter returns a context whose responses are presented by
+// presenter instead of the executor's presenter, such as for a single HTTP
+// handler.
+func WithErrorPresenter(ctx context.Context, presenter ErrorPresenter) context.Context {
+	return context.WithValue(ctx, presenterKey{}, presenter)
+}
+
+// PresentError converts err as the error presenter does, for errors raised
+// outside of execution, such as by HTTP handlers.
+func (e *Executor) PresentError(ctx context.Context, err error) *Error {
+	gqlErr := gqlerrors.Wrap(err)
+	presenter := e.errorPresenter
+	if p, ok := ctx.Value(presenterKey{}).(ErrorPresenter); ok && p != nil {
+		presenter = p
+	}
+	if presenter == nil {
+		return gqlErr
+	}
+	presented := presenter(ctx, gqlErr)
+	if presented == nil {
+		return gqlErr
+	}
+	if presented.Path == nil && presented.Locations == nil {
+		withPos := *presented
+		withPos.Path, withPos.Locations = gqlErr.Path, gqlErr.Locations
+		return &withPos
+	}
+	return presented
+}
+
+// resolve calls fn for the field at path, converting panics into errors and
+// wrapping failures in an *Error that carries the path. An *Error returned
+// by fn is kept as it is, except that a copy gets the path if it has none.
+func (e *Executor) resolve(path []interface{}, fn func() (interface{}, error)) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			e.Logger().Error("resolver panicked", "path", formatPath(path), "panic", r, "stack", string(stack))
+			res, err = nil, e.newError(path, fmt.Errorf("panic: %v", r), stack)
+		}
+	}()
+	res, err = fn()
+	if err != nil {
+		var gqlErr *Error
+		if errors.As(err, &gqlErr) {
+			if gqlErr.Path != nil {
+				return nil, err
+			}
+			located := *gqlErr
+			located.Path = append([]interface{}(nil), path...)
+			return nil, &located
+		}
+		var stack []byte
+		if e.devMode {
+			// Capturing the stack is costly, and only development mode
+			// reports it.
+			stack = debug.Stack()
+		}
+		return nil, e.newError(path, err, stack)
+	}
+	return res, nil
+}
+
+// newError builds an *Error for err at path, attaching the stack trace in
+// development mode.
+func (e *Executor) newError(path []interface{}, err error, stack []byte) *Error {
+	gqlErr := &Error{
+		Message: err.Error(),
+		Path:    append([]interface{}(nil), path...),
+		Err:     err,
+	}
+	if e.devMode {
+		gqlErr.Extensions = map[string]interface{}{
+			"field":      formatPath(path),
+			"stacktrace": stackLines(stack),
+		}
+	}
+	return gqlErr
+}
+
+// formatPath renders a response path as "user.friends[0].name".
+func formatPath(path []interface{}) string {
+	var sb strings.Builder
+	for _, seg := range path {
+		switch s := seg.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", s)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			fmt.Fprint(&sb, s)
+		}
+	}
+	return sb.String()
+}
+
+// stackLines splits a stack trace into trimmed, non-empty lines.
+func stackLines(stack []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(stack), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
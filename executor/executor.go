@@ -1,71 +1,227 @@
 package executor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/dataloader"
+	"github.com/Protocol-Lattice/graphql/gqlerrors"
 )
 
-// ResolverFunc defines the function signature for all resolvers.
+// ResolverFunc defines the function signature for resolvers that do not
+// need the request context.
 type ResolverFunc func(source interface{}, args map[string]interface{}) (interface{}, error)
 
+// ContextResolverFunc defines the function signature for resolvers that
+// honor cancellation and deadlines or read request-scoped values, such as
+// the authenticated user, from ctx.
+type ContextResolverFunc func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error)
+
+// WithContext adapts fn to a ContextResolverFunc that ignores its context.
+func (fn ResolverFunc) WithContext() ContextResolverFunc {
+	return func(_ context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return fn(source, args)
+	}
+}
+
 // Executor executes GraphQL queries against registered resolvers.
 type Executor struct {
-	queryResolvers        map[string]ResolverFunc
-	mutationResolvers     map[string]ResolverFunc
-	subscriptionResolvers map[string]ResolverFunc
+	queryResolvers        map[string]ContextResolverFunc
+	mutationResolvers     map[string]ContextResolverFunc
+	subscriptionResolvers map[string]ContextResolverFunc
+	devMode               bool
+	fieldNaming           FieldNaming
+	fieldNameMapper       FieldNameMapper
+	missingFieldPolicy    MissingFieldPolicy
+	filterRawJSON         bool
+	schema                *ast.Document
+	deprecationHandler    DeprecationHandler
+	possibleTypes         map[string]map[string]bool       // abstract type -> object types
+	fields                map[string]map[string]*ast.Field // type -> field -> definition
+	inputTypes            map[string]*ast.InputObjectTypeDefinition
+	enums                 map[string]*enumType
+	scalars               map[string]Scalar
+	declaredScalars       map[string]bool // known scalars declared by the schema
+	typeResolvers         map[string]TypeResolver
+	fieldIndex            sync.Map // reflect.Type -> map[string]int, see structFields
+	timeout               time.Duration
+	fieldTimeout          time.Duration
+	maxDepth              int
+	memoize               bool
+	errorPresenter        ErrorPresenter
+	tracer                Tracer
+	logger                *slog.Logger
+	orderedFields         bool
+	maxNodes              int
+	maxResponseSize       int
+	validate              bool
+	maxGoroutines         int
+	workers               *workerPool
 }
 
 // New creates a new Executor instance.
 func New() *Executor {
 	return &Executor{
-		queryResolvers:        make(map[string]ResolverFunc),
-		mutationResolvers:     make(map[string]ResolverFunc),
-		subscriptionResolvers: make(map[string]ResolverFunc),
+		queryResolvers:        make(map[string]ContextResolverFunc),
+		mutationResolvers:     make(map[string]ContextResolverFunc),
+		subscriptionResolvers: make(map[string]ContextResolverFunc),
+		maxGoroutines:         DefaultMaxGoroutines,
+		workers:               &workerPool{sem: make(chan struct{}, DefaultWorkerPool)},
 	}
 }
 
 // RegisterQueryResolver registers a resolver for a query field.
 func (e *Executor) RegisterQueryResolver(field string, resolver ResolverFunc) {
-	e.queryResolvers[field] = resolver
+	e.queryResolvers[field] = resolver.WithContext()
 }
 
 // RegisterMutationResolver registers a resolver for a mutation field.
 func (e *Executor) RegisterMutationResolver(field string, resolver ResolverFunc) {
-	e.mutationResolvers[field] = resolver
+	e.mutationResolvers[field] = resolver.WithContext()
 }
 
 // RegisterSubscriptionResolver registers a resolver for a subscription field.
 func (e *Executor) RegisterSubscriptionResolver(field string, resolver ResolverFunc) {
+	e.subscriptionResolvers[field] = resolver.WithContext()
+}
+
+// RegisterQueryResolverContext registers a context-aware resolver for a
+// query field.
+func (e *Executor) RegisterQueryResolverContext(field string, resolver ContextResolverFunc) {
+	e.queryResolvers[field] = resolver
+}
+
+// RegisterMutationResolverContext registers a context-aware resolver for a
+// mutation field.
+func (e *Executor) RegisterMutationResolverContext(field string, resolver ContextResolverFunc) {
+	e.mutationResolvers[field] = resolver
+}
+
+// RegisterSubscriptionResolverContext registers a context-aware resolver
+// for a subscription field. ctx is done when the subscriber goes away.
+func (e *Executor) RegisterSubscriptionResolverContext(field string, resolver ContextResolverFunc) {
 	e.subscriptionResolvers[field] = resolver
 }
 
 // Execute processes a parsed GraphQL document and returns the result.
 func (e *Executor) Execute(doc *ast.Document, variables map[string]interface{}) (map[string]interface{}, error) {
+	return e.ExecuteContext(context.Background(), doc, variables)
+}
+
+// ExecuteContext processes a parsed GraphQL document within ctx and returns
+// the result. Field errors do not fail the request: the failed field is
+// null, as is its nearest nullable ancestor if the schema declares it
+// non-null, and the errors are listed under "errors". Entries set with
+// SetExtension are listed under "extensions".
+//
+// ctx is passed to context-aware resolvers; once it is done, no further
+// fields are resolved and its error is returned. Timeouts set with
+// SetTimeout and SetFieldTimeout are reported as field errors instead.
+// Documents that fail validation enabled with SetValidation, operations
+// deeper than the limit set with SetMaxDepth and, with a schema set,
+// operations whose variables do not match their declared types fail
+// without resolving any field. Each operation gets its own scope of
+// dataloader loaders.
+func (e *Executor) ExecuteContext(ctx context.Context, doc *ast.Document, variables map[string]interface{}) (map[string]interface{}, error) {
+	return e.ExecuteOperation(ctx, doc, "", variables)
+}
+
+// ExecuteOperation executes the operation named operationName in doc, or
+// its first operation if operationName is empty, like ExecuteContext.
+func (e *Executor) ExecuteOperation(ctx context.Context, doc *ast.Document, operationName string, variables map[string]interface{}) (map[string]interface{}, error) {
+	if err := e.validateDocument(ctx, doc); err != nil {
+		return map[string]interface{}{}, err
+	}
+	q, err := e.prepare(doc, operationName)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+	return q.ExecuteContext(ctx, variables)
+}
+
+// executeOperation executes op with the state in ex.
+func (e *Executor) executeOperation(ctx context.Context, op *ast.OperationDefinition, ex *execution, variables map[string]interface{}) (map[string]interface{}, error) {
 	response := map[string]interface{}{}
-	if len(doc.Definitions) == 0 {
-		return response, fmt.Errorf("no definitions found")
+	ex.operation = op.Operation
+	ex.variables = VariableValues(op, variables)
+	if e.schema != nil {
+		if err := e.checkVariables(op, ex.variables); err != nil {
+			return response, err
+		}
 	}
-	op, ok := doc.Definitions[0].(*ast.OperationDefinition)
-	if !ok {
-		return response, fmt.Errorf("unsupported definition type")
+	warns := &warnings{}
+	ctx = context.WithValue(ctx, warningsKey{}, warns)
+	ext := &extensions{}
+	ctx = context.WithValue(ctx, extensionsKey{}, ext)
+	if debug := DebugFromContext(ctx); debug != nil {
+		ctx = dataloader.WithObserver(ctx, debug.RecordBatch)
 	}
-	data, err := e.executeSelectionSet(nil, op.SelectionSet, variables)
-	if err != nil {
+	ctx = dataloader.NewContext(ctx)
+	e.reportDeprecations(ctx, op, ex)
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, e.timeout, ErrExecutionTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	schema := e.schema
+	if schema == nil {
+		schema = &ast.Document{}
+	}
+	data, err := e.executeSelectionSet(ctx, nil, schema.RootTypeName(op.Operation), op.SelectionSet, ex, nil)
+	DebugFromContext(ctx).recordExecute(time.Since(start))
+	switch {
+	case err == errNullPropagated:
+		response["data"] = nil
+	case err != nil:
 		return response, err
+	default:
+		response["data"] = data
+	}
+	if len(ex.errors) > 0 {
+		for i, err := range ex.errors {
+			ex.errors[i] = e.PresentError(ctx, err)
+		}
+		response["errors"] = ex.errors
+	}
+	if len(warns.list) > 0 {
+		SetExtension(ctx, "warnings", warns.list)
+	}
+	if values := ext.snapshot(); len(values) > 0 {
+		response["extensions"] = values
 	}
-	response["data"] = data
 	return response, nil
 }
 
 // ExecuteSubscription executes a subscription and returns a channel of events.
 func (e *Executor) ExecuteSubscription(field *ast.Field, variables map[string]interface{}) (<-chan interface{}, error) {
+	return e.ExecuteSubscriptionContext(context.Background(), field, variables)
+}
+
+// ExecuteSubscriptionContext executes a subscription and returns a channel of
+// events. Subscription resolvers may return a channel, an iter.Seq or an
+// iter.Seq2 whose second value is an error. Iterators are driven by the
+// executor and stopped when ctx is done; a non-nil error from an iter.Seq2
+// is delivered as the final event.
+func (e *Executor) ExecuteSubscriptionContext(ctx context.Context, field *ast.Field, variables map[string]interface{}) (<-chan interface{}, error) {
 	if resolver, ok := e.subscriptionResolvers[field.Name]; ok {
 		args := buildArgs(field, variables)
-		res, err := resolver(nil, args)
+		if e.schema != nil {
+			if err := e.coerceArgs(e.schema.RootTypeName("subscription"), field, args); err != nil {
+				return nil, err
+			}
+		}
+		res, err := resolver(ctx, nil, args)
 		if err != nil {
 			return nil, err
 		}
@@ -77,62 +233,367 @@ func (e *Executor) ExecuteSubscription(field *ast.Field, variables map[string]in
 		if ch, ok := res.(chan interface{}); ok {
 			return (<-chan interface{})(ch), nil
 		}
-		return nil, fmt.Errorf("subscription resolver for field %s did not return a channel", field.Name)
+		// Finally, try an iterator
+		if seq, ok := iterSeq(res); ok {
+			return driveSeq(ctx, seq), nil
+		}
+		return nil, fmt.Errorf("subscription resolver for field %s did not return a channel or iterator", field.Name)
 	}
 	return nil, fmt.Errorf("no subscription resolver found for field %s", field.Name)
 }
 
-// executeSelectionSet traverses the selection set and resolves each field.
-func (e *Executor) executeSelectionSet(source interface{}, ss *ast.SelectionSet, variables map[string]interface{}) (map[string]interface{}, error) {
-	result := make(map[string]interface{})
-	for _, sel := range ss.Selections {
-		field, ok := sel.(*ast.Field)
-		if !ok {
+// ExecuteSubscriptionOperation starts the subscription operation named
+// operationName in doc, or its first operation if operationName is empty,
+// like ExecuteSubscriptionContext does for its first field. Like queries
+// run by ExecuteOperation, documents that fail validation enabled with
+// SetValidation and operations deeper than the limit set with SetMaxDepth
+// fail without starting.
+func (e *Executor) ExecuteSubscriptionOperation(ctx context.Context, doc *ast.Document, operationName string, variables map[string]interface{}) (<-chan interface{}, error) {
+	if err := e.validateDocument(ctx, doc); err != nil {
+		return nil, err
+	}
+	var op *ast.OperationDefinition
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if op == nil && (operationName == "" || def.Name == operationName) {
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name] = def
+		}
+	}
+	switch {
+	case op == nil && operationName != "":
+		return nil, fmt.Errorf("unknown operation %q", operationName)
+	case op == nil:
+		return nil, fmt.Errorf("no subscription definition found")
+	case op.Operation != "subscription":
+		return nil, fmt.Errorf("provided operation is not a subscription")
+	case op.SelectionSet == nil || len(op.SelectionSet.Selections) == 0:
+		return nil, fmt.Errorf("subscription selection set is empty")
+	}
+	if e.maxDepth > 0 {
+		if err := checkDepth(op, fragments, e.maxDepth); err != nil {
+			return nil, err
+		}
+	}
+	field, ok := op.SelectionSet.Selections[0].(*ast.Field)
+	if !ok {
+		return nil, fmt.Errorf("invalid subscription field")
+	}
+	return e.ExecuteSubscriptionContext(ctx, field, VariableValues(op, variables))
+}
+
+// execution holds the per-operation state shared by the whole traversal.
+type execution struct {
+	operation string // "query" or "mutation"
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	// possibleTypes lists the object types of each union and interface.
+	possibleTypes map[string]map[string]bool
+	errors        []*Error // field errors, in the order they occurred
+	timedOut      bool     // the execution timeout has been reported
+	memo          map[memoKey]memoResult
+	nodes         int                            // values in the response so far
+	size          int                            // approximate encoded size of the response so far
+	truncated     bool                           // a response limit was exceeded
+	goroutines    atomic.Int64                   // resolver goroutines running, see startWorker
+	collected     map[collectKey]collectedFields // see cachedFields
+	nullables     map[*ast.Type]*ast.Type        // see nullable
+	tracer        Tracer
+}
+
+// addError records a field error. Errors are *Error values as produced by
+// Executor.resolve. The execution timeout is recorded only once.
+func (ex *execution) addError(err error) {
+	if errors.Is(err, ErrExecutionTimeout) {
+		if ex.timedOut {
+			return
+		}
+		ex.timedOut = true
+	}
+	ex.errors = append(ex.errors, gqlerrors.Wrap(err))
+}
+
+// located returns err as an *Error with the position of field as its
+// location, unless it already has locations or the position is unknown.
+func located(err error, field *ast.Field) *Error {
+	gqlErr := gqlerrors.Wrap(err)
+	if gqlErr.Locations != nil || !field.Start.IsValid() {
+		return gqlErr
+	}
+	withLoc := *gqlErr
+	withLoc.Locations = []Location{{Line: field.Start.Line, Column: field.Start.Column}}
+	return &withLoc
+}
+
+// nullable returns the nullable version of the non-null type t, made once
+// per operation.
+func (ex *execution) nullable(t *ast.Type) *ast.Type {
+	if n, ok := ex.nullables[t]; ok {
+		return n
+	}
+	n := *t
+	n.NonNull = false
+	if ex.nullables == nil {
+		ex.nullables = make(map[*ast.Type]*ast.Type)
+	}
+	ex.nullables[t] = &n
+	return &n
+}
+
+// errNullPropagated reports that a non-null value was null, so the nearest
+// nullable ancestor must become null. The cause has already been recorded
+// as a field error.
+var errNullPropagated = errors.New("null propagated to parent")
+
+// executeSelectionSet traverses the selection set and resolves each field
+// of source, an object of the named type. path is the response path of the
+// object being resolved. Field errors are recorded in ex and null the
+// field; it returns errNullPropagated if a non-null field is null, and
+// other errors only if execution must stop.
+func (e *Executor) executeSelectionSet(ctx context.Context, source interface{}, typeName string, ss *ast.SelectionSet, ex *execution, path []interface{}) (interface{}, error) {
+	return e.startSelectionSet(ctx, source, typeName, ss, ex, path).complete()
+}
+
+// completion finishes a value whose fields have been resolved, completing
+// them in turn. Values that are already finished, such as leaves, are held
+// as they are rather than in a function, which saves an allocation for
+// most of the values of a response.
+type completion struct {
+	value  interface{}
+	finish func() (interface{}, error)
+}
+
+// completed returns the completion of a finished value.
+func completed(value interface{}) completion {
+	return completion{value: value}
+}
+
+// completeWith returns the completion that calls finish.
+func completeWith(finish func() (interface{}, error)) completion {
+	return completion{finish: finish}
+}
+
+// complete returns the completed value.
+func (c completion) complete() (interface{}, error) {
+	if c.finish == nil {
+		return c.value, nil
+	}
+	return c.finish()
+}
+
+// selectedField is a field whose resolver has been called.
+type selectedField struct {
+	field     *ast.Field
+	fieldType *ast.Type
+	path      []interface{}
+	value     interface{}
+	done      bool // value is final and needs no completion
+}
+
+// startSelectionSet calls the resolvers of the fields of ss like
+// executeSelectionSet and returns the completion of the object. Execution
+// is breadth-first: the resolvers of every object in a list, and of the
+// siblings of a field whose value is a Thunk, run before any of them is
+// completed, so loads they schedule can be batched.
+func (e *Executor) startSelectionSet(ctx context.Context, source interface{}, typeName string, ss *ast.SelectionSet, ex *execution, path []interface{}) completion {
+	failed := func(err error) completion {
+		return completeWith(func() (interface{}, error) { return nil, err })
+	}
+	debug := DebugFromContext(ctx)
+	collected, keys := ex.cachedFields(source, typeName, ss)
+	// The fields, their paths and their infos are allocated together.
+	fields := make([]selectedField, 0, len(collected))
+	paths := make([]interface{}, 0, len(collected)*(len(path)+1))
+	infos := make([]ResolveInfo, len(collected))
+	for i, field := range collected {
+		paths = append(append(paths, path...), keys[i])
+		fields = append(fields, selectedField{field: field, path: paths[len(paths)-len(path)-1 : len(paths) : len(paths)]})
+		f := &fields[len(fields)-1]
+		if e.maxResponseSize > 0 {
+			ex.size += len(field.ResponseKey()) + 8 // quotes, colon, comma and null
+		}
+		if def := e.fields[typeName][field.Name]; def != nil {
+			f.fieldType = def.Type
+		}
+		if err := ctx.Err(); err != nil {
+			if !errors.Is(context.Cause(ctx), ErrExecutionTimeout) {
+				return failed(err)
+			}
+			// Abort the remaining fields.
+			if err := e.fieldFailed(ctx, f, e.newError(f.path, ErrExecutionTimeout, nil), ex); err != nil {
+				return failed(err)
+			}
+			continue
+		}
+		if field.Name == typeNameField {
+			f.value, f.done = e.resolveTypeName(source, typeName), true
 			continue
 		}
-		res, err := e.resolveField(source, field, variables)
+		info := &infos[i]
+		*info = ResolveInfo{
+			FieldName:    field.Name,
+			Alias:        field.Alias,
+			Path:         f.path,
+			ParentType:   typeName,
+			ReturnType:   f.fieldType,
+			Variables:    ex.variables,
+			Field:        field,
+			SelectionSet: field.SelectionSet,
+			ex:           ex,
+		}
+		fieldCtx, finish := ex.tracer.StartField(context.WithValue(ctx, resolveInfoKey{}, info), info)
+		start := time.Now()
+		res, err := e.memoized(ex, source, typeName, field, f.path, func() (interface{}, error) {
+			return e.resolveTimed(fieldCtx, ex, f.path, func(ctx context.Context) (interface{}, error) {
+				return e.resolveField(ctx, source, typeName, field, ex)
+			})
+		})
+		debug.recordField(f.path, time.Since(start))
+		finish(time.Since(start), err)
 		if err != nil {
-			return nil, err
+			if err := e.fieldFailed(ctx, f, err, ex); err != nil {
+				return failed(err)
+			}
+			continue
 		}
-		if field.SelectionSet != nil {
-			nested, err := e.resolveNestedSelection(res, field.SelectionSet, variables)
+		f.value = res
+	}
+
+	return completeWith(func() (interface{}, error) {
+		completions := make([]completion, len(fields))
+		for i := range fields {
+			f := &fields[i]
+			if f.done {
+				continue
+			}
+			value, err := e.forceThunks(ctx, ex, f.value, f.path)
 			if err != nil {
-				return nil, err
+				if err := e.fieldFailed(ctx, f, err, ex); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			completions[i] = e.completeValue(ctx, f.fieldType, value, f.field.SelectionSet, ex, f.path)
+		}
+		result := make(map[string]interface{}, len(fields))
+		var keys []string
+		for i, f := range fields {
+			value := f.value
+			if !f.done {
+				var err error
+				if value, err = completions[i].complete(); err != nil {
+					return nil, err
+				}
 			}
-			result[field.Name] = nested
-		} else {
-			result[field.Name] = res
+			result[f.field.ResponseKey()] = value
+			if e.orderedFields {
+				keys = append(keys, f.field.ResponseKey())
+			}
+		}
+		if e.orderedFields {
+			return &OrderedMap{Keys: keys, Values: result}, nil
+		}
+		return result, nil
+	})
+}
+
+// fieldFailed records the error of resolving f and nulls the field. It
+// returns errNullPropagated if the field is non-null. Missing fields are
+// handled according to the missing field policy.
+func (e *Executor) fieldFailed(ctx context.Context, f *selectedField, err error, ex *execution) error {
+	f.value, f.done = nil, true
+	var missing *missingFieldError
+	switch {
+	case e.missingFieldPolicy == MissingFieldError || !errors.As(err, &missing):
+		ex.addError(located(err, f.field))
+		if f.fieldType != nil && f.fieldType.NonNull {
+			return errNullPropagated
 		}
+	case e.missingFieldPolicy == MissingFieldNullWithWarning:
+		warningsFromContext(ctx).add(f.path, missing.Error())
 	}
-	return result, nil
+	return nil
 }
 
-// resolveField looks up and executes the appropriate resolver for a field.
-func (e *Executor) resolveField(source interface{}, field *ast.Field, variables map[string]interface{}) (interface{}, error) {
-	// At the top level, source is nil, so try both query and mutation resolvers
-	if source == nil {
-		// First, try the query resolver
-		if resolver, ok := e.queryResolvers[field.Name]; ok {
-			args := buildArgs(field, variables)
-			return resolver(source, args)
+// resolveField looks up and executes the appropriate resolver for a field
+// of source, an object of the named type. Root fields are resolved only by
+// the resolvers registered for the operation type being executed; other
+// fields by a struct field, map entry or method of source.
+func (e *Executor) resolveField(ctx context.Context, source interface{}, typeName string, field *ast.Field, ex *execution) (interface{}, error) {
+	if source != nil {
+		res, err := e.reflectResolve(source, field)
+		if err == nil || !isMissingField(err) {
+			return res, err
+		}
+		method, ok := findMethod(source, field.Name)
+		if !ok {
+			return nil, err
+		}
+		args, err := e.fieldArgs(typeName, field, ex)
+		if err != nil {
+			return nil, err
+		}
+		return callMethod(ctx, method, args)
+	}
+	resolvers, err := e.rootResolvers(ex.operation)
+	if err != nil {
+		return nil, err
+	}
+	if resolver, ok := resolvers[field.Name]; ok {
+		args, err := e.fieldArgs(typeName, field, ex)
+		if err != nil {
+			return nil, err
+		}
+		return resolver(ctx, source, args)
+	}
+	for _, other := range []string{"query", "mutation", "subscription"} {
+		if other == ex.operation {
+			continue
 		}
-		// Next, try the mutation resolver
-		if resolver, ok := e.mutationResolvers[field.Name]; ok {
-			args := buildArgs(field, variables)
-			return resolver(source, args)
+		if others, _ := e.rootResolvers(other); others[field.Name] != nil {
+			return nil, fmt.Errorf("field %s is a %s field and cannot be selected in a %s", field.Name, other, ex.operation)
 		}
 	}
+	return nil, fmt.Errorf("no resolver found for field %s", field.Name)
+}
 
-	// If the source is not nil, use reflection to resolve nested fields
-	if source != nil {
-		return reflectResolve(source, field)
+// fieldArgs returns the arguments of field, selected on the named type,
+// coerced to the types the schema declares for them.
+func (e *Executor) fieldArgs(typeName string, field *ast.Field, ex *execution) (map[string]interface{}, error) {
+	args := buildArgs(field, ex.variables)
+	if err := e.coerceArgs(typeName, field, args); err != nil {
+		return nil, err
 	}
+	return args, nil
+}
 
-	return nil, fmt.Errorf("no resolver found for field %s", field.Name)
+// rootResolvers returns the resolvers of the root fields of an operation type.
+func (e *Executor) rootResolvers(operation string) (map[string]ContextResolverFunc, error) {
+	switch operation {
+	case "query":
+		return e.queryResolvers, nil
+	case "mutation":
+		return e.mutationResolvers, nil
+	case "subscription":
+		return e.subscriptionResolvers, nil
+	}
+	return nil, fmt.Errorf("unsupported operation type %q", operation)
 }
 
-// reflectResolve uses reflection to find a field value on a source struct.
-func reflectResolve(source interface{}, field *ast.Field) (interface{}, error) {
+// reflectResolve uses reflection to find a field value on a source struct
+// or map.
+func (e *Executor) reflectResolve(source interface{}, field *ast.Field) (interface{}, error) {
+	if raw, ok := source.(json.RawMessage); ok {
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, fmt.Errorf("invalid raw JSON: %v", err)
+		}
+		source = decoded
+	}
 	val := reflect.ValueOf(source)
 	// Dereference pointer if needed
 	if val.Kind() == reflect.Ptr {
@@ -141,67 +602,241 @@ func reflectResolve(source interface{}, field *ast.Field) (interface{}, error) {
 		}
 		val = val.Elem()
 	}
+	if val.Kind() == reflect.Map {
+		return mapResolve(val, field)
+	}
 	if val.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("source is not a struct")
-	}
-
-	typ := val.Type()
-	// Loop through all fields
-	for i := 0; i < typ.NumField(); i++ {
-		sf := typ.Field(i)
-		// Check if the field name matches (case-insensitive)
-		if strings.EqualFold(sf.Name, field.Name) {
-			return val.Field(i).Interface(), nil
-		}
-		// Also check the "json" tag if present
-		if tag, ok := sf.Tag.Lookup("json"); ok {
-			tagName := strings.Split(tag, ",")[0]
-			if strings.EqualFold(tagName, field.Name) {
-				return val.Field(i).Interface(), nil
-			}
-		}
+		return nil, fmt.Errorf("source is not a struct or map")
 	}
 
-	return nil, fmt.Errorf("no resolver found for field %s via reflection", field.Name)
+	if i, ok := e.structFields(val.Type())[strings.ToLower(field.Name)]; ok {
+		return val.Field(i).Interface(), nil
+	}
+	return nil, &missingFieldError{field: field.Name}
 }
 
-// resolveNestedSelection handles nested selection sets for both objects and slices.
-func (e *Executor) resolveNestedSelection(res interface{}, ss *ast.SelectionSet, variables map[string]interface{}) (interface{}, error) {
+// mapResolve looks up a field in a map with string keys, preferring an
+// exact match of the key over a case-insensitive one. Absent keys resolve
+// to null, as JSON objects commonly omit null members.
+func mapResolve(val reflect.Value, field *ast.Field) (interface{}, error) {
+	if val.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("source map keys are not strings")
+	}
+	key := reflect.ValueOf(field.Name).Convert(val.Type().Key())
+	if v := val.MapIndex(key); v.IsValid() {
+		return v.Interface(), nil
+	}
+	iter := val.MapRange()
+	for iter.Next() {
+		if strings.EqualFold(iter.Key().String(), field.Name) {
+			return iter.Value().Interface(), nil
+		}
+	}
+	return nil, nil
+}
+
+// completeValue shapes the resolved value res of a field of type t for the
+// response, applying the selection set ss to objects and lists of objects
+// and serializing enums. The resolvers of the fields of objects are called
+// right away; completing them is left to the returned completion.
+// If t is non-null and the value is null, the null is recorded as a field
+// error unless an error below caused it, and errNullPropagated is returned.
+// Without a schema, t is nil and every value is nullable.
+func (e *Executor) completeValue(ctx context.Context, t *ast.Type, res interface{}, ss *ast.SelectionSet, ex *execution, path []interface{}) completion {
+	if t != nil && t.NonNull {
+		errs := len(ex.errors)
+		c := e.completeValue(ctx, ex.nullable(t), res, ss, ex, path)
+		if c.finish == nil && c.value != nil {
+			return c
+		}
+		return completeWith(func() (interface{}, error) {
+			value, err := c.complete()
+			if err != nil || value != nil {
+				return value, err
+			}
+			if len(ex.errors) == errs && !ex.truncated {
+				ex.addError(e.newError(path, fmt.Errorf("cannot return null for non-nullable type %s", t), nil))
+			}
+			return nil, errNullPropagated
+		})
+	}
+	if isNull(res) {
+		return completed(nil)
+	}
+	if ss == nil && (t == nil || !t.IsList) {
+		value := e.completeLeaf(t, res, ex, path)
+		if !e.charge(ex, path, e.sizeOf(value)) {
+			return completed(nil)
+		}
+		return completed(value)
+	}
+	if !e.charge(ex, path, 2) {
+		return completed(nil)
+	}
+	if raw, ok := res.(json.RawMessage); ok {
+		if ss == nil || !e.filterRawJSON {
+			return completed(raw)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			ex.addError(e.newError(path, fmt.Errorf("invalid raw JSON: %v", err), nil))
+			return completed(nil)
+		}
+		res = decoded
+	}
 	val := reflect.ValueOf(res)
 	switch val.Kind() {
 	case reflect.Ptr:
-		if val.IsNil() {
-			return res, nil
+		if ss != nil && val.Elem().Kind() == reflect.Struct {
+			return e.completeObject(ctx, t, res, ss, ex, path)
 		}
-		if val.Elem().Kind() == reflect.Struct {
-			return e.executeSelectionSet(res, ss, variables)
+	case reflect.Struct, reflect.Map:
+		if ss != nil {
+			return e.completeObject(ctx, t, res, ss, ex, path)
 		}
-	case reflect.Struct:
-		return e.executeSelectionSet(res, ss, variables)
 	case reflect.Slice:
-		var arr []interface{}
-		for i := 0; i < val.Len(); i++ {
-			item := val.Index(i).Interface()
-			sub, err := e.executeSelectionSet(item, ss, variables)
-			if err != nil {
-				return nil, err
+		var elem *ast.Type
+		if t != nil && t.IsList {
+			elem = t.Elem
+		}
+		items := make([]completion, val.Len())
+		for i := range items {
+			nodes := ex.nodes
+			items[i] = e.completeValue(ctx, elem, val.Index(i).Interface(), ss, ex, append(path[:len(path):len(path)], i))
+			if ex.truncated {
+				// End the list after the last item that was not truncated.
+				if ex.nodes == nodes {
+					items = items[:i]
+				} else {
+					items = items[:i+1]
+				}
+				break
+			}
+		}
+		return completeWith(func() (interface{}, error) {
+			arr := make([]interface{}, len(items))
+			for i, c := range items {
+				item, err := c.complete()
+				if err == errNullPropagated {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = item
+			}
+			return arr, nil
+		})
+	}
+	return completed(res)
+}
+
+// completeLeaf serializes the scalar or enum value res of type t. Values of
+// registered enums are converted to their names and scalar values are
+// serialized by their Scalar; the result then goes through serializeLeaf.
+// Values that cannot be serialized are field errors and complete as nil.
+func (e *Executor) completeLeaf(t *ast.Type, res interface{}, ex *execution, path []interface{}) interface{} {
+	value, err := res, error(nil)
+	if t != nil {
+		if enum, ok := e.enums[t.Name]; ok {
+			value, err = enum.serialize(res)
+		} else if scalar, ok := e.scalar(t.Name); ok && scalar.Serialize != nil {
+			value, err = scalar.Serialize(res)
+		}
+	}
+	if err == nil {
+		value, err = serializeLeaf(value)
+	}
+	if err != nil {
+		ex.addError(e.newError(path, err, nil))
+		return nil
+	}
+	return value
+}
+
+// isNull reports whether v is nil or a nil pointer, map or slice.
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return val.IsNil()
+	}
+	return false
+}
+
+// completeObject executes ss on the object res of type t. A null propagated
+// from one of its fields makes the object null.
+func (e *Executor) completeObject(ctx context.Context, t *ast.Type, res interface{}, ss *ast.SelectionSet, ex *execution, path []interface{}) completion {
+	typeName := objectTypeName(res)
+	if t != nil {
+		if resolver, ok := e.typeResolvers[t.NamedType()]; ok {
+			if name := resolver(res); name != "" {
+				typeName = name
 			}
-			arr = append(arr, sub)
+		} else if _, ok := e.fields[typeName]; !ok {
+			typeName = t.NamedType()
 		}
-		return arr, nil
 	}
-	return res, nil
+	c := e.startSelectionSet(ctx, res, typeName, ss, ex, path)
+	return completeWith(func() (interface{}, error) {
+		obj, err := c.complete()
+		if err == errNullPropagated {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	})
 }
 
-// buildArgs constructs a map of argument names to values.
+// VariableValues returns variables with the default value of every variable
+// op declares but variables omits filled in, and with numbers coerced to
+// the declared types of the variables: JSON decodes every number as
+// float64, but resolvers expect int for Int, float64 for Float and string
+// for ID. An explicit null is kept. variables itself is not modified.
+func VariableValues(op *ast.OperationDefinition, variables map[string]interface{}) map[string]interface{} {
+	if len(op.VariableDefinitions) == 0 {
+		return variables
+	}
+	values := make(map[string]interface{}, len(variables)+len(op.VariableDefinitions))
+	for name, value := range variables {
+		values[name] = value
+	}
+	for _, def := range op.VariableDefinitions {
+		if value, ok := variables[def.Variable]; ok {
+			values[def.Variable], _ = coercion{}.value(&def.Type, value)
+		} else if def.DefaultValue != nil {
+			values[def.Variable], _ = coercion{}.value(&def.Type, buildValue(def.DefaultValue, nil))
+		}
+	}
+	return values
+}
+
+// buildArgs constructs a map of argument names to values. Arguments set to
+// a variable that was not provided are left out, so that resolvers can tell
+// them from arguments explicitly set to null (see Args).
 func buildArgs(field *ast.Field, variables map[string]interface{}) map[string]interface{} {
 	args := make(map[string]interface{})
 	for _, arg := range field.Arguments {
-		args[arg.Name] = buildValue(arg.Value, variables)
+		if !absent(arg.Value, variables) {
+			args[arg.Name] = buildValue(arg.Value, variables)
+		}
 	}
 	return args
 }
 
+// absent reports whether val is a variable that was not provided.
+func absent(val *ast.Value, variables map[string]interface{}) bool {
+	if val == nil || val.Kind != "Variable" {
+		return false
+	}
+	_, ok := variables[val.Literal]
+	return !ok
+}
+
 // buildValue converts an AST Value to a Go value.
 func buildValue(val *ast.Value, variables map[string]interface{}) interface{} {
 	switch val.Kind {
@@ -213,9 +848,18 @@ func buildValue(val *ast.Value, variables map[string]interface{}) interface{} {
 	case "Int":
 		i, err := strconv.Atoi(val.Literal)
 		if err != nil {
-			return 0
+			// Too large for int; kept exact for the BigInt scalar.
+			return json.Number(val.Literal)
 		}
 		return i
+	case "Float":
+		f, err := strconv.ParseFloat(val.Literal, 64)
+		if err != nil {
+			return 0.0
+		}
+		return f
+	case "Null":
+		return nil
 	case "String":
 		return val.Literal
 	case "Boolean":
@@ -223,7 +867,9 @@ func buildValue(val *ast.Value, variables map[string]interface{}) interface{} {
 	case "Object":
 		m := make(map[string]interface{})
 		for key, fieldVal := range val.ObjectFields {
-			m[key] = buildValue(fieldVal, variables)
+			if !absent(fieldVal, variables) {
+				m[key] = buildValue(fieldVal, variables)
+			}
 		}
 		return m
 	case "Array":
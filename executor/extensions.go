@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// extensionsKey is the context key for the execution's extensions.
+type extensionsKey struct{}
+
+// extensions collects the entries of a response's "extensions" map.
+type extensions struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// SetExtension sets the named entry of the "extensions" map of the response
+// being executed with ctx. Resolvers, tracers and deprecation handlers use
+// it to report tracing data, cache hints and the like. It does nothing
+// outside of an execution.
+func SetExtension(ctx context.Context, name string, value interface{}) {
+	ext, ok := ctx.Value(extensionsKey{}).(*extensions)
+	if !ok {
+		return
+	}
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	if ext.values == nil {
+		ext.values = make(map[string]interface{})
+	}
+	ext.values[name] = value
+}
+
+// snapshot returns a copy of the extensions set so far, which resolvers
+// still running after a field timeout cannot change.
+func (x *extensions) snapshot() map[string]interface{} {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	values := make(map[string]interface{}, len(x.values))
+	for name, value := range x.values {
+		values[name] = value
+	}
+	return values
+}
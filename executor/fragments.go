@@ -0,0 +1,229 @@
+package executor
+
+import (
+	"reflect"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// collectFields returns the fields selected by ss on source, an object of
+// the named type (or "" if unknown), with fragment spreads and inline
+// fragments expanded. Fields selected more than once under the same
+// response key are merged into a single field whose sub-selections are
+// combined, and spreads of unknown fragments or of a fragment already being
+// expanded are ignored. Fragments whose type condition does not apply to
+// source are skipped, as are selections excluded by @skip or @include.
+func (ex *execution) collectFields(source interface{}, typeName string, ss *ast.SelectionSet) []*ast.Field {
+	fields, _ := ex.collectFieldsOf(source, typeName, ss)
+	return fields
+}
+
+// collectFieldsOf is collectFields that also reports whether the fields
+// depend on source rather than only on its type, through a fragment whose
+// type condition was checked against source.
+func (ex *execution) collectFieldsOf(source interface{}, typeName string, ss *ast.SelectionSet) (fields []*ast.Field, bySource bool) {
+	if ss == nil {
+		return nil, false
+	}
+	c := collector{index: make(map[string]int, len(ss.Selections))}
+	ex.collect(source, typeName, ss, &c)
+	return c.fields, c.bySource
+}
+
+// collectKey identifies the fields selected by a selection set on a type.
+type collectKey struct {
+	ss       *ast.SelectionSet
+	typeName string
+}
+
+// cachedFields is collectFields for the traversal, which selects the same
+// fields on every object of a list. It also returns the response keys of
+// the fields, ready for use in paths. Fields that do not depend on source
+// are collected once per operation. It must not be called by resolvers.
+func (ex *execution) cachedFields(source interface{}, typeName string, ss *ast.SelectionSet) ([]*ast.Field, []interface{}) {
+	key := collectKey{ss, typeName}
+	if c, ok := ex.collected[key]; ok {
+		return c.fields, c.keys
+	}
+	fields, bySource := ex.collectFieldsOf(source, typeName, ss)
+	keys := make([]interface{}, len(fields))
+	for i, field := range fields {
+		keys[i] = field.ResponseKey()
+	}
+	if !bySource {
+		if ex.collected == nil {
+			ex.collected = make(map[collectKey]collectedFields)
+		}
+		ex.collected[key] = collectedFields{fields, keys}
+	}
+	return fields, keys
+}
+
+// collectedFields are the results of cachedFields.
+type collectedFields struct {
+	fields []*ast.Field
+	keys   []interface{}
+}
+
+// collector accumulates the fields of collect.
+type collector struct {
+	fields   []*ast.Field
+	index    map[string]int  // position of each response key in fields
+	visiting map[string]bool // fragments being expanded, to break cycles
+	bySource bool            // a type condition was checked against source
+}
+
+// included reports whether a selection with the given directives is
+// executed, honoring @skip(if:) and @include(if:).
+func (ex *execution) included(directives []*ast.Directive) bool {
+	for _, d := range directives {
+		cond := d.Argument("if")
+		if cond == nil {
+			continue
+		}
+		value, _ := buildValue(cond, ex.variables).(bool)
+		switch d.Name {
+		case "skip":
+			if value {
+				return false
+			}
+		case "include":
+			if !value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// collect appends the fields of ss to c, merging them by response key.
+func (ex *execution) collect(source interface{}, typeName string, ss *ast.SelectionSet, c *collector) {
+	if ss == nil {
+		return
+	}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !ex.included(sel.Directives) {
+				continue
+			}
+			i, seen := c.index[sel.ResponseKey()]
+			if !seen {
+				c.index[sel.ResponseKey()] = len(c.fields)
+				c.fields = append(c.fields, sel)
+				continue
+			}
+			prev := c.fields[i]
+			if prev.SelectionSet == nil || sel.SelectionSet == nil {
+				continue
+			}
+			merged := *prev
+			merged.SelectionSet = &ast.SelectionSet{
+				Selections: append(append([]ast.Selection(nil), prev.SelectionSet.Selections...), sel.SelectionSet.Selections...),
+			}
+			c.fields[i] = &merged
+		case *ast.FragmentSpread:
+			frag, ok := ex.fragments[sel.Name]
+			if !ok || c.visiting[sel.Name] || !ex.included(sel.Directives) || !c.applies(ex, source, typeName, frag.TypeCondition) {
+				continue
+			}
+			if c.visiting == nil {
+				c.visiting = make(map[string]bool)
+			}
+			c.visiting[sel.Name] = true
+			ex.collect(source, typeName, frag.SelectionSet, c)
+			delete(c.visiting, sel.Name)
+		case *ast.InlineFragment:
+			if ex.included(sel.Directives) && c.applies(ex, source, typeName, sel.TypeCondition) {
+				ex.collect(source, typeName, sel.SelectionSet, c)
+			}
+		}
+	}
+}
+
+// applies is typeConditionApplies, noting when the answer depends on
+// source rather than on typeName.
+func (c *collector) applies(ex *execution, source interface{}, typeName, typeCondition string) bool {
+	if typeCondition == "" {
+		return true
+	}
+	if _, abstract := ex.possibleTypes[typeName]; source == nil || typeName == "" || abstract {
+		c.bySource = true
+	}
+	return ex.typeConditionApplies(source, typeName, typeCondition)
+}
+
+// typeConditionApplies reports whether a fragment on the named type applies
+// to source, an object of type typeName. Unless typeName is a known object
+// type, the type is that of source as given by objectTypeName. A condition
+// applies to its own type and, according to the schema, to the members of
+// the union or the implementations of the interface it names. Sources of
+// unknown type, such as the root value or maps, match any condition.
+func (ex *execution) typeConditionApplies(source interface{}, typeName, typeCondition string) bool {
+	if typeCondition == "" || source == nil {
+		return true
+	}
+	if _, abstract := ex.possibleTypes[typeName]; typeName == "" || abstract {
+		typeName = objectTypeName(source)
+	}
+	if typeName == "" || typeName == typeCondition {
+		return true
+	}
+	return ex.possibleTypes[typeCondition][typeName]
+}
+
+// objectTypeName returns the name of the object type of source: the name
+// reported by a TypeNamer, the "__typename" entry of a map, or the name of
+// its struct type. It returns "" if none is known.
+func objectTypeName(source interface{}) string {
+	if source == nil {
+		return ""
+	}
+	if namer, ok := source.(TypeNamer); ok {
+		return namer.TypeName()
+	}
+	if m, ok := source.(map[string]interface{}); ok {
+		name, _ := m[typeNameField].(string)
+		return name
+	}
+	t := reflect.TypeOf(source)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	return t.Name()
+}
+
+// possibleTypes indexes the object types of schema by the unions and
+// interfaces they belong to. Every union and interface has an entry.
+func possibleTypes(schema *ast.Document) map[string]map[string]bool {
+	index := make(map[string]map[string]bool)
+	add := func(abstract, object string) {
+		if index[abstract] == nil {
+			index[abstract] = make(map[string]bool)
+		}
+		index[abstract][object] = true
+	}
+	for _, def := range schema.Definitions {
+		if ext, ok := def.(*ast.TypeExtension); ok {
+			def = ext.Definition
+		}
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			for _, iface := range def.Interfaces {
+				add(iface, def.Name)
+			}
+		case *ast.InterfaceTypeDefinition:
+			if index[def.Name] == nil {
+				index[def.Name] = make(map[string]bool)
+			}
+		case *ast.UnionTypeDefinition:
+			for _, member := range def.Types {
+				add(def.Name, member)
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// ResolveInfo describes the field being resolved. Context-aware resolvers
+// and resolver methods obtain it with ResolveInfoFromContext, for example
+// to fetch only the columns backing the selected subfields.
+type ResolveInfo struct {
+	FieldName  string        // name of the field in the schema
+	Alias      string        // alias of the field, if any
+	Path       []interface{} // response path of the field
+	ParentType string        // type the field is selected on, if known
+	// ReturnType is the declared type of the field; it is nil without a
+	// schema.
+	ReturnType *ast.Type
+	// Variables holds the operation's variable values.
+	Variables map[string]interface{}
+	// Field is the selected field, including its arguments and directives.
+	Field *ast.Field
+	// SelectionSet is the sub-selection of the field, nil for leaves.
+	SelectionSet *ast.SelectionSet
+
+	ex *execution
+}
+
+type resolveInfoKey struct{}
+
+// ResolveInfoFromContext returns the description of the field being
+// resolved with ctx, or nil outside of a resolver.
+func ResolveInfoFromContext(ctx context.Context) *ResolveInfo {
+	info, _ := ctx.Value(resolveInfoKey{}).(*ResolveInfo)
+	return info
+}
+
+// SelectedFields returns the names of the fields selected directly under
+// the field, with fragments expanded and duplicates removed. Fields of
+// every fragment are included since the concrete type of the value is not
+// known until it is resolved.
+func (info *ResolveInfo) SelectedFields() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range info.ex.collectFields(nil, "", info.SelectionSet) {
+		if !seen[field.Name] {
+			seen[field.Name] = true
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"iter"
+	"reflect"
+)
+
+var (
+	boolType  = reflect.TypeOf(true)
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// iterSeq adapts an iter.Seq[T] or iter.Seq2[T, error] of any element type
+// to an iter.Seq2[interface{}, error].
+func iterSeq(res interface{}) (iter.Seq2[interface{}, error], bool) {
+	switch seq := res.(type) {
+	case iter.Seq[interface{}]:
+		return func(yield func(interface{}, error) bool) {
+			seq(func(v interface{}) bool { return yield(v, nil) })
+		}, true
+	case iter.Seq2[interface{}, error]:
+		return seq, true
+	}
+
+	// Generic element types need reflection to build a matching yield func.
+	fn := reflect.ValueOf(res)
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, false
+	}
+	ft := fn.Type()
+	if ft.NumIn() != 1 || ft.NumOut() != 0 {
+		return nil, false
+	}
+	yt := ft.In(0)
+	if yt.Kind() != reflect.Func || yt.NumOut() != 1 || yt.Out(0) != boolType {
+		return nil, false
+	}
+	switch {
+	case yt.NumIn() == 1:
+		return func(yield func(interface{}, error) bool) {
+			fn.Call([]reflect.Value{reflect.MakeFunc(yt, func(in []reflect.Value) []reflect.Value {
+				return []reflect.Value{reflect.ValueOf(yield(in[0].Interface(), nil))}
+			})})
+		}, true
+	case yt.NumIn() == 2 && yt.In(1) == errorType:
+		return func(yield func(interface{}, error) bool) {
+			fn.Call([]reflect.Value{reflect.MakeFunc(yt, func(in []reflect.Value) []reflect.Value {
+				err, _ := in[1].Interface().(error)
+				return []reflect.Value{reflect.ValueOf(yield(in[0].Interface(), err))}
+			})})
+		}, true
+	}
+	return nil, false
+}
+
+// driveSeq runs seq in its own goroutine and forwards its values to the
+// returned channel until the sequence ends, yields an error, or ctx is done.
+// The channel is closed when the iterator has returned.
+func driveSeq(ctx context.Context, seq iter.Seq2[interface{}, error]) <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		seq(func(v interface{}, err error) bool {
+			if err != nil {
+				v = err
+			}
+			select {
+			case ch <- v:
+				return err == nil
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
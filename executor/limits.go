@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"encoding/json"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// SetMaxNodes limits the number of non-null values, objects, lists and
+// leaves, in the data of a response. Once an operation reaches the limit,
+// an errcode.NodeLimitExceeded field error is recorded and the rest of the
+// response is truncated: remaining values are null and lists end early.
+// Zero, the default, means no limit.
+func (e *Executor) SetMaxNodes(n int) {
+	e.maxNodes = n
+}
+
+// SetMaxResponseSize limits the approximate size in bytes of the data of a
+// response once encoded as JSON. Once an operation reaches the limit, an
+// errcode.ResponseSizeExceeded field error is recorded and the rest of the
+// response is truncated as with SetMaxNodes. Zero, the default, means no
+// limit.
+func (e *Executor) SetMaxResponseSize(n int) {
+	e.maxResponseSize = n
+}
+
+// charge accounts for a value of size bytes at path against the node and
+// response size limits. It returns false, recording the error the first
+// time, if the value exceeds a limit and must be truncated; truncated
+// values are not counted.
+func (e *Executor) charge(ex *execution, path []interface{}, size int) bool {
+	if ex.truncated {
+		return false
+	}
+	var err error
+	switch {
+	case e.maxNodes > 0 && ex.nodes+1 > e.maxNodes:
+		err = errcode.New(errcode.NodeLimitExceeded, "limit", e.maxNodes)
+	case e.maxResponseSize > 0 && ex.size+size > e.maxResponseSize:
+		err = errcode.New(errcode.ResponseSizeExceeded, "limit", e.maxResponseSize)
+	default:
+		ex.nodes++
+		ex.size += size
+		return true
+	}
+	ex.truncated = true
+	ex.addError(e.newError(path, err, nil))
+	return false
+}
+
+// sizeOf estimates the encoded size of the leaf value v. It is only
+// computed when the response size is limited.
+func (e *Executor) sizeOf(v interface{}) int {
+	if e.maxResponseSize <= 0 {
+		return 0
+	}
+	switch v := v.(type) {
+	case string:
+		return len(v) + 2
+	case bool:
+		return 5
+	case int:
+		return 20
+	}
+	data, _ := json.Marshal(v)
+	return len(data)
+}
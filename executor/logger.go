@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger installs the logger recording the executor's internal
+// failures, such as resolver panics, which are otherwise only reported to
+// clients. A nil logger, the default, discards them.
+func (e *Executor) SetLogger(logger *slog.Logger) {
+	e.logger = logger
+}
+
+// Logger returns the executor's logger, which discards records if none is
+// set.
+func (e *Executor) Logger() *slog.Logger {
+	if e.logger == nil {
+		return discardLogger
+	}
+	return e.logger
+}
+
+// discardLogger is the logger of executors without one.
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler that discards all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
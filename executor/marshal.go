@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Marshaler is implemented by values that serialize themselves as GraphQL
+// leaf values. MarshalGraphQL returns the value to encode in the response,
+// such as a string or number.
+type Marshaler interface {
+	MarshalGraphQL() (interface{}, error)
+}
+
+// serializeLeaf converts a leaf value to its response representation,
+// trying in turn Marshaler, time.Time as an RFC 3339 string, json.Marshaler
+// decoded to plain values, and fmt.Stringer. Other values are returned as
+// they are.
+func serializeLeaf(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case Marshaler:
+		return v.MarshalGraphQL()
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case *time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case json.RawMessage:
+		return v, nil
+	case json.Marshaler:
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, fmt.Errorf("invalid JSON from %T: %v", v, err)
+		}
+		return decoded, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	return v, nil
+}
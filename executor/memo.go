@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"unsafe"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// SetMemoization enables or disables memoizing field resolution within an
+// operation. When enabled, a field selected more than once on the same
+// source with the same arguments, for example under different aliases, is
+// resolved only once. Sources are identified by pointer, so fields of
+// non-pointer struct values are not memoized, nor are mutation root
+// fields, which may have side effects.
+func (e *Executor) SetMemoization(enabled bool) {
+	e.memoize = enabled
+}
+
+// memoKey identifies a field resolution within an operation.
+type memoKey struct {
+	typeName string
+	field    string
+	args     string         // arguments encoded as JSON, with sorted keys
+	source   unsafe.Pointer // identity of the source; nil for root fields
+}
+
+// memoResult is the outcome of a memoized field resolution.
+type memoResult struct {
+	value interface{}
+	err   error
+}
+
+// memoized returns the result of resolve for field of source, an object
+// of the named type, calling resolve only if the same field has not been
+// resolved on source with the same arguments before. path is the response
+// path of this selection, which errors are reported at.
+func (e *Executor) memoized(ex *execution, source interface{}, typeName string, field *ast.Field, path []interface{}, resolve func() (interface{}, error)) (interface{}, error) {
+	if !e.memoize {
+		return resolve()
+	}
+	key, ok := ex.memoKey(source, typeName, field)
+	if !ok {
+		return resolve()
+	}
+	if r, ok := ex.memo[key]; ok {
+		var gqlErr *Error
+		if errors.As(r.err, &gqlErr) {
+			moved := *gqlErr
+			moved.Path = append([]interface{}(nil), path...)
+			return nil, &moved
+		}
+		return r.value, r.err
+	}
+	value, err := resolve()
+	if ex.memo == nil {
+		ex.memo = make(map[memoKey]memoResult)
+	}
+	ex.memo[key] = memoResult{value, err}
+	return value, err
+}
+
+// memoKey returns the memoization key of field of source, or false if the
+// resolution must not be memoized.
+func (ex *execution) memoKey(source interface{}, typeName string, field *ast.Field) (memoKey, bool) {
+	key := memoKey{typeName: typeName, field: field.Name}
+	if source == nil {
+		if ex.operation == "mutation" {
+			return key, false
+		}
+	} else {
+		val := reflect.ValueOf(source)
+		if val.Kind() != reflect.Ptr && val.Kind() != reflect.Map {
+			return key, false
+		}
+		key.source = val.UnsafePointer()
+	}
+	args, err := json.Marshal(buildArgs(field, ex.variables))
+	if err != nil {
+		return key, false
+	}
+	key.args = string(args)
+	return key, true
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	argsType    = reflect.TypeOf(map[string]interface{}(nil))
+)
+
+// findMethod returns the exported method of source whose name matches the
+// field name case-insensitively and whose signature callMethod supports.
+// Methods with pointer receivers are found on struct values too.
+func findMethod(source interface{}, name string) (reflect.Value, bool) {
+	val := reflect.ValueOf(source)
+	if val.Kind() == reflect.Struct {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		val = ptr
+	}
+	typ := val.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if strings.EqualFold(m.Name, name) && resolverMethod(m.Type) {
+			return val.Method(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// resolverMethod reports whether a method type, including its receiver,
+// takes an optional context.Context followed by optional arguments map and
+// returns a value optionally followed by an error.
+func resolverMethod(t reflect.Type) bool {
+	in := 1 // skip the receiver
+	if in < t.NumIn() && t.In(in) == contextType {
+		in++
+	}
+	if in < t.NumIn() && t.In(in) == argsType {
+		in++
+	}
+	if in != t.NumIn() {
+		return false
+	}
+	switch t.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return t.Out(1) == errorType
+	}
+	return false
+}
+
+// callMethod calls a method accepted by resolverMethod with the arguments
+// it takes.
+func callMethod(ctx context.Context, method reflect.Value, args map[string]interface{}) (interface{}, error) {
+	t := method.Type()
+	in := make([]reflect.Value, t.NumIn())
+	for i := range in {
+		if t.In(i) == contextType {
+			in[i] = reflect.ValueOf(&ctx).Elem()
+		} else {
+			in[i] = reflect.ValueOf(args)
+		}
+	}
+	out := method.Call(in)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
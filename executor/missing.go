@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MissingFieldPolicy controls what happens when a selected field does not
+// exist on the source value being resolved.
+type MissingFieldPolicy int
+
+const (
+	// MissingFieldError reports a field error, which nulls the field.
+	MissingFieldError MissingFieldPolicy = iota
+	// MissingFieldNull resolves the field to null.
+	MissingFieldNull
+	// MissingFieldNullWithWarning resolves the field to null and adds a
+	// warning naming its path to the response's extensions.warnings.
+	MissingFieldNullWithWarning
+)
+
+// SetMissingFieldPolicy sets how fields absent on their source are handled.
+// Resolving them to null is useful while a schema is rolled out ahead of the
+// Go types that back it.
+func (e *Executor) SetMissingFieldPolicy(policy MissingFieldPolicy) {
+	e.missingFieldPolicy = policy
+}
+
+// missingFieldError reports a field that is absent on its source.
+type missingFieldError struct {
+	field string
+}
+
+func (err *missingFieldError) Error() string {
+	return fmt.Sprintf("no resolver found for field %s via reflection", err.field)
+}
+
+// warningsKey is the context key for the execution's warnings.
+type warningsKey struct{}
+
+// warnings collects the non-fatal problems of a single execution.
+type warnings struct {
+	mu   sync.Mutex
+	list []map[string]interface{}
+}
+
+// warningsFromContext returns the execution's warnings, or nil.
+func warningsFromContext(ctx context.Context) *warnings {
+	w, _ := ctx.Value(warningsKey{}).(*warnings)
+	return w
+}
+
+// add records a warning for the field at path. It is safe on a nil receiver.
+func (w *warnings) add(path []interface{}, message string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, map[string]interface{}{
+		"message": message,
+		"path":    append([]interface{}(nil), path...),
+	})
+}
+
+// isMissingField reports whether err is a missingFieldError. It is kept out
+// of line so that the target of errors.As only escapes on the error path.
+func isMissingField(err error) bool {
+	var missing *missingFieldError
+	return errors.As(err, &missing)
+}
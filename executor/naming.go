@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldNaming selects how struct fields are matched to GraphQL field names
+// when resolving fields by reflection. Names are always compared
+// case-insensitively. Under every strategy a `graphql` tag takes precedence:
+// `graphql:"name"` makes the field answer to that name only, and
+// `graphql:"-"` hides it.
+type FieldNaming int
+
+const (
+	// FieldNamingDefault matches the Go field name or its `json` tag.
+	FieldNamingDefault FieldNaming = iota
+	// FieldNamingCamelCase also accepts the camelCase form of snake_case
+	// `json` tags, so `json:"user_id"` answers to "userId".
+	FieldNamingCamelCase
+	// FieldNamingSnakeCase also accepts the snake_case form of the Go field
+	// name, so UserID answers to "user_id".
+	FieldNamingSnakeCase
+)
+
+// FieldNameMapper returns the GraphQL names a struct field answers to.
+// Names are compared case-insensitively; returning no names hides the field.
+type FieldNameMapper func(goField reflect.StructField) []string
+
+// SetFieldNaming sets the strategy used to match struct fields to GraphQL
+// field names.
+func (e *Executor) SetFieldNaming(naming FieldNaming) {
+	e.fieldNaming = naming
+	e.fieldIndex.Clear()
+}
+
+// SetFieldNameMapper installs a custom mapping from struct fields to GraphQL
+// names, replacing the field naming strategy. This lets generated or legacy
+// structs (e.g. protobuf messages) serve a clean schema. A nil mapper
+// restores the strategy set with SetFieldNaming.
+func (e *Executor) SetFieldNameMapper(mapper FieldNameMapper) {
+	e.fieldNameMapper = mapper
+	e.fieldIndex.Clear()
+}
+
+// fieldNameMapperOrDefault returns the mapper in effect for reflection resolution.
+func (e *Executor) fieldNameMapperOrDefault() FieldNameMapper {
+	if e.fieldNameMapper != nil {
+		return e.fieldNameMapper
+	}
+	return e.fieldNaming.fieldNames
+}
+
+// structFields returns the index of the struct field each lowercased
+// GraphQL name resolves to on typ. The lookup is built once per type, so
+// resolving a field of every item in a large list costs a map access
+// rather than a scan of the struct's fields and tags.
+func (e *Executor) structFields(typ reflect.Type) map[string]int {
+	if index, ok := e.fieldIndex.Load(typ); ok {
+		return index.(map[string]int)
+	}
+	fieldNames := e.fieldNameMapperOrDefault()
+	index := make(map[string]int)
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		for _, name := range fieldNames(sf) {
+			// The first field answering to a name wins.
+			if _, ok := index[strings.ToLower(name)]; !ok {
+				index[strings.ToLower(name)] = i
+			}
+		}
+	}
+	cached, _ := e.fieldIndex.LoadOrStore(typ, index)
+	return cached.(map[string]int)
+}
+
+// fieldNames returns the GraphQL names the struct field sf answers to.
+func (n FieldNaming) fieldNames(sf reflect.StructField) []string {
+	if t, ok := sf.Tag.Lookup("graphql"); ok {
+		switch name := strings.Split(t, ",")[0]; name {
+		case "-":
+			return nil
+		case "":
+		default:
+			return []string{name}
+		}
+	}
+	names := []string{sf.Name}
+	tag := ""
+	if t, ok := sf.Tag.Lookup("json"); ok {
+		tag = strings.Split(t, ",")[0]
+		if tag != "" && tag != "-" {
+			names = append(names, tag)
+		}
+	}
+	switch n {
+	case FieldNamingCamelCase:
+		if strings.Contains(tag, "_") {
+			names = append(names, camelCase(tag))
+		}
+	case FieldNamingSnakeCase:
+		names = append(names, snakeCase(sf.Name))
+	}
+	return names
+}
+
+// camelCase converts a snake_case name to camelCase: "user_id" -> "userId".
+func camelCase(s string) string {
+	var b strings.Builder
+	upper := false
+	for i, r := range s {
+		switch {
+		case r == '_':
+			upper = i > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping initialisms
+// together: "UserID" -> "user_id", "HTTPServer" -> "http_server".
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// OrderedMap is a response object that marshals its fields to JSON in
+// selection order rather than sorted by key.
+type OrderedMap struct {
+	Keys   []string               // response keys in selection order
+	Values map[string]interface{} // values by response key
+}
+
+// SetOrderedFields controls whether response objects are *OrderedMap
+// values, so their fields are encoded in the order the query selects them,
+// as the specification recommends. By default objects are plain maps,
+// which encoding/json sorts by key.
+func (e *Executor) SetOrderedFields(enabled bool) {
+	e.orderedFields = enabled
+}
+
+// Get returns the value of the field with the given response key.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.Values[key]
+	return value, ok
+}
+
+// MarshalJSON encodes the fields in the order of Keys.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/validator"
+)
+
+// PreparedQuery is an operation prepared by Executor.Prepare for repeated
+// execution. It is safe for concurrent use.
+type PreparedQuery struct {
+	executor  *Executor
+	operation *ast.OperationDefinition
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// Prepare selects the operation named operationName in doc, or its first
+// operation if operationName is empty, and does the work that does not
+// depend on variables once: it validates doc against the schema, if one is
+// set, indexes its fragments and checks the depth limit. The returned query
+// can be executed any number of times; doc must not be modified meanwhile.
+func (e *Executor) Prepare(doc *ast.Document, operationName string) (*PreparedQuery, error) {
+	if e.schema != nil {
+		if errs := validator.Validate(e.schema, doc); len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+	}
+	return e.prepare(doc, operationName)
+}
+
+// prepare is Prepare without validation.
+func (e *Executor) prepare(doc *ast.Document, operationName string) (*PreparedQuery, error) {
+	if len(doc.Definitions) == 0 {
+		return nil, fmt.Errorf("no definitions found")
+	}
+	q := &PreparedQuery{executor: e, fragments: make(map[string]*ast.FragmentDefinition)}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if q.operation == nil && (operationName == "" || def.Name == operationName) {
+				q.operation = def
+			}
+		case *ast.FragmentDefinition:
+			q.fragments[def.Name] = def
+		}
+	}
+	switch {
+	case q.operation == nil && operationName != "":
+		return nil, fmt.Errorf("unknown operation %q", operationName)
+	case q.operation == nil:
+		return nil, fmt.Errorf("unsupported definition type")
+	case q.operation.Operation == "subscription":
+		return nil, fmt.Errorf("subscription operations must be executed with ExecuteSubscription")
+	}
+	if e.maxDepth > 0 {
+		if err := checkDepth(q.operation, q.fragments, e.maxDepth); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// Operation returns the prepared operation.
+func (q *PreparedQuery) Operation() *ast.OperationDefinition {
+	return q.operation
+}
+
+// Execute executes the query with the given variables.
+func (q *PreparedQuery) Execute(variables map[string]interface{}) (map[string]interface{}, error) {
+	return q.ExecuteContext(context.Background(), variables)
+}
+
+// ExecuteContext executes the query within ctx with the given variables, as
+// Executor.ExecuteContext does.
+func (q *PreparedQuery) ExecuteContext(ctx context.Context, variables map[string]interface{}) (map[string]interface{}, error) {
+	e := q.executor
+	tracer := e.tracerFor(ctx)
+	ex := &execution{fragments: q.fragments, possibleTypes: e.possibleTypes, tracer: tracer}
+	ctx, finish := timed(tracer.StartExecute(ctx, q.operation))
+	response, err := e.executeOperation(ctx, q.operation, ex, variables)
+	finish(err)
+	return response, err
+}
@@ -0,0 +1,11 @@
+package executor
+
+// SetFilterRawJSON controls how json.RawMessage values returned by resolvers
+// are treated when the field has a selection set. By default the raw JSON is
+// embedded in the response verbatim, avoiding a decode and re-encode. When
+// filtering is enabled the JSON is decoded and the selection set is applied
+// to it like to a map returned by a resolver. Raw JSON at leaf positions is
+// always embedded verbatim.
+func (e *Executor) SetFilterRawJSON(enabled bool) {
+	e.filterRawJSON = enabled
+}
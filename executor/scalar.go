@@ -0,0 +1,242 @@
+package executor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// Scalar implements a custom scalar type. Serialize converts the values
+// resolvers return to their response representation, and ParseValue
+// converts argument values, from literals or variables, to the Go values
+// resolvers receive. A nil function leaves values as they are; an error
+// from either is a field error.
+type Scalar struct {
+	Serialize  func(v interface{}) (interface{}, error)
+	ParseValue func(v interface{}) (interface{}, error)
+}
+
+// RegisterScalar installs the implementation of the scalar type name,
+// replacing any built-in one. ID and Float are built in, as are DateTime,
+// UUID, JSON, Int64 and BigInt when the schema declares them. Scalars
+// require a schema set with SetSchema that declares the argument and field
+// types.
+func (e *Executor) RegisterScalar(name string, scalar Scalar) {
+	if e.scalars == nil {
+		e.scalars = make(map[string]Scalar)
+	}
+	e.scalars[name] = scalar
+}
+
+// specScalars are the built-in scalars of every schema that need converting.
+var specScalars = map[string]Scalar{
+	"ID":    {Serialize: serializeID},
+	"Float": {Serialize: serializeFloat},
+}
+
+// knownScalars are the scalars implemented when the schema declares them:
+// DateTime maps RFC 3339 strings to time.Time, UUID accepts canonical
+// UUID strings and normalizes them to lower case, and JSON passes any
+// value through. Int64 and BigInt map integers to int64 and *big.Int and
+// are serialized as decimal strings, as JSON numbers lose precision beyond
+// 2^53.
+var knownScalars = map[string]Scalar{
+	"DateTime": {Serialize: serializeDateTime, ParseValue: parseDateTime},
+	"UUID":     {Serialize: parseUUID, ParseValue: parseUUID},
+	"JSON":     {},
+	"Int64":    {Serialize: serializeInt64, ParseValue: parseInt64},
+	"BigInt":   {Serialize: serializeBigInt, ParseValue: parseBigInt},
+}
+
+// scalar returns the implementation of the scalar type name: a registered
+// one, or a built-in one if name is a spec scalar or declared by the schema.
+func (e *Executor) scalar(name string) (Scalar, bool) {
+	if s, ok := e.scalars[name]; ok {
+		return s, true
+	}
+	if s, ok := specScalars[name]; ok {
+		return s, true
+	}
+	if e.declaredScalars[name] {
+		return knownScalars[name], true
+	}
+	return Scalar{}, false
+}
+
+// declaredScalars returns the names of the known scalars schema declares.
+func declaredScalars(schema *ast.Document) map[string]bool {
+	declared := make(map[string]bool)
+	for _, def := range schema.Definitions {
+		if s, ok := def.(*ast.ScalarTypeDefinition); ok {
+			if _, known := knownScalars[s.Name]; known {
+				declared[s.Name] = true
+			}
+		}
+	}
+	return declared
+}
+
+// serializeID renders integers as strings; other values are unchanged.
+func serializeID(v interface{}) (interface{}, error) {
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	}
+	return v, nil
+}
+
+// serializeFloat converts numbers to float64, rejecting NaN and infinities;
+// other values are unchanged.
+func serializeFloat(v interface{}) (interface{}, error) {
+	var f float64
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f = val.Float()
+	default:
+		return v, nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("Float cannot represent %v", f)
+	}
+	return f, nil
+}
+
+// serializeInt64 formats an integer within the int64 range as a string.
+func serializeInt64(v interface{}) (interface{}, error) {
+	n, err := parseInt64(v)
+	if err != nil {
+		return nil, err
+	}
+	return strconv.FormatInt(n.(int64), 10), nil
+}
+
+// parseInt64 converts an integer given as a Go integer, an exact float64,
+// or a decimal string or json.Number to an int64.
+func parseInt64(v interface{}) (interface{}, error) {
+	n, err := parseBigInt(v)
+	if err != nil || !n.(*big.Int).IsInt64() {
+		return nil, fmt.Errorf("Int64 cannot represent %v", v)
+	}
+	return n.(*big.Int).Int64(), nil
+}
+
+// serializeBigInt formats an integer as a string.
+func serializeBigInt(v interface{}) (interface{}, error) {
+	n, err := parseBigInt(v)
+	if err != nil {
+		return nil, err
+	}
+	return n.(*big.Int).String(), nil
+}
+
+// parseBigInt converts an integer given as a Go integer, a big.Int, an
+// exact float64, or a decimal string or json.Number to a *big.Int.
+func parseBigInt(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case *big.Int:
+		return n, nil
+	case big.Int:
+		return &n, nil
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) <= 1<<53 {
+			return big.NewInt(int64(n)), nil
+		}
+	case string:
+		if i, ok := new(big.Int).SetString(n, 10); ok {
+			return i, nil
+		}
+	case json.Number:
+		if i, ok := new(big.Int).SetString(n.String(), 10); ok {
+			return i, nil
+		}
+	default:
+		switch val := reflect.ValueOf(v); val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return big.NewInt(val.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return new(big.Int).SetUint64(val.Uint()), nil
+		}
+	}
+	return nil, fmt.Errorf("BigInt cannot represent %v", v)
+}
+
+// serializeDateTime formats times as RFC 3339 strings; strings must already
+// be in that format.
+func serializeDateTime(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	case *time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	case string:
+		if _, err := time.Parse(time.RFC3339, t); err != nil {
+			return nil, fmt.Errorf("DateTime cannot represent %q", t)
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("DateTime cannot represent %T", v)
+}
+
+// parseDateTime converts an RFC 3339 string to a time.Time.
+func parseDateTime(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an RFC 3339 DateTime", t)
+		}
+		return parsed, nil
+	}
+	return nil, fmt.Errorf("%v is not an RFC 3339 DateTime", v)
+}
+
+// parseUUID validates a UUID given as a string or 16 bytes and returns it
+// in canonical lower case form.
+func parseUUID(v interface{}) (interface{}, error) {
+	switch u := v.(type) {
+	case [16]byte:
+		s := hex.EncodeToString(u[:])
+		return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+	case string:
+		if isUUID(u) {
+			return strings.ToLower(u), nil
+		}
+	}
+	return nil, fmt.Errorf("%v is not a valid UUID", v)
+}
+
+// isUUID reports whether s has the 8-4-4-4-12 hexadecimal UUID form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package executor
+
+import "github.com/Protocol-Lattice/graphql/ast"
+
+// SetSchema attaches the schema, parsed from SDL, that this executor serves.
+// Resolvers remain registered separately; the schema is used by tooling such
+// as the SDL endpoint, to match fragments on interfaces and unions, and to
+// coerce arguments to their declared types. Declaring scalar DateTime, UUID,
+// JSON, Int64 or BigInt enables its built-in implementation (see
+// RegisterScalar).
+func (e *Executor) SetSchema(schema *ast.Document) {
+	e.schema = schema
+	e.possibleTypes, e.fields, e.inputTypes, e.declaredScalars = nil, nil, nil, nil
+	if schema == nil {
+		return
+	}
+	if merged, err := ast.MergeExtensions(schema); err == nil {
+		schema = merged
+	}
+	e.possibleTypes = possibleTypes(schema)
+	e.fields = schemaFields(schema)
+	e.inputTypes = inputTypes(schema)
+	e.declaredScalars = declaredScalars(schema)
+}
+
+// Schema returns the schema set with SetSchema, or nil if none is set.
+func (e *Executor) Schema() *ast.Document {
+	return e.schema
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+)
+
+// Thunk is a field value computed on demand. A resolver returning a Thunk,
+// a func() (interface{}, error) or any func() (T, error), such as the
+// thunks of dataloader.Loader.LoadThunk, lets the executor call the
+// resolvers of the field's siblings and cousins before blocking on it, so
+// the loads they schedule share a batch.
+type Thunk func() (interface{}, error)
+
+// forceThunks calls value while it is a thunk and returns the result. The
+// calls are bounded by the timeouts like resolvers and fail at path.
+func (e *Executor) forceThunks(ctx context.Context, ex *execution, value interface{}, path []interface{}) (interface{}, error) {
+	for {
+		thunk, ok := thunkOf(value)
+		if !ok {
+			return value, nil
+		}
+		var err error
+		value, err = e.resolveTimed(ctx, ex, path, func(context.Context) (interface{}, error) {
+			return thunk()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// thunkOf returns value as a Thunk if it is a function without parameters
+// returning a value and an error.
+func thunkOf(value interface{}) (Thunk, bool) {
+	switch fn := value.(type) {
+	case Thunk:
+		return fn, fn != nil
+	case func() (interface{}, error):
+		return fn, fn != nil
+	}
+	fn := reflect.ValueOf(value)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, false
+	}
+	t := fn.Type()
+	if t.NumIn() != 0 || t.NumOut() != 2 || t.Out(1) != errorType {
+		return nil, false
+	}
+	return func() (interface{}, error) {
+		out := fn.Call(nil)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}, true
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrExecutionTimeout is the field error reported when an operation
+	// runs past the timeout set with SetTimeout.
+	ErrExecutionTimeout = errors.New("execution timed out")
+	// ErrFieldTimeout is the field error reported when a resolver runs past
+	// the timeout set with SetFieldTimeout.
+	ErrFieldTimeout = errors.New("field timed out")
+)
+
+// SetTimeout bounds the execution of each operation. When it is exceeded
+// the context passed to resolvers is canceled, the remaining fields are
+// not resolved and null, and an ErrExecutionTimeout field error is
+// reported. Zero, the default, disables the timeout.
+func (e *Executor) SetTimeout(d time.Duration) {
+	e.timeout = d
+}
+
+// SetFieldTimeout bounds each resolver call. A resolver that runs past it
+// has its context canceled and its field fails with ErrFieldTimeout. Zero,
+// the default, disables the timeout.
+func (e *Executor) SetFieldTimeout(d time.Duration) {
+	e.fieldTimeout = d
+}
+
+// resolveTimed calls fn for the field at path like resolve. When timeouts
+// are set, fn runs in its own goroutine so that a resolver that ignores its
+// context cannot hold up the response past the deadline, unless the
+// goroutine limits of ex are reached.
+func (e *Executor) resolveTimed(ctx context.Context, ex *execution, path []interface{}, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	if e.timeout <= 0 && e.fieldTimeout <= 0 {
+		return e.resolve(path, func() (interface{}, error) { return fn(ctx) })
+	}
+	return e.resolveAsync(ctx, ex, path, fn)
+}
+
+// resolveAsync is the part of resolveTimed that runs fn under the timeouts.
+// It is separate so that the context of the untimed path does not escape.
+func (e *Executor) resolveAsync(ctx context.Context, ex *execution, path []interface{}, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	if e.fieldTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, e.fieldTimeout, ErrFieldTimeout)
+		defer cancel()
+	}
+	type result struct {
+		res interface{}
+		err error
+	}
+	done := make(chan result, 1)
+	if release, ok := e.startWorker(ex); ok {
+		go func() {
+			defer release()
+			res, err := e.resolve(path, func() (interface{}, error) { return fn(ctx) })
+			done <- result{res, err}
+		}()
+	} else {
+		res, err := e.resolve(path, func() (interface{}, error) { return fn(ctx) })
+		done <- result{res, err}
+	}
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(r.err, context.DeadlineExceeded) && isTimeout(context.Cause(ctx)) {
+			return nil, e.newError(path, context.Cause(ctx), nil)
+		}
+		return r.res, r.err
+	case <-ctx.Done():
+		return nil, e.newError(path, context.Cause(ctx), nil)
+	}
+}
+
+// isTimeout reports whether err is one of the executor's timeout errors.
+func isTimeout(err error) bool {
+	return errors.Is(err, ErrExecutionTimeout) || errors.Is(err, ErrFieldTimeout)
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// Tracer observes the phases of requests, for logging, metrics or APM
+// integrations. Each Start method is called when a phase begins and may
+// return a derived context, for example carrying a span, which is used for
+// the rest of the phase; the returned FinishFunc is called when the phase
+// ends. Tracers are called concurrently and must be safe for concurrent
+// use. Embed NopTracer to implement only some of the methods.
+type Tracer interface {
+	// StartParse is called before a request's document is parsed.
+	StartParse(ctx context.Context) (context.Context, FinishFunc)
+	// StartValidate is called before a document is validated.
+	StartValidate(ctx context.Context) (context.Context, FinishFunc)
+	// StartExecute is called before an operation is executed. The error
+	// it finishes with is the one returned by ExecuteContext; field
+	// errors are reported to StartField's FinishFunc.
+	StartExecute(ctx context.Context, op *ast.OperationDefinition) (context.Context, FinishFunc)
+	// StartField is called before a field's resolver. Its FinishFunc
+	// receives the resolver's error.
+	StartField(ctx context.Context, info *ResolveInfo) (context.Context, FinishFunc)
+}
+
+// FinishFunc ends a traced phase that took d, with its error if it failed.
+type FinishFunc func(d time.Duration, err error)
+
+// NopTracer is a Tracer that does nothing.
+type NopTracer struct{}
+
+func nopFinish(time.Duration, error) {}
+
+// StartParse returns ctx and a FinishFunc that does nothing.
+func (NopTracer) StartParse(ctx context.Context) (context.Context, FinishFunc) {
+	return ctx, nopFinish
+}
+
+// StartValidate returns ctx and a FinishFunc that does nothing.
+func (NopTracer) StartValidate(ctx context.Context) (context.Context, FinishFunc) {
+	return ctx, nopFinish
+}
+
+// StartExecute returns ctx and a FinishFunc that does nothing.
+func (NopTracer) StartExecute(ctx context.Context, op *ast.OperationDefinition) (context.Context, FinishFunc) {
+	return ctx, nopFinish
+}
+
+// StartField returns ctx and a FinishFunc that does nothing.
+func (NopTracer) StartField(ctx context.Context, info *ResolveInfo) (context.Context, FinishFunc) {
+	return ctx, nopFinish
+}
+
+// SetTracer installs the tracer observing the executor's requests. A nil
+// tracer, the default, disables tracing.
+func (e *Executor) SetTracer(tracer Tracer) {
+	e.tracer = tracer
+}
+
+// tracerKey is the context key for a tracer set with WithTracer.
+type tracerKey struct{}
+
+// WithTracer returns a context whose requests are traced by tracer instead
+// of the executor's tracer, such as for a single HTTP handler.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// tracerFor returns the tracer in effect for ctx.
+func (e *Executor) tracerFor(ctx context.Context) Tracer {
+	if tracer, ok := ctx.Value(tracerKey{}).(Tracer); ok && tracer != nil {
+		return tracer
+	}
+	if e.tracer != nil {
+		return e.tracer
+	}
+	return NopTracer{}
+}
+
+// TraceParse starts tracing the parsing of a document by code outside of
+// the executor, such as the HTTP handlers. Call the returned function with
+// the parse error, if any, once parsing is done.
+func (e *Executor) TraceParse(ctx context.Context) (context.Context, func(err error)) {
+	return timed(e.tracerFor(ctx).StartParse(ctx))
+}
+
+// TraceValidate starts tracing the validation of a document like
+// TraceParse.
+func (e *Executor) TraceValidate(ctx context.Context) (context.Context, func(err error)) {
+	return timed(e.tracerFor(ctx).StartValidate(ctx))
+}
+
+// timed returns ctx and a function calling finish with the time elapsed
+// since timed was called.
+func timed(ctx context.Context, finish FinishFunc) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		finish(time.Since(start), err)
+	}
+}
@@ -0,0 +1,23 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Protocol-Lattice/graphql/variables"
+)
+
+// Typed adapts a resolver taking its arguments as a Go value to a
+// ContextResolverFunc. Arguments are decoded into Args with
+// variables.Unmarshal, so Args is usually a struct whose fields are named
+// like the arguments, or map[string]interface{}; use struct{} for fields
+// without arguments. Decoding failures are field errors.
+func Typed[Args, Result any](fn func(ctx context.Context, args Args) (Result, error)) ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		var typed Args
+		if err := variables.Unmarshal(args, &typed); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		return fn(ctx, typed)
+	}
+}
@@ -0,0 +1,48 @@
+package executor
+
+// TypeNamer is implemented by values that know the name of their GraphQL
+// object type. It takes precedence over the Go type name when resolving
+// __typename and matching fragment type conditions, so a single Go type
+// can back several GraphQL types, or a type named differently.
+type TypeNamer interface {
+	TypeName() string
+}
+
+// TypeResolver returns the name of the concrete object type of a value
+// whose declared type is an interface or union, or "" if it cannot tell.
+type TypeResolver func(value interface{}) string
+
+// RegisterTypeResolver installs the resolver that determines the concrete
+// type of values whose declared type is the named interface or union. The
+// concrete type decides which fragments apply and the value of __typename;
+// it takes precedence over TypeNamer and the Go type name. Declared types
+// are read from the schema set with SetSchema.
+func (e *Executor) RegisterTypeResolver(abstractType string, resolver TypeResolver) {
+	if e.typeResolvers == nil {
+		e.typeResolvers = make(map[string]TypeResolver)
+	}
+	e.typeResolvers[abstractType] = resolver
+}
+
+// typeNameField is the meta field selectable on every object type.
+const typeNameField = "__typename"
+
+// resolveTypeName returns the value of __typename for source, an object of
+// the named type: the name reported by a TypeNamer, else typeName unless
+// it is a union or interface, else the name of source's struct type.
+func (e *Executor) resolveTypeName(source interface{}, typeName string) string {
+	if namer, ok := source.(TypeNamer); ok {
+		return namer.TypeName()
+	}
+	if typeName != "" && !e.isAbstract(typeName) {
+		return typeName
+	}
+	return objectTypeName(source)
+}
+
+// isAbstract reports whether the schema declares the named type as a union
+// or an interface.
+func (e *Executor) isAbstract(typeName string) bool {
+	_, ok := e.possibleTypes[typeName]
+	return ok
+}
@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/validator"
+)
+
+// SetValidation controls whether Execute and ExecuteContext validate
+// documents against the schema set with SetSchema before executing them,
+// as Prepare always does. Invalid documents fail without resolving any
+// field, with every violation joined in the returned error; unknown fields,
+// types and arguments and missing or extra selections are reported instead
+// of failing mid-execution. It has no effect without a schema.
+func (e *Executor) SetValidation(enabled bool) {
+	e.validate = enabled
+}
+
+// validateDocument validates doc against the schema if validation is
+// enabled, tracing it with the tracer.
+func (e *Executor) validateDocument(ctx context.Context, doc *ast.Document) error {
+	if !e.validate || e.schema == nil {
+		return nil
+	}
+	_, finish := e.TraceValidate(ctx)
+	start := time.Now()
+	err := errors.Join(validator.Validate(e.schema, doc)...)
+	DebugFromContext(ctx).RecordValidate(time.Since(start))
+	finish(err)
+	return err
+}
@@ -0,0 +1,16 @@
+// Package format renders GraphQL documents in a canonical style so that
+// teams can enforce a single layout for schemas and operations.
+package format
+
+// Options controls formatting.
+type Options struct {
+	// Indent is the string used for one level of indentation.
+	// It defaults to two spaces.
+	Indent string
+	// SortFields orders schema fields and arguments alphabetically instead
+	// of keeping their declaration order.
+	SortFields bool
+	// LineWidth is the width beyond which argument and variable lists in
+	// operations are wrapped one per line. It defaults to 80.
+	LineWidth int
+}
@@ -0,0 +1,12 @@
+package format
+
+import "github.com/Protocol-Lattice/graphql/ast"
+
+// Query renders the operations and fragments in doc as canonical GraphQL
+// source. Anonymous queries without variables use the shorthand "{ ... }"
+// form. Argument and variable lists are kept on one line unless that line
+// would exceed Options.LineWidth, in which case each entry goes on its own
+// line. Type definitions in doc are ignored.
+func Query(doc *ast.Document, opts Options) string {
+	return ast.QueryPrinter{Indent: opts.Indent, LineWidth: opts.LineWidth}.Print(doc)
+}
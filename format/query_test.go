@@ -0,0 +1,82 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{"shorthand", `{hello}`, Options{}, "{\n  hello\n}\n"},
+		{"variable defaults", `query Q($first: Int = 10, $tags: [String] = ["a"]) { users(first: $first) { id } }`, Options{}, `query Q($first: Int = 10, $tags: [String] = ["a"]) {
+  users(first: $first) {
+    id
+  }
+}
+`},
+		{"named with variables", `query Q($id: ID!){user(id:$id,filter:{b:1,a:"x"}){name}}`, Options{}, `query Q($id: ID!) {
+  user(id: $id, filter: {a: "x", b: 1}) {
+    name
+  }
+}
+`},
+		{"wrapped arguments", `{ users(first: 10, after: "abc") { id } }`, Options{LineWidth: 20}, `{
+  users(
+    first: 10
+    after: "abc"
+  ) {
+    id
+  }
+}
+`},
+		{"fragments", `{ user { ...UserFields } } fragment UserFields on User { id name }`, Options{}, `{
+  user {
+    ...UserFields
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+}
+`},
+		{"inline fragments", `{ members { ... { name } ... on Admin { permissions } } }`, Options{}, `{
+  members {
+    ... {
+      name
+    }
+    ... on Admin {
+      permissions
+    }
+  }
+}
+`},
+		{"directives", `query Q($x: Boolean) @live { a @include(if: $x) ...F @skip(if: true) ... @include(if: false) { b } } fragment F on T @tag { c }`, Options{}, `query Q($x: Boolean) @live {
+  a @include(if: $x)
+  ...F @skip(if: true)
+  ... @include(if: false) {
+    b
+  }
+}
+
+fragment F on T @tag {
+  c
+}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parser.New(lexer.New(tt.input)).ParseDocument()
+			if got := Query(doc, tt.opts); got != tt.want {
+				t.Errorf("unexpected output:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package format
+
+import "github.com/Protocol-Lattice/graphql/ast"
+
+// Schema renders the type definitions in doc as canonical SDL: one blank
+// line between definitions, one field per line, and arguments inline.
+// Operation definitions in doc are ignored.
+func Schema(doc *ast.Document, opts Options) string {
+	return ast.SchemaPrinter{Indent: opts.Indent, SortFields: opts.SortFields}.Print(doc)
+}
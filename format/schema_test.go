@@ -0,0 +1,290 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestSchema(t *testing.T) {
+	input := `type Query { users(limit: Int = 10, ids: [ID!]!): [User]  user(id: ID!): User }
+type User{id: ID! name: String}`
+	doc := parser.New(lexer.New(input)).ParseDocument()
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"declaration order", Options{}, `type Query {
+  users(limit: Int = 10, ids: [ID!]!): [User]
+  user(id: ID!): User
+}
+
+type User {
+  id: ID!
+  name: String
+}
+`},
+		{"sorted", Options{SortFields: true, Indent: "    "}, `type Query {
+    user(id: ID!): User
+    users(ids: [ID!]!, limit: Int = 10): [User]
+}
+
+type User {
+    id: ID!
+    name: String
+}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Schema(doc, tt.opts); got != tt.want {
+				t.Errorf("unexpected output:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaDirectives(t *testing.T) {
+	input := `type Query { users(limit: Int): [User] @cost(weight: 5, multipliers: ["limit"]) @beta }`
+	doc := parser.New(lexer.New(input)).ParseDocument()
+	want := `type Query {
+  users(limit: Int): [User] @cost(weight: 5, multipliers: ["limit"]) @beta
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaInputObjects(t *testing.T) {
+	input := `input UpdateUserInput { name: String!, tags: [String] = [], role: Role = USER }
+type Mutation { updateUser(id: ID!, input: UpdateUserInput!): User }`
+	p := parser.New(lexer.New(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `input UpdateUserInput {
+  name: String!
+  tags: [String] = []
+  role: Role = USER
+}
+
+type Mutation {
+  updateUser(id: ID!, input: UpdateUserInput!): User
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaEnums(t *testing.T) {
+	input := `enum Role { USER, ADMIN @deprecated(reason: "use USER") GUEST }`
+	p := parser.New(lexer.New(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `enum Role {
+  USER
+  ADMIN @deprecated(reason: "use USER")
+  GUEST
+}
+`
+	if got := Schema(doc, Options{SortFields: true}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	p = parser.New(lexer.New(`enum Bad { true }`))
+	p.ParseDocument()
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected an error for a reserved enum value, got %v", p.Errors())
+	}
+}
+
+func TestSchemaInterfaces(t *testing.T) {
+	input := `interface Node { id: ID! }
+interface Timestamped implements Node { id: ID! createdAt: String }
+type User implements & Node & Timestamped { id: ID! createdAt: String }`
+	p := parser.New(lexer.New(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	if got := doc.Definitions[2].(*ast.TypeDefinition).Interfaces; len(got) != 2 || got[0] != "Node" || got[1] != "Timestamped" {
+		t.Errorf("unexpected interfaces %v", got)
+	}
+	want := `interface Node {
+  id: ID!
+}
+
+interface Timestamped implements Node {
+  id: ID!
+  createdAt: String
+}
+
+type User implements Node & Timestamped {
+  id: ID!
+  createdAt: String
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaUnions(t *testing.T) {
+	p := parser.New(lexer.New("union SearchResult =\n  | User\n  | Post\ntype Query { search: [SearchResult] }"))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `union SearchResult = User | Post
+
+type Query {
+  search: [SearchResult]
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaScalars(t *testing.T) {
+	p := parser.New(lexer.New(`scalar DateTime @specifiedBy(url: "https://example.com/datetime") scalar JSON type Query { now: DateTime }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `scalar DateTime @specifiedBy(url: "https://example.com/datetime")
+
+scalar JSON
+
+type Query {
+  now: DateTime
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaDefinition(t *testing.T) {
+	p := parser.New(lexer.New(`schema { query: RootQuery, mutation: RootMutation } type RootQuery { a: Int }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `schema {
+  query: RootQuery
+  mutation: RootMutation
+}
+
+type RootQuery {
+  a: Int
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	for op, want := range map[string]string{"query": "RootQuery", "mutation": "RootMutation", "subscription": ""} {
+		if got := doc.RootTypeName(op); got != want {
+			t.Errorf("RootTypeName(%q) = %q, want %q", op, got, want)
+		}
+	}
+}
+
+func TestSchemaDirectiveDefinitions(t *testing.T) {
+	p := parser.New(lexer.New(`directive @auth(role: String! = "admin") on FIELD_DEFINITION | OBJECT
+directive @tag(name: String!) repeatable on | FIELD
+type Query { me: String @auth }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `directive @auth(role: String! = "admin") on FIELD_DEFINITION | OBJECT
+
+directive @tag(name: String!) repeatable on FIELD
+
+type Query {
+  me: String @auth
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	p = parser.New(lexer.New(`directive @x on NOWHERE`))
+	p.ParseDocument()
+	if errs := p.Errors(); len(errs) != 1 || errs[0] != "unknown directive location NOWHERE" {
+		t.Errorf("expected an unknown location error, got %v", errs)
+	}
+}
+
+func TestSchemaExtensions(t *testing.T) {
+	p := parser.New(lexer.New(`extend type Query { b: Int } extend union U = C extend type User implements Node`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `extend type Query {
+  b: Int
+}
+
+extend union U = C
+
+extend type User implements Node
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSchemaDescriptions(t *testing.T) {
+	p := parser.New(lexer.New(`"The root query."
+type Query {
+  """
+  Looks up a user.
+  Returns null if there is none.
+  """
+  user("The user ID." id: ID!): User
+}
+"A role." enum Role { "Full access." ADMIN USER }
+input Filter { "Maximum results." first: Int }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	want := `"The root query."
+type Query {
+  """
+  Looks up a user.
+  Returns null if there is none.
+  """
+  user(
+    "The user ID."
+    id: ID!
+  ): User
+}
+
+"A role."
+enum Role {
+  "Full access."
+  ADMIN
+  USER
+}
+
+input Filter {
+  "Maximum results."
+  first: Int
+}
+`
+	if got := Schema(doc, Options{}); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
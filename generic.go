@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/Protocol-Lattice/graphql/executor"
+)
+
+// Typed adapts a resolver taking its arguments as a Go value, such as a
+// struct with a field per argument, to a ContextResolverFunc for use with
+// an executor's Register*ResolverContext methods.
+func Typed[Args, Result any](fn func(ctx context.Context, args Args) (Result, error)) ContextResolverFunc {
+	return executor.Typed(fn)
+}
+
+// Query registers a typed query resolver in the global registry. Its
+// arguments are decoded into Args before fn is called:
+//
+//	type userArgs struct{ ID string }
+//
+//	graphql.Query("user", func(ctx context.Context, args userArgs) (*User, error) {
+//		return db.User(ctx, args.ID)
+//	})
+func Query[Args, Result any](name string, fn func(ctx context.Context, args Args) (Result, error)) {
+	RegisterQueryResolverContext(name, Typed(fn))
+}
+
+// Mutation registers a typed mutation resolver in the global registry.
+func Mutation[Args, Result any](name string, fn func(ctx context.Context, args Args) (Result, error)) {
+	RegisterMutationResolverContext(name, Typed(fn))
+}
+
+// Subscription registers a typed subscription resolver in the global
+// registry. Result must be a channel of interface{} or an iterator, as for
+// untyped subscription resolvers.
+func Subscription[Args, Result any](name string, fn func(ctx context.Context, args Args) (Result, error)) {
+	RegisterSubscriptionResolverContext(name, Typed(fn))
+}
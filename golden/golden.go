@@ -0,0 +1,160 @@
+// Package golden provides snapshot assertions for schemas and query
+// responses. Snapshots are compared against golden files and rewritten
+// when tests run with the -update-golden flag:
+//
+//	func TestUserQuery(t *testing.T) {
+//		result, _ := exec.Execute(doc, nil)
+//		golden.AssertResponse(t, "testdata/user.golden", result, golden.Redact("createdAt"))
+//	}
+//
+//	go test ./... -run TestUserQuery -update-golden
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/format"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite golden files with the current output")
+
+// Redacted replaces the values of redacted fields in snapshots.
+const Redacted = "<redacted>"
+
+// Option configures how a response is normalized before comparison.
+type Option func(*options)
+
+type options struct {
+	redact []string
+}
+
+// Redact replaces volatile values, such as timestamps or generated IDs,
+// with Redacted. A pattern without dots matches an object key at any
+// depth; a dotted pattern matches a full path such as "data.users.*.id",
+// where "*" matches any key or list index.
+func Redact(patterns ...string) Option {
+	return func(o *options) {
+		o.redact = append(o.redact, patterns...)
+	}
+}
+
+// AssertSchema compares the canonical SDL of schema with the golden file
+// at path.
+func AssertSchema(t testing.TB, path string, schema *ast.Document) {
+	t.Helper()
+	assert(t, path, []byte(format.Schema(schema, format.Options{})))
+}
+
+// AssertResponse compares a response (any JSON-encodable value, typically
+// the result of Execute) with the golden file at path. The response is
+// encoded as indented JSON with sorted object keys so snapshots are stable.
+func AssertResponse(t testing.TB, path string, response interface{}, opts ...Option) {
+	t.Helper()
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	data, err := normalize(response, o)
+	if err != nil {
+		t.Fatalf("golden: cannot encode response: %v", err)
+	}
+	assert(t, path, data)
+}
+
+// normalize encodes v as indented JSON with redactions applied.
+func normalize(v interface{}, o options) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	generic = redact(generic, nil, o.redact)
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// redact replaces the values at paths matching patterns.
+func redact(v interface{}, path []string, patterns []string) interface{} {
+	if len(path) > 0 && matches(path, patterns) {
+		return Redacted
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = redact(val, append(path, key), patterns)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val, append(path, strconv.Itoa(i)), patterns)
+		}
+	}
+	return v
+}
+
+// matches reports whether path matches any of patterns.
+func matches(path []string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, ".") {
+			if path[len(path)-1] == pattern {
+				return true
+			}
+			continue
+		}
+		segments := strings.Split(pattern, ".")
+		if len(segments) != len(path) {
+			continue
+		}
+		match := true
+		for i, seg := range segments {
+			if seg != "*" && seg != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// assert compares got with the golden file at path, rewriting it when
+// -update-golden is set.
+func assert(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden: %v (run with -update-golden to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("golden: output does not match %s (run with -update-golden to accept it)\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
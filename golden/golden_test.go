@@ -0,0 +1,34 @@
+package golden
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func TestAssertSchema(t *testing.T) {
+	doc := parser.New(lexer.New(`type Query { user(id: ID!): User } type User { id: ID! name: String }`)).ParseDocument()
+	AssertSchema(t, "testdata/schema.golden", doc)
+}
+
+func TestAssertResponse(t *testing.T) {
+	response := map[string]interface{}{
+		"data": map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "Ada", "id": "u-9f3a", "createdAt": "2024-05-01T10:00:00Z"},
+			},
+		},
+	}
+	AssertResponse(t, "testdata/response.golden", response, Redact("createdAt", "data.users.*.id"))
+}
+
+func TestUpdate(t *testing.T) {
+	*update = true
+	defer func() { *update = false }()
+	path := filepath.Join(t.TempDir(), "nested", "response.golden")
+	AssertResponse(t, path, map[string]interface{}{"b": 1, "a": 2.5})
+	*update = false
+	AssertResponse(t, path, map[string]interface{}{"a": 2.5, "b": 1})
+}
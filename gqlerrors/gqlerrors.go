@@ -0,0 +1,79 @@
+// Package gqlerrors defines the errors listed under "errors" in GraphQL
+// responses. Resolvers may return them to control the message, path and
+// extensions a client sees; the executor passes them through as they are,
+// only filling in the path and locations of the field when they are unset.
+//
+//	return nil, gqlerrors.WithCode(gqlerrors.Newf("user %s not found", id), "NOT_FOUND")
+package gqlerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Location is a position in the query document, as reported in responses.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error. It marshals to the "errors" entry format of a
+// GraphQL response.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Err is the underlying error, if any.
+	Err error `json:"-"`
+}
+
+// New returns an Error with the given message.
+func New(message string) *Error {
+	return &Error{Message: message}
+}
+
+// Newf returns an Error whose message is formatted as by fmt.Errorf. An
+// error operand of the %w verb becomes the underlying error.
+func Newf(format string, args ...interface{}) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// Wrap returns err as an *Error: err itself if it is one, or otherwise an
+// Error with err's message and err as the underlying error.
+func Wrap(err error) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return &Error{Message: err.Error(), Err: err}
+}
+
+// WithCode returns err as an *Error, as by Wrap, with code set as the "code"
+// extension.
+func WithCode(err error, code string) *Error {
+	return WithExtension(err, "code", code)
+}
+
+// WithExtension returns err as an *Error, as by Wrap, with the named
+// extension set to value.
+func WithExtension(err error, name string, value interface{}) *Error {
+	gqlErr := Wrap(err)
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = make(map[string]interface{})
+	}
+	gqlErr.Extensions[name] = value
+	return gqlErr
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,33 @@
+package gqlerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestNewfWrapsErrors(t *testing.T) {
+	err := Newf("reading user %s: %w", "42", io.EOF)
+	if err.Message != "reading user 42: EOF" {
+		t.Errorf("unexpected message %q", err.Message)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("expected the %w operand to be the underlying error")
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	plain := errors.New("forbidden")
+	err := WithCode(plain, "FORBIDDEN")
+	if err.Err != plain {
+		t.Error("expected the plain error to be wrapped")
+	}
+	if WithCode(err, "DENIED") != err {
+		t.Error("expected an *Error to be updated in place")
+	}
+	out, _ := json.Marshal(WithExtension(err, "retry", false))
+	if want := `{"message":"forbidden","extensions":{"code":"DENIED","retry":false}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
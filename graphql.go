@@ -3,13 +3,17 @@
 package graphql
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/Protocol-Lattice/graphql/ast"
 	"github.com/Protocol-Lattice/graphql/executor"
-	"github.com/Protocol-Lattice/graphql/handler"
 	"github.com/Protocol-Lattice/graphql/lexer"
 	"github.com/Protocol-Lattice/graphql/parser"
 	"github.com/Protocol-Lattice/graphql/registry"
 	"github.com/Protocol-Lattice/graphql/token"
+	"github.com/Protocol-Lattice/graphql/variables"
 )
 
 // ===========================
@@ -28,6 +32,7 @@ const (
 	EOF       = token.EOF
 	IDENT     = token.IDENT
 	INT       = token.INT
+	FLOAT     = token.FLOAT
 	STRING    = token.STRING
 	ASSIGN    = token.ASSIGN
 	COLON     = token.COLON
@@ -41,36 +46,102 @@ const (
 	RBRACKET  = token.RBRACKET
 	DOLLAR    = token.DOLLAR
 	BANG      = token.BANG
+	AT        = token.AT
+	SPREAD    = token.SPREAD
+	PIPE      = token.PIPE
+	AMP       = token.AMP
 )
 
 // AST types
 type (
-	Node                = ast.Node
-	Document            = ast.Document
-	Definition          = ast.Definition
-	OperationDefinition = ast.OperationDefinition
-	VariableDefinition  = ast.VariableDefinition
-	Type                = ast.Type
-	SelectionSet        = ast.SelectionSet
-	Selection           = ast.Selection
-	Field               = ast.Field
-	Argument            = ast.Argument
-	Value               = ast.Value
-	TypeDefinition      = ast.TypeDefinition
+	Node                      = ast.Node
+	Loc                       = ast.Loc
+	Position                  = ast.Position
+	Document                  = ast.Document
+	Definition                = ast.Definition
+	OperationDefinition       = ast.OperationDefinition
+	VariableDefinition        = ast.VariableDefinition
+	Type                      = ast.Type
+	SelectionSet              = ast.SelectionSet
+	Selection                 = ast.Selection
+	Field                     = ast.Field
+	Argument                  = ast.Argument
+	Value                     = ast.Value
+	TypeDefinition            = ast.TypeDefinition
+	InterfaceTypeDefinition   = ast.InterfaceTypeDefinition
+	TypeExtension             = ast.TypeExtension
+	DirectiveDefinition       = ast.DirectiveDefinition
+	SchemaDefinition          = ast.SchemaDefinition
+	OperationTypeDefinition   = ast.OperationTypeDefinition
+	ScalarTypeDefinition      = ast.ScalarTypeDefinition
+	UnionTypeDefinition       = ast.UnionTypeDefinition
+	InputObjectTypeDefinition = ast.InputObjectTypeDefinition
+	InputValueDefinition      = ast.InputValueDefinition
+	EnumTypeDefinition        = ast.EnumTypeDefinition
+	EnumValueDefinition       = ast.EnumValueDefinition
+	Directive                 = ast.Directive
 )
 
 // Executor types
 type (
-	ResolverFunc = executor.ResolverFunc
-	Executor     = executor.Executor
+	ResolverFunc         = executor.ResolverFunc
+	ContextResolverFunc  = executor.ContextResolverFunc
+	Executor             = executor.Executor
+	Error                = executor.Error
+	Location             = executor.Location
+	FieldNaming          = executor.FieldNaming
+	FieldNameMapper      = executor.FieldNameMapper
+	MissingFieldPolicy   = executor.MissingFieldPolicy
+	DeprecatedFieldUsage = executor.DeprecatedFieldUsage
+	DeprecationHandler   = executor.DeprecationHandler
+	TypeNamer            = executor.TypeNamer
+	TypeResolver         = executor.TypeResolver
+	ResolveInfo          = executor.ResolveInfo
+	Thunk                = executor.Thunk
+	ErrorPresenter       = executor.ErrorPresenter
+	Tracer               = executor.Tracer
+	NopTracer            = executor.NopTracer
+	FinishFunc           = executor.FinishFunc
+	OrderedMap           = executor.OrderedMap
+	PreparedQuery        = executor.PreparedQuery
+	Scalar               = executor.Scalar
+	Marshaler            = executor.Marshaler
+	Args                 = executor.Args
+	PoolStats            = executor.PoolStats
+)
+
+// Field naming strategies
+const (
+	FieldNamingDefault   = executor.FieldNamingDefault
+	FieldNamingCamelCase = executor.FieldNamingCamelCase
+	FieldNamingSnakeCase = executor.FieldNamingSnakeCase
+)
+
+// Missing field policies
+const (
+	MissingFieldError           = executor.MissingFieldError
+	MissingFieldNull            = executor.MissingFieldNull
+	MissingFieldNullWithWarning = executor.MissingFieldNullWithWarning
 )
 
+// Timeout errors
+var (
+	ErrExecutionTimeout = executor.ErrExecutionTimeout
+	ErrFieldTimeout     = executor.ErrFieldTimeout
+)
+
+// Upload is a file value for use in variables.
+type Upload = variables.Upload
+
 // Lexer type
 type Lexer = lexer.Lexer
 
 // Parser type
 type Parser = parser.Parser
 
+// DefaultMaxParseDepth is the default maximum nesting depth of a document.
+const DefaultMaxParseDepth = parser.DefaultMaxDepth
+
 // ===========================
 // Convenience Functions
 // ===========================
@@ -80,20 +151,197 @@ func NewLexer(input string) *Lexer {
 	return lexer.New(input)
 }
 
+// NewLexerFromReader creates a new lexer that reads GraphQL source from r.
+func NewLexerFromReader(r io.Reader) *Lexer {
+	return lexer.NewFromReader(r)
+}
+
 // NewParser creates a new parser for the given lexer.
 func NewParser(l *Lexer) *Parser {
 	return parser.New(l)
 }
 
+// MergeExtensions combines schema documents, such as the SDL files of a
+// modular schema, into one with every type extension folded into the type
+// it extends.
+func MergeExtensions(docs ...*Document) (*Document, error) {
+	return ast.MergeExtensions(docs...)
+}
+
+// Print renders every definition in doc as GraphQL source.
+func Print(doc *Document) string {
+	return ast.Print(doc)
+}
+
+// PrintSchema renders the type system definitions in doc as SDL.
+func PrintSchema(doc *Document) string {
+	return ast.PrintSchema(doc)
+}
+
+// SetMaxParseDepth sets the maximum nesting depth of documents parsed by
+// parsers created afterwards, including those of the HTTP handlers.
+// A value of zero or less disables the limit.
+func SetMaxParseDepth(n int) {
+	parser.SetMaxDepth(n)
+}
+
 // NewExecutor creates a new executor instance.
 func NewExecutor() *Executor {
 	return executor.New()
 }
 
+// MarshalVariables converts a Go struct or map into an operation variables map.
+func MarshalVariables(v interface{}) (map[string]interface{}, error) {
+	return variables.Marshal(v)
+}
+
+// ResolveInfoFromContext returns the description of the field being
+// resolved with ctx, or nil outside of a resolver.
+func ResolveInfoFromContext(ctx context.Context) *ResolveInfo {
+	return executor.ResolveInfoFromContext(ctx)
+}
+
+// SetExtension sets the named entry of the "extensions" map of the response
+// being executed with ctx.
+func SetExtension(ctx context.Context, name string, value interface{}) {
+	executor.SetExtension(ctx, name, value)
+}
+
 // ===========================
 // Global Registry Functions
 // ===========================
 
+// SetDevMode toggles development mode on the global executor, which adds
+// stack traces to error extensions.
+func SetDevMode(enabled bool) {
+	registry.GetGlobalExecutor().SetDevMode(enabled)
+}
+
+// SetFieldNaming sets the strategy the global executor uses to match struct
+// fields to GraphQL field names.
+func SetFieldNaming(naming FieldNaming) {
+	registry.GetGlobalExecutor().SetFieldNaming(naming)
+}
+
+// SetFieldNameMapper installs a custom struct field to GraphQL name mapping
+// on the global executor.
+func SetFieldNameMapper(mapper FieldNameMapper) {
+	registry.GetGlobalExecutor().SetFieldNameMapper(mapper)
+}
+
+// SetMissingFieldPolicy sets how the global executor handles selected
+// fields that are absent on their source.
+func SetMissingFieldPolicy(policy MissingFieldPolicy) {
+	registry.GetGlobalExecutor().SetMissingFieldPolicy(policy)
+}
+
+// SetFilterRawJSON controls whether the global executor applies selection
+// sets to json.RawMessage values returned by resolvers.
+func SetFilterRawJSON(enabled bool) {
+	registry.GetGlobalExecutor().SetFilterRawJSON(enabled)
+}
+
+// SetSchema attaches a schema parsed from SDL to the global executor.
+func SetSchema(schema *Document) {
+	registry.GetGlobalExecutor().SetSchema(schema)
+}
+
+// SetDeprecationHandler installs a handler the global executor calls for
+// each deprecated field an operation selects. It requires a schema set
+// with SetSchema.
+func SetDeprecationHandler(handler DeprecationHandler) {
+	registry.GetGlobalExecutor().SetDeprecationHandler(handler)
+}
+
+// SetTimeout bounds the execution of each operation on the global executor.
+func SetTimeout(d time.Duration) {
+	registry.GetGlobalExecutor().SetTimeout(d)
+}
+
+// SetFieldTimeout bounds each resolver call on the global executor.
+func SetFieldTimeout(d time.Duration) {
+	registry.GetGlobalExecutor().SetFieldTimeout(d)
+}
+
+// SetMaxGoroutines limits the resolver goroutines each operation of the
+// global executor may have running at once.
+func SetMaxGoroutines(n int) {
+	registry.GetGlobalExecutor().SetMaxGoroutines(n)
+}
+
+// SetWorkerPool limits the resolver goroutines all operations of the global
+// executor may have running at once.
+func SetWorkerPool(n int) {
+	registry.GetGlobalExecutor().SetWorkerPool(n)
+}
+
+// SetMaxDepth limits how deeply the fields of operations executed by the
+// global executor may nest.
+func SetMaxDepth(n int) {
+	registry.GetGlobalExecutor().SetMaxDepth(n)
+}
+
+// SetMaxNodes limits the number of values in the responses of the global
+// executor, truncating larger responses with an error.
+func SetMaxNodes(n int) {
+	registry.GetGlobalExecutor().SetMaxNodes(n)
+}
+
+// SetMaxResponseSize limits the approximate encoded size in bytes of the
+// responses of the global executor, truncating larger responses with an
+// error.
+func SetMaxResponseSize(n int) {
+	registry.GetGlobalExecutor().SetMaxResponseSize(n)
+}
+
+// SetValidation controls whether the global executor validates documents
+// against its schema before executing them.
+func SetValidation(enabled bool) {
+	registry.GetGlobalExecutor().SetValidation(enabled)
+}
+
+// SetMemoization enables or disables memoizing field resolution within an
+// operation on the global executor.
+func SetMemoization(enabled bool) {
+	registry.GetGlobalExecutor().SetMemoization(enabled)
+}
+
+// SetErrorPresenter installs the presenter applied to the errors of every
+// response of the global executor, including those of the HTTP handlers.
+func SetErrorPresenter(presenter ErrorPresenter) {
+	registry.GetGlobalExecutor().SetErrorPresenter(presenter)
+}
+
+// SetTracer installs the tracer observing the requests of the global
+// executor, including the parsing done by the HTTP handlers.
+func SetTracer(tracer Tracer) {
+	registry.GetGlobalExecutor().SetTracer(tracer)
+}
+
+// SetOrderedFields controls whether the global executor encodes the fields
+// of response objects in selection order.
+func SetOrderedFields(enabled bool) {
+	registry.GetGlobalExecutor().SetOrderedFields(enabled)
+}
+
+// RegisterEnum maps the values of an enum type to Go values on the global
+// executor. It requires a schema set with SetSchema.
+func RegisterEnum(name string, values map[string]interface{}) {
+	registry.GetGlobalExecutor().RegisterEnum(name, values)
+}
+
+// RegisterScalar installs the implementation of a custom scalar type on the
+// global executor. It requires a schema set with SetSchema.
+func RegisterScalar(name string, scalar Scalar) {
+	registry.GetGlobalExecutor().RegisterScalar(name, scalar)
+}
+
+// RegisterTypeResolver installs the resolver that determines the concrete
+// type of values of an interface or union on the global executor.
+func RegisterTypeResolver(abstractType string, resolver TypeResolver) {
+	registry.GetGlobalExecutor().RegisterTypeResolver(abstractType, resolver)
+}
+
 // RegisterQueryResolver registers a query resolver in the global registry.
 func RegisterQueryResolver(field string, resolver ResolverFunc) {
 	registry.RegisterQueryResolver(field, resolver)
@@ -109,16 +357,17 @@ func RegisterSubscriptionResolver(field string, resolver ResolverFunc) {
 	registry.RegisterSubscriptionResolver(field, resolver)
 }
 
-// ===========================
-// HTTP Handlers
-// ===========================
-
-// GraphqlHandler handles standard GraphQL HTTP requests.
-// For backward compatibility with existing code.
-var GraphqlHandler = handler.GraphQL
+// RegisterQueryResolverContext registers a context-aware query resolver in the global registry.
+func RegisterQueryResolverContext(field string, resolver ContextResolverFunc) {
+	registry.RegisterQueryResolverContext(field, resolver)
+}
 
-// GraphqlUploadHandler handles GraphQL requests with file upload support.
-var GraphqlUploadHandler = handler.Upload
+// RegisterMutationResolverContext registers a context-aware mutation resolver in the global registry.
+func RegisterMutationResolverContext(field string, resolver ContextResolverFunc) {
+	registry.RegisterMutationResolverContext(field, resolver)
+}
 
-// SubscriptionHandler handles GraphQL subscriptions over WebSocket.
-var SubscriptionHandler = handler.Subscription
+// RegisterSubscriptionResolverContext registers a context-aware subscription resolver in the global registry.
+func RegisterSubscriptionResolverContext(field string, resolver ContextResolverFunc) {
+	registry.RegisterSubscriptionResolverContext(field, resolver)
+}
@@ -0,0 +1,31 @@
+//go:build !js
+
+package graphql
+
+import "github.com/Protocol-Lattice/graphql/handler"
+
+// ===========================
+// HTTP Handlers
+// ===========================
+
+// GraphqlHandler handles standard GraphQL HTTP requests.
+// For backward compatibility with existing code.
+var GraphqlHandler = handler.GraphQL
+
+// GraphqlUploadHandler handles GraphQL requests with file upload support.
+var GraphqlUploadHandler = handler.Upload
+
+// SubscriptionHandler handles GraphQL subscriptions over WebSocket.
+var SubscriptionHandler = handler.Subscription
+
+// SchemaHandler serves the global executor's schema as SDL.
+var SchemaHandler = handler.Schema
+
+// HandlerConfig configures a handler created with NewHandler.
+type HandlerConfig = handler.Config
+
+// NewHandler returns a handler serving GraphQL requests, including file
+// uploads, as config describes.
+func NewHandler(config HandlerConfig) *handler.Handler {
+	return handler.New(config)
+}
@@ -0,0 +1,159 @@
+//go:build !js
+
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphql "github.com/Protocol-Lattice/graphql"
+	"github.com/Protocol-Lattice/graphql/dataloader"
+)
+
+func TestGraphqlHandlerInvalidJSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBufferString("not-json"))
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid JSON, got %d", resp.StatusCode)
+	}
+}
+
+func TestGraphqlHandlerNoDefinitions(t *testing.T) {
+	payload := map[string]interface{}{
+		"query": "",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty document, got %d", resp.StatusCode)
+	}
+}
+
+func TestGraphqlHandlerNilVariables(t *testing.T) {
+	graphql.RegisterQueryResolver("greet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hi", nil
+	})
+	payload := map[string]interface{}{
+		"query":     "{ greet }",
+		"variables": nil,
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, req)
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestGraphqlHandlerDebugExtension(t *testing.T) {
+	graphql.RegisterQueryResolver("greet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hi", nil
+	})
+	body, _ := json.Marshal(map[string]interface{}{"query": "{ greet }"})
+
+	for _, devMode := range []bool{false, true} {
+		graphql.SetDevMode(devMode)
+		req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+		req.Header.Set("X-GraphQL-Debug", "1")
+		w := httptest.NewRecorder()
+		graphql.GraphqlHandler(w, req)
+
+		var resp struct {
+			Extensions struct {
+				Debug *struct {
+					Resolvers []struct {
+						Path []interface{} `json:"path"`
+					} `json:"resolvers"`
+				} `json:"debug"`
+			} `json:"extensions"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if (resp.Extensions.Debug != nil) != devMode {
+			t.Errorf("devMode=%v: debug extension present=%v", devMode, resp.Extensions.Debug != nil)
+		}
+		if devMode && (len(resp.Extensions.Debug.Resolvers) != 1 || resp.Extensions.Debug.Resolvers[0].Path[0] != "greet") {
+			t.Errorf("unexpected resolver timings: %+v", resp.Extensions.Debug.Resolvers)
+		}
+	}
+	graphql.SetDevMode(false)
+
+	// Validation and data loader batches are reported too.
+	exec := graphql.NewExecutor()
+	exec.SetDevMode(true)
+	exec.SetValidation(true)
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`type Query { users: [User] } type User { name: String }`)).ParseDocument())
+	exec.RegisterQueryResolverContext("users", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		loader := dataloader.For(ctx, "names", func(ctx context.Context, keys []int) ([]map[string]interface{}, []error) {
+			users := make([]map[string]interface{}, len(keys))
+			for i, key := range keys {
+				users[i] = map[string]interface{}{"name": fmt.Sprint("user", key)}
+			}
+			return users, nil
+		})
+		values, _ := loader.LoadMany(ctx, []int{1, 2, 3})
+		return values, nil
+	})
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ users { name } }"}`))
+	req.Header.Set("X-GraphQL-Debug", "1")
+	w := httptest.NewRecorder()
+	graphql.NewHandler(graphql.HandlerConfig{Executor: exec}).ServeHTTP(w, req)
+	var resp struct {
+		Extensions struct {
+			Debug struct {
+				Validate *struct {
+					DurationNs int64 `json:"durationNs"`
+				} `json:"validate"`
+				Dataloaders map[string]struct {
+					Batches int `json:"batches"`
+					Keys    int `json:"keys"`
+				} `json:"dataloaders"`
+			} `json:"debug"`
+		} `json:"extensions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Extensions.Debug.Validate == nil || resp.Extensions.Debug.Validate.DurationNs <= 0 {
+		t.Errorf("expected validate timings, got %+v", resp.Extensions.Debug)
+	}
+	if names := resp.Extensions.Debug.Dataloaders["names"]; names.Batches != 1 || names.Keys != 3 {
+		t.Errorf("expected one batch of 3 keys, got %+v", resp.Extensions.Debug.Dataloaders)
+	}
+}
+
+func TestGraphqlHandlerRequestContext(t *testing.T) {
+	type traceKey struct{}
+	graphql.RegisterQueryResolverContext("trace", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return ctx.Value(traceKey{}), nil
+	})
+	body, _ := json.Marshal(map[string]interface{}{"query": "{ trace }"})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	req = req.WithContext(context.WithValue(req.Context(), traceKey{}, "abc"))
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, req)
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["trace"] != "abc" {
+		t.Errorf("expected the request context to reach the resolver, got %v", resp.Data)
+	}
+}
@@ -1,42 +1,27 @@
 package graphql_test
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"net/http"
-	"net/http/httptest"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	graphql "github.com/Protocol-Lattice/graphql"
+	"github.com/Protocol-Lattice/graphql/dataloader"
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/gqlerrors"
 )
 
-func TestGraphqlHandlerInvalidJSON(t *testing.T) {
-	req := httptest.NewRequest("POST", "/graphql", bytes.NewBufferString("not-json"))
-	w := httptest.NewRecorder()
-	graphql.GraphqlHandler(w, req)
-	resp := w.Result()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected 400 for invalid JSON, got %d", resp.StatusCode)
-	}
-}
-
-func TestGraphqlHandlerNoDefinitions(t *testing.T) {
-	payload := map[string]interface{}{
-		"query": "",
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
-	w := httptest.NewRecorder()
-	graphql.GraphqlHandler(w, req)
-	resp := w.Result()
-	if resp.StatusCode != http.StatusInternalServerError {
-		t.Errorf("expected 500 for empty document, got %d", resp.StatusCode)
-	}
-}
-
 func TestLexerIllegalCharacter(t *testing.T) {
-	input := "@"
+	input := "%"
 	lexer := graphql.NewLexer(input)
 	tok := lexer.NextToken()
 	if tok.Type != graphql.ILLEGAL {
@@ -149,24 +134,6 @@ func TestSubscriptionExecutor(t *testing.T) {
 	}
 }
 
-func TestGraphqlHandlerNilVariables(t *testing.T) {
-	graphql.RegisterQueryResolver("greet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
-		return "hi", nil
-	})
-	payload := map[string]interface{}{
-		"query":     "{ greet }",
-		"variables": nil,
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
-	w := httptest.NewRecorder()
-	graphql.GraphqlHandler(w, req)
-	resp := w.Result()
-	if resp.StatusCode != 200 {
-		t.Errorf("expected status 200, got %d", resp.StatusCode)
-	}
-}
-
 func TestLexerStringToken(t *testing.T) {
 	input := `"hello world"`
 	lexer := graphql.NewLexer(input)
@@ -195,3 +162,2231 @@ func TestOperationDefinitionWithNameAndVariables(t *testing.T) {
 		t.Errorf("expected one variable definition, got %d", len(op.VariableDefinitions))
 	}
 }
+
+func TestSubscriptionIterator(t *testing.T) {
+	exec := graphql.NewExecutor()
+
+	stopped := make(chan struct{})
+	exec.RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq[int](func(yield func(int) bool) {
+			defer close(stopped)
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subCh, err := exec.ExecuteSubscriptionContext(ctx, &graphql.Field{Name: "ticks"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for want := 0; want < 3; want++ {
+		if event := <-subCh; event != want {
+			t.Fatalf("expected %d, got %v", want, event)
+		}
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("iterator was not stopped after context cancellation")
+	}
+}
+
+func TestSubscriptionIteratorError(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterSubscriptionResolver("feed", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq2[string, error](func(yield func(string, error) bool) {
+			if yield("first", nil) {
+				yield("", errors.New("feed closed"))
+			}
+		}), nil
+	})
+
+	subCh, err := exec.ExecuteSubscription(&graphql.Field{Name: "feed"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var events []interface{}
+	for event := range subCh {
+		events = append(events, event)
+	}
+	if len(events) != 2 || events[0] != "first" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if err, ok := events[1].(error); !ok || err.Error() != "feed closed" {
+		t.Errorf("expected terminal error event, got %v", events[1])
+	}
+}
+
+type devModeUser struct {
+	Name string
+}
+
+func TestDevModeStackTraces(t *testing.T) {
+	doc := graphql.NewParser(graphql.NewLexer(`{ crash { name } }`)).ParseDocument()
+
+	for _, devMode := range []bool{true, false} {
+		exec := graphql.NewExecutor()
+		exec.SetDevMode(devMode)
+		exec.RegisterQueryResolver("crash", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			var u *devModeUser
+			return u.Name, nil // nil dereference
+		})
+
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		errs, _ := result["errors"].([]*graphql.Error)
+		if len(errs) != 1 {
+			t.Fatalf("expected one field error, got %v", result["errors"])
+		}
+		gqlErr := errs[0]
+		if len(gqlErr.Path) != 1 || gqlErr.Path[0] != "crash" {
+			t.Errorf("expected path [crash], got %v", gqlErr.Path)
+		}
+		_, hasStack := gqlErr.Extensions["stacktrace"]
+		if hasStack != devMode {
+			t.Errorf("devMode=%v: stacktrace present=%v", devMode, hasStack)
+		}
+	}
+}
+
+func TestResolverPanicsAreLogged(t *testing.T) {
+	var buf strings.Builder
+	exec := graphql.NewExecutor()
+	exec.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	exec.RegisterQueryResolver("crash", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		panic("out of cheese")
+	})
+
+	doc := graphql.NewParser(graphql.NewLexer(`{ crash }`)).ParseDocument()
+	if _, err := exec.Execute(doc, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q", buf.String())
+	}
+	if record["level"] != "ERROR" || record["path"] != "crash" || record["panic"] != "out of cheese" || record["stack"] == "" {
+		t.Errorf("unexpected record %v", record)
+	}
+}
+
+type namingAccount struct {
+	AccountID string `json:"account_id"`
+	OwnerName string
+}
+
+func TestFieldNaming(t *testing.T) {
+	tests := []struct {
+		naming graphql.FieldNaming
+		query  string
+		want   map[string]interface{}
+	}{
+		{graphql.FieldNamingDefault, `{ account { account_id ownerName } }`, map[string]interface{}{"account_id": "a1", "ownerName": "Ada"}},
+		{graphql.FieldNamingCamelCase, `{ account { accountId ownerName } }`, map[string]interface{}{"accountId": "a1", "ownerName": "Ada"}},
+		{graphql.FieldNamingSnakeCase, `{ account { account_id owner_name } }`, map[string]interface{}{"account_id": "a1", "owner_name": "Ada"}},
+	}
+	for _, tt := range tests {
+		exec := graphql.NewExecutor()
+		exec.SetFieldNaming(tt.naming)
+		exec.RegisterQueryResolver("account", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return &namingAccount{AccountID: "a1", OwnerName: "Ada"}, nil
+		})
+		doc := graphql.NewParser(graphql.NewLexer(tt.query)).ParseDocument()
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Errorf("naming %d: %v", tt.naming, err)
+			continue
+		}
+		got := result["data"].(map[string]interface{})["account"]
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("naming %d: expected %v, got %v", tt.naming, tt.want, got)
+		}
+	}
+}
+
+// protoUser mimics a protobuf-generated struct.
+type protoUser struct {
+	UserId      string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+}
+
+func TestFieldNameMapper(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetFieldNameMapper(func(sf reflect.StructField) []string {
+		for _, opt := range strings.Split(sf.Tag.Get("protobuf"), ",") {
+			if name, ok := strings.CutPrefix(opt, "json="); ok {
+				return []string{name}
+			}
+		}
+		return nil
+	})
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return &protoUser{UserId: "u1", DisplayName: "Ada"}, nil
+	})
+
+	doc := graphql.NewParser(graphql.NewLexer(`{ user { userId displayName } }`)).ParseDocument()
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"userId": "u1", "displayName": "Ada"}
+	if got := result["data"].(map[string]interface{})["user"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// The Go field name no longer matches once a mapper is installed.
+	doc = graphql.NewParser(graphql.NewLexer(`{ user { user_id } }`)).ParseDocument()
+	if result, _ := exec.Execute(doc, nil); result["errors"] == nil {
+		t.Error("expected unmapped name to fail")
+	}
+
+	// Removing the mapper restores the default names.
+	exec.SetFieldNameMapper(nil)
+	if result, _ := exec.Execute(doc, nil); result["errors"] != nil {
+		t.Errorf("expected default names after removing the mapper, got %v", result["errors"])
+	}
+}
+
+func TestMissingFieldPolicy(t *testing.T) {
+	doc := graphql.NewParser(graphql.NewLexer(`{ user { name nickname } }`)).ParseDocument()
+	newExec := func(policy graphql.MissingFieldPolicy) *graphql.Executor {
+		exec := graphql.NewExecutor()
+		exec.SetMissingFieldPolicy(policy)
+		exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return &devModeUser{Name: "Ada"}, nil
+		})
+		return exec
+	}
+
+	if result, _ := newExec(graphql.MissingFieldError).Execute(doc, nil); result["errors"] == nil {
+		t.Error("expected an error for the missing field by default")
+	}
+
+	result, err := newExec(graphql.MissingFieldNull).Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"name": "Ada", "nickname": nil}
+	if got := result["data"].(map[string]interface{})["user"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, ok := result["extensions"]; ok {
+		t.Error("expected no warnings without MissingFieldNullWithWarning")
+	}
+
+	result, err = newExec(graphql.MissingFieldNullWithWarning).Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := result["extensions"].(map[string]interface{})["warnings"].([]map[string]interface{})
+	if len(warnings) != 1 || !reflect.DeepEqual(warnings[0]["path"], []interface{}{"user", "nickname"}) {
+		t.Errorf("expected one warning for user.nickname, got %v", warnings)
+	}
+}
+
+func TestRawJSONPassThrough(t *testing.T) {
+	raw := json.RawMessage(`{"name":"Ada","email":"ada@example.com","friends":[{"name":"Bob","age":30}]}`)
+	doc := graphql.NewParser(graphql.NewLexer(`{ profile { name friends { name } } settings }`)).ParseDocument()
+
+	for _, filter := range []bool{false, true} {
+		exec := graphql.NewExecutor()
+		exec.SetFilterRawJSON(filter)
+		exec.RegisterQueryResolver("profile", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return raw, nil
+		})
+		exec.RegisterQueryResolver("settings", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return json.RawMessage(`{"theme":"dark"}`), nil
+		})
+
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"data":{"profile":` + string(raw) + `,"settings":{"theme":"dark"}}}`
+		if filter {
+			want = `{"data":{"profile":{"friends":[{"name":"Bob"}],"name":"Ada"},"settings":{"theme":"dark"}}}`
+		}
+		if string(out) != want {
+			t.Errorf("filter=%v:\nexpected %s\ngot      %s", filter, want, out)
+		}
+	}
+}
+
+func TestFragmentSpreads(t *testing.T) {
+	type User struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return User{ID: "1", Name: "Ada", Email: "ada@example.com"}, nil
+	})
+
+	src := `fragment Contact on User { email ...Contact }
+query { user { ...Basic ...Contact } }
+fragment Basic on User { id name }`
+	p := graphql.NewParser(graphql.NewLexer(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"user":{"email":"ada@example.com","id":"1","name":"Ada"}}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+type Admin struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type Guest struct {
+	Name string `json:"name"`
+}
+
+func TestInlineFragments(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("members", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []interface{}{&Admin{Name: "Ada", Permissions: []string{"all"}}, Guest{Name: "Bob"}}, nil
+	})
+
+	p := graphql.NewParser(graphql.NewLexer(`{ members { ... { name } ... on Admin { permissions } } }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"members":[{"name":"Ada","permissions":["all"]},{"name":"Bob"}]}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestSkipAndIncludeDirectives(t *testing.T) {
+	exec := graphql.NewExecutor()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		value := name
+		exec.RegisterQueryResolver(name, func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return value, nil
+		})
+	}
+
+	src := `query Q($on: Boolean, $off: Boolean) {
+  a @include(if: $on)
+  b @skip(if: $on)
+  ... @include(if: $off) { c }
+  ... @skip(if: $off) { d }
+}`
+	p := graphql.NewParser(graphql.NewLexer(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	result, err := exec.Execute(doc, map[string]interface{}{"on": true, "off": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	if want := `{"data":{"a":"a","d":"d"}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestVariableDefaults(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return args, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`query ($limit: Int = 10, $after: String = "x") { users(limit: $limit, after: $after) }`)).ParseDocument()
+
+	vars := map[string]interface{}{"after": nil}
+	result, err := exec.Execute(doc, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	if want := `{"data":{"users":{"after":null,"limit":10}}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	if len(vars) != 1 {
+		t.Errorf("caller's variables were modified: %v", vars)
+	}
+}
+
+func TestFloatAndNullArguments(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("echo", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return args, nil
+	})
+	p := graphql.NewParser(graphql.NewLexer(`{ echo(ratio: 0.25, missing: null, point: {x: -1.5, y: null}, list: [1e3, null]) }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"ratio":   0.25,
+		"missing": nil,
+		"point":   map[string]interface{}{"x": -1.5, "y": nil},
+		"list":    []interface{}{1000.0, nil},
+	}
+	if got := result["data"].(map[string]interface{})["echo"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
+func TestNestedListTypes(t *testing.T) {
+	p := graphql.NewParser(graphql.NewLexer(`type Query { matrix: [[Int!]!]! grid(rows: [[Int]]): [[String]!] }
+query ($m: [[Int!]!]!) { matrix }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	fields := doc.Definitions[0].(*graphql.TypeDefinition).Fields
+	matrix := fields[0].Type
+	if got := matrix.String(); got != "[[Int!]!]!" {
+		t.Errorf("expected [[Int!]!]!, got %s", got)
+	}
+	if !matrix.NonNull || !matrix.Elem.NonNull || !matrix.Elem.Elem.NonNull || matrix.Elem.Elem.Name != "Int" {
+		t.Errorf("non-null flags not preserved: %+v", matrix)
+	}
+	if got := fields[1].Type.String(); got != "[[String]!]" {
+		t.Errorf("expected [[String]!], got %s", got)
+	}
+	if got := fields[1].ArgumentDefinitions[0].Type.String(); got != "[[Int]]" {
+		t.Errorf("expected [[Int]], got %s", got)
+	}
+	op := doc.Definitions[1].(*graphql.OperationDefinition)
+	if got := op.VariableDefinitions[0].Type.String(); got != "[[Int!]!]!" {
+		t.Errorf("expected variable type [[Int!]!]!, got %s", got)
+	}
+
+	p = graphql.NewParser(graphql.NewLexer(`type Query { broken: [] }`))
+	p.ParseDocument()
+	if len(p.Errors()) == 0 {
+		t.Error("expected an error for a list type without an element type")
+	}
+}
+
+func TestParserMaxDepth(t *testing.T) {
+	deep := strings.Repeat("{ a ", 50) + strings.Repeat("}", 50)
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		fail  bool
+	}{
+		{"within default", deep, 0, false},
+		{"selection sets", deep, 10, true},
+		{"object values", "{ f(x: " + strings.Repeat("{a: ", 20) + "1" + strings.Repeat("}", 20) + ") }", 10, true},
+		{"list values", "{ f(x: " + strings.Repeat("[", 20) + strings.Repeat("]", 20) + ") }", 10, true},
+		{"list types", "query ($x: " + strings.Repeat("[", 20) + "Int" + strings.Repeat("]", 20) + ") { a }", 10, true},
+		{"disabled", strings.Repeat("{ a ", 500) + strings.Repeat("}", 500), -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := graphql.NewParser(graphql.NewLexer(tt.input))
+			if tt.limit != 0 {
+				p.SetMaxDepth(tt.limit)
+			}
+			p.ParseDocument()
+			errs := p.Errors()
+			if !tt.fail {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0] != "maximum nesting depth of 10 exceeded" {
+				t.Errorf("expected a single depth error, got %v", errs)
+			}
+		})
+	}
+
+	graphql.SetMaxParseDepth(5)
+	defer graphql.SetMaxParseDepth(graphql.DefaultMaxParseDepth)
+	p := graphql.NewParser(graphql.NewLexer(deep))
+	p.ParseDocument()
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected the global limit to apply, got %v", p.Errors())
+	}
+}
+
+func TestMergeExtensions(t *testing.T) {
+	parse := func(src string) *graphql.Document {
+		t.Helper()
+		p := graphql.NewParser(graphql.NewLexer(src))
+		doc := p.ParseDocument()
+		if errs := p.Errors(); len(errs) > 0 {
+			t.Fatalf("parse errors: %v", errs)
+		}
+		return doc
+	}
+	users := parse(`extend type Query { user(id: ID!): User }
+type User { id: ID! }
+extend enum Role { GUEST }`)
+	base := parse(`type Query { me: User }
+interface Node { id: ID! }
+enum Role { ADMIN }
+extend type User implements Node`)
+
+	merged, err := graphql.MergeExtensions(base, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, def := range merged.Definitions {
+		switch def := def.(type) {
+		case *graphql.TypeDefinition:
+			var fields []string
+			for _, f := range def.Fields {
+				fields = append(fields, f.Name)
+			}
+			got = append(got, def.Name+strings.Join(def.Interfaces, "&")+"{"+strings.Join(fields, ",")+"}")
+		case *graphql.EnumTypeDefinition:
+			var values []string
+			for _, v := range def.Values {
+				values = append(values, v.Name)
+			}
+			got = append(got, def.Name+"{"+strings.Join(values, ",")+"}")
+		}
+	}
+	want := []string{"Query{me,user}", "Role{ADMIN,GUEST}", "UserNode{id}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if n := len(base.Definitions[0].(*graphql.TypeDefinition).Fields); n != 1 {
+		t.Errorf("input document was modified: Query has %d fields", n)
+	}
+
+	for src, want := range map[string]string{
+		`extend type Missing { a: Int }`:             "cannot extend undefined type Missing",
+		`type A { a: Int } extend type A { a: Int }`: "cannot extend type A: field a already exists",
+		`type A { a: Int } extend enum A { B }`:      "cannot extend type A: it is an object type",
+		`type A { a: Int } type A { b: Int }`:        "type A is defined more than once",
+	} {
+		if _, err := graphql.MergeExtensions(parse(src)); err == nil || err.Error() != want {
+			t.Errorf("%s: expected %q, got %v", src, want, err)
+		}
+	}
+}
+
+func TestTypeFieldDefinitions(t *testing.T) {
+	p := graphql.NewParser(graphql.NewLexer(`type Query {
+  users(first: Int = 10, after: String): [User!]! @auth
+  me: User
+}`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	fields := doc.Definitions[0].(*graphql.TypeDefinition).Fields
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(fields))
+	}
+	users := fields[0]
+	if got := users.Type.String(); got != "[User!]!" {
+		t.Errorf("expected type [User!]!, got %s", got)
+	}
+	if len(users.ArgumentDefinitions) != 2 {
+		t.Fatalf("expected 2 arguments, got %d", len(users.ArgumentDefinitions))
+	}
+	first := users.ArgumentDefinitions[0]
+	if first.Name != "first" || first.Type.String() != "Int" || first.DefaultValue == nil || first.DefaultValue.Literal != "10" {
+		t.Errorf("unexpected first argument: %+v", first)
+	}
+	if after := users.ArgumentDefinitions[1]; after.Name != "after" || after.Type.String() != "String" || after.DefaultValue != nil {
+		t.Errorf("unexpected after argument: %+v", after)
+	}
+	if len(users.Directives) != 1 || users.Directives[0].Name != "auth" {
+		t.Errorf("expected @auth directive, got %v", users.Directives)
+	}
+	if got := fields[1].Type.String(); got != "User" || fields[1].ArgumentDefinitions != nil {
+		t.Errorf("unexpected me field: %+v", fields[1])
+	}
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	p := graphql.NewParser(graphql.NewLexer(`type Query {
+  user: User
+  legacyUser: User @deprecated(reason: "Use user.")
+}
+type User { name: String fullName: String @deprecated }
+enum Role { ADMIN ROOT @deprecated(reason: "Use ADMIN.") }`))
+	schema := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	legacy := schema.Definitions[0].(*graphql.TypeDefinition).Fields[1]
+	if !legacy.Deprecated || legacy.DeprecationReason != "Use user." {
+		t.Errorf("unexpected deprecation of legacyUser: %v %q", legacy.Deprecated, legacy.DeprecationReason)
+	}
+	fullName := schema.Definitions[1].(*graphql.TypeDefinition).Fields[1]
+	if !fullName.Deprecated || fullName.DeprecationReason != "No longer supported" {
+		t.Errorf("expected the default reason for fullName, got %v %q", fullName.Deprecated, fullName.DeprecationReason)
+	}
+	values := schema.Definitions[2].(*graphql.EnumTypeDefinition).Values
+	if values[0].Deprecated || !values[1].Deprecated || values[1].DeprecationReason != "Use ADMIN." {
+		t.Errorf("unexpected enum value deprecations: %+v %+v", values[0], values[1])
+	}
+
+	type User struct{ Name, FullName string }
+	exec := graphql.NewExecutor()
+	exec.SetSchema(schema)
+	exec.RegisterQueryResolver("legacyUser", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return User{Name: "Ada", FullName: "Ada Lovelace"}, nil
+	})
+	var got []graphql.DeprecatedFieldUsage
+	exec.SetDeprecationHandler(func(ctx context.Context, usage graphql.DeprecatedFieldUsage) {
+		got = append(got, usage)
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ legacyUser { name fullName ...F } } fragment F on User { fullName }`)).ParseDocument()
+	if _, err := exec.Execute(doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []graphql.DeprecatedFieldUsage{
+		{Type: "Query", Field: "legacyUser", Reason: "Use user."},
+		{Type: "User", Field: "fullName", Reason: "No longer supported"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "query Q($id: ID = 1) {\n  user(id: $id) @include(if: true) {\n    ...F\n  }\n}\nfragment F on User { name }\n\"Doc\"\ntype User { name: String }"
+	p := graphql.NewParser(graphql.NewLexer(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	op := doc.Definitions[0].(*graphql.OperationDefinition)
+	user := op.SelectionSet.Selections[0].(*graphql.Field)
+	typeDef := doc.Definitions[2].(*graphql.TypeDefinition)
+	tests := []struct {
+		name       string
+		node       graphql.Node
+		start, end string
+	}{
+		{"document", doc, "1:1", "8:27"},
+		{"operation", op, "1:1", "5:2"},
+		{"variable", &op.VariableDefinitions[0], "1:9", "1:20"},
+		{"default value", op.VariableDefinitions[0].DefaultValue, "1:19", "1:20"},
+		{"field", user, "2:3", "4:4"},
+		{"argument", &user.Arguments[0], "2:8", "2:15"},
+		{"directive", user.Directives[0], "2:17", "2:35"},
+		{"spread", user.SelectionSet.Selections[0], "3:5", "3:9"},
+		{"fragment", doc.Definitions[1], "6:1", "6:28"},
+		{"described type", typeDef, "7:1", "8:27"},
+		{"field definition", typeDef.Fields[0], "8:13", "8:25"},
+	}
+	for _, tt := range tests {
+		loc := tt.node.Location()
+		if loc.Start.String() != tt.start || loc.End.String() != tt.end {
+			t.Errorf("%s: expected %s-%s, got %s-%s", tt.name, tt.start, tt.end, loc.Start, loc.End)
+		}
+	}
+	if got := typeDef.Fields[0].Type.Start.Offset; input[got:got+6] != "String" {
+		t.Errorf("expected the field type to start at String, got offset %d", got)
+	}
+}
+
+func TestContextResolvers(t *testing.T) {
+	type userKey struct{}
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolverContext("me", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return ctx.Value(userKey{}), nil
+	})
+	exec.RegisterQueryResolver("greet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hi", nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ me greet }`)).ParseDocument()
+
+	ctx := context.WithValue(context.Background(), userKey{}, "alice")
+	result, err := exec.ExecuteContext(ctx, doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := result["data"].(map[string]interface{})
+	if data["me"] != "alice" || data["greet"] != "hi" {
+		t.Errorf("unexpected data: %v", data)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := exec.ExecuteContext(canceled, doc, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	exec.RegisterSubscriptionResolverContext("ticks", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{})
+		go func() {
+			defer close(ch)
+			<-ctx.Done()
+		}()
+		return ch, nil
+	})
+	subCtx, stop := context.WithCancel(context.Background())
+	events, err := exec.ExecuteSubscriptionContext(subCtx, &graphql.Field{Name: "ticks"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the subscription to end without events")
+		}
+	case <-time.After(time.Second):
+		t.Error("subscription resolver did not observe cancellation")
+	}
+}
+
+func TestResolversByOperationType(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "alice", nil
+	})
+	exec.RegisterMutationResolver("deleteUser", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return true, nil
+	})
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{`{ user }`, ""},
+		{`mutation { deleteUser }`, ""},
+		{`{ deleteUser }`, "field deleteUser is a mutation field and cannot be selected in a query"},
+		{`mutation { user }`, "field user is a query field and cannot be selected in a mutation"},
+		{`subscription { user }`, "subscription operations must be executed with ExecuteSubscription"},
+	}
+	for _, tt := range tests {
+		doc := graphql.NewParser(graphql.NewLexer(tt.query)).ParseDocument()
+		result, err := exec.Execute(doc, nil)
+		if errs, _ := result["errors"].([]*graphql.Error); err == nil && len(errs) > 0 {
+			err = errs[0]
+		}
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.query, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("%s: expected error %q, got %v", tt.query, tt.wantErr, err)
+		}
+	}
+}
+
+func TestFieldAliases(t *testing.T) {
+	type User struct {
+		ID   string
+		Name string
+	}
+	users := map[string]*User{"1": {ID: "1", Name: "Ada"}, "2": {ID: "2", Name: "Grace"}}
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return users[args["id"].(string)], nil
+	})
+	input := `{
+  first: user(id: "1") { id name }
+  second: user(id: "2") { userName: name name }
+  user(id: "1") { id }
+}`
+	p := graphql.NewParser(graphql.NewLexer(input))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	field := doc.Definitions[0].(*graphql.OperationDefinition).SelectionSet.Selections[0].(*graphql.Field)
+	if field.Alias != "first" || field.Name != "user" {
+		t.Errorf("expected alias first of user, got %q of %q", field.Alias, field.Name)
+	}
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"first":  map[string]interface{}{"id": "1", "name": "Ada"},
+		"second": map[string]interface{}{"userName": "Grace", "name": "Grace"},
+		"user":   map[string]interface{}{"id": "1"},
+	}
+	if !reflect.DeepEqual(result["data"], want) {
+		t.Errorf("expected %v, got %v", want, result["data"])
+	}
+}
+
+func TestFragmentsOnAbstractTypes(t *testing.T) {
+	schema := graphql.NewParser(graphql.NewLexer(`
+interface Member { name: String }
+type Admin implements Member { name: String permissions: [String] }
+type Guest { name: String }
+union Privileged = Admin
+type Query { members: [Member] }`)).ParseDocument()
+	exec := graphql.NewExecutor()
+	exec.SetSchema(schema)
+	exec.RegisterQueryResolver("members", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []interface{}{&Admin{Name: "Ada", Permissions: []string{"all"}}, Guest{Name: "Bob"}}, nil
+	})
+
+	p := graphql.NewParser(graphql.NewLexer(`{ members { ... on Member { name } ...Perms } }
+fragment Perms on Privileged { permissions }`))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"members":[{"name":"Ada","permissions":["all"]},{}]}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestNumberCoercion(t *testing.T) {
+	var got map[string]interface{}
+	exec := graphql.NewExecutor()
+	exec.RegisterMutationResolver("updateUser", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return true, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(
+		`mutation ($id: ID!, $age: Int, $scores: [Int!], $ratio: Float = 1, $input: UserInput) {
+  updateUser(id: $id, age: $age, scores: $scores, ratio: $ratio, input: $input, weight: 70)
+}`)).ParseDocument()
+	var variables map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"id": 7, "age": 36, "scores": [1, 2.5], "input": {"age": 40}}`), &variables); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := exec.Execute(doc, variables); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"id":     "7",
+		"age":    36,
+		"scores": []interface{}{1, 2.5},
+		"ratio":  1.0,
+		"input":  map[string]interface{}{"age": 40.0},
+		"weight": 70,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("without schema: expected %#v, got %#v", want, got)
+	}
+
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+input UserInput { age: Int }
+type Mutation { updateUser(id: ID!, age: Int, scores: [Int!], ratio: Float, input: UserInput, weight: Float): Boolean }`)).ParseDocument())
+	if _, err := exec.Execute(doc, variables); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want["input"] = map[string]interface{}{"age": 40}
+	want["weight"] = 70.0
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("with schema: expected %#v, got %#v", want, got)
+	}
+}
+
+type bubbleUser struct {
+	Name  *string
+	Email *string
+}
+
+func TestNullPropagation(t *testing.T) {
+	name := "Ada"
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+type User { name: String! email: String }
+type Query { user: User, users: [User!], strict: User!, broken: String }`)).ParseDocument())
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return &bubbleUser{}, nil
+	})
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []*bubbleUser{{Name: &name}, {}}, nil
+	})
+	exec.RegisterQueryResolver("strict", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return &bubbleUser{}, nil
+	})
+	exec.RegisterQueryResolver("broken", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	tests := []struct {
+		query  string
+		data   string
+		errors []string // paths of the expected errors, breadth-first
+	}{
+		{`{ user { name email } broken }`, `{"broken":null,"user":null}`, []string{"broken", "user.name"}},
+		{`{ user { email } }`, `{"user":{"email":null}}`, nil},
+		{`{ users { name } }`, `{"users":null}`, []string{"users.1.name"}},
+		{`{ broken strict { name } }`, `null`, []string{"broken", "strict.name"}},
+	}
+	for _, tt := range tests {
+		doc := graphql.NewParser(graphql.NewLexer(tt.query)).ParseDocument()
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.query, err)
+		}
+		if data, _ := json.Marshal(result["data"]); string(data) != tt.data {
+			t.Errorf("%s: expected data %s, got %s", tt.query, tt.data, data)
+		}
+		errs, _ := result["errors"].([]*graphql.Error)
+		var paths []string
+		for _, e := range errs {
+			var segments []string
+			for _, seg := range e.Path {
+				segments = append(segments, strings.TrimSuffix(strings.TrimPrefix(fmt.Sprint(seg), "["), "]"))
+			}
+			paths = append(paths, strings.Join(segments, "."))
+		}
+		if !reflect.DeepEqual(paths, tt.errors) {
+			t.Errorf("%s: expected errors at %v, got %v", tt.query, tt.errors, paths)
+		}
+	}
+}
+
+type typenameUserModel struct {
+	Name string
+}
+
+type typenameBot struct {
+	Name string
+}
+
+func (typenameBot) TypeName() string { return "Bot" }
+
+func TestTypenameField(t *testing.T) {
+	resolvers := func(exec *graphql.Executor) {
+		exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return &typenameUserModel{Name: "Ada"}, nil
+		})
+		exec.RegisterQueryResolver("actors", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return []interface{}{typenameUserModel{Name: "Ada"}, typenameBot{Name: "R2"}}, nil
+		})
+	}
+	doc := graphql.NewParser(graphql.NewLexer(`{ __typename user { kind: __typename name } actors { __typename ... on Bot { name } } }`)).ParseDocument()
+
+	exec := graphql.NewExecutor()
+	resolvers(exec)
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result["data"])
+	want := `{"__typename":"Query","actors":[{"__typename":"typenameUserModel"},{"__typename":"Bot","name":"R2"}],"user":{"kind":"typenameUserModel","name":"Ada"}}`
+	if string(out) != want {
+		t.Errorf("without schema: expected %s, got %s", want, out)
+	}
+
+	exec = graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+schema { query: Root }
+interface Actor { name: String }
+type User implements Actor { name: String }
+type Bot implements Actor { name: String }
+type Root { user: User, actors: [Actor] }`)).ParseDocument())
+	resolvers(exec)
+	result, err = exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ = json.Marshal(result["data"])
+	want = `{"__typename":"Root","actors":[{"__typename":"typenameUserModel"},{"__typename":"Bot","name":"R2"}],"user":{"kind":"User","name":"Ada"}}`
+	if string(out) != want {
+		t.Errorf("with schema: expected %s, got %s", want, out)
+	}
+}
+
+type enumRole int
+
+const (
+	enumRoleAdmin enumRole = iota + 1
+	enumRoleUser
+)
+
+func TestEnums(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+enum Role { ADMIN USER GUEST }
+input Filter { roles: [Role!] }
+type Query { role(is: Role): Role, roles(filter: Filter): [Role], guest: Role }`)).ParseDocument())
+	exec.RegisterEnum("Role", map[string]interface{}{"ADMIN": enumRoleAdmin, "USER": enumRoleUser})
+	var got map[string]interface{}
+	exec.RegisterQueryResolver("role", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return args["is"], nil
+	})
+	exec.RegisterQueryResolver("roles", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return []enumRole{enumRoleUser, enumRoleAdmin}, nil
+	})
+	exec.RegisterQueryResolver("guest", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return enumRole(42), nil
+	})
+
+	run := func(query string, variables map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), variables)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		return result
+	}
+
+	result := run(`{ role(is: ADMIN) }`, nil)
+	if got["is"] != enumRoleAdmin || result["data"].(map[string]interface{})["role"] != "ADMIN" {
+		t.Errorf("literal: got args %v and data %v", got, result["data"])
+	}
+
+	result = run(`query ($f: Filter) { roles(filter: $f) }`, map[string]interface{}{"f": map[string]interface{}{"roles": []interface{}{"USER"}}})
+	if want := map[string]interface{}{"roles": []interface{}{enumRoleUser}}; !reflect.DeepEqual(got["filter"], want) {
+		t.Errorf("input object: expected %v, got %v", want, got["filter"])
+	}
+	if data := result["data"].(map[string]interface{}); !reflect.DeepEqual(data["roles"], []interface{}{"USER", "ADMIN"}) {
+		t.Errorf("list: got %v", data["roles"])
+	}
+
+	for query, want := range map[string]string{
+		`{ role(is: OWNER) }`: "argument is: OWNER is not a value of enum Role",
+		`{ role(is: GUEST) }`: "argument is: GUEST is not a value of enum Role",
+		`{ guest }`:           "enum Role cannot represent value 42",
+	} {
+		errs, _ := run(query, nil)["errors"].([]*graphql.Error)
+		if len(errs) != 1 || errs[0].Message != want {
+			t.Errorf("%s: expected error %q, got %v", query, want, errs)
+		}
+	}
+}
+
+func TestBuiltinScalars(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+scalar DateTime
+scalar UUID
+scalar JSON
+type Query {
+  id: ID, score: Float, at(t: DateTime): DateTime, key(u: UUID): UUID, raw(v: JSON): JSON, bad: DateTime
+}`)).ParseDocument())
+	var got map[string]interface{}
+	echo := func(name string) graphql.ResolverFunc {
+		return func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			got = args
+			return args[name], nil
+		}
+	}
+	exec.RegisterQueryResolver("id", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return 42, nil
+	})
+	exec.RegisterQueryResolver("score", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return 3, nil
+	})
+	exec.RegisterQueryResolver("at", echo("t"))
+	exec.RegisterQueryResolver("key", echo("u"))
+	exec.RegisterQueryResolver("raw", echo("v"))
+	exec.RegisterQueryResolver("bad", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return 42, nil
+	})
+
+	run := func(query string, variables map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), variables)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		return result
+	}
+
+	result := run(`{ id score }`, nil)
+	if want := map[string]interface{}{"id": "42", "score": 3.0}; !reflect.DeepEqual(result["data"], want) {
+		t.Errorf("ID and Float: expected %v, got %v", want, result["data"])
+	}
+
+	result = run(`query ($t: DateTime) { at(t: $t) }`, map[string]interface{}{"t": "2024-05-01T12:30:00+02:00"})
+	if at, ok := got["t"].(time.Time); !ok || !at.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("DateTime: expected a time.Time argument, got %#v", got["t"])
+	}
+	if at := result["data"].(map[string]interface{})["at"]; at != "2024-05-01T12:30:00+02:00" {
+		t.Errorf("DateTime: got %v", at)
+	}
+
+	result = run(`{ key(u: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8") }`, nil)
+	if key := result["data"].(map[string]interface{})["key"]; key != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("UUID: got %v", key)
+	}
+
+	result = run(`query ($v: JSON) { raw(v: $v) }`, map[string]interface{}{"v": map[string]interface{}{"a": []interface{}{1.0, "b"}}})
+	if want := map[string]interface{}{"a": []interface{}{1.0, "b"}}; !reflect.DeepEqual(result["data"].(map[string]interface{})["raw"], want) {
+		t.Errorf("JSON: got %v", result["data"])
+	}
+
+	for query, want := range map[string]string{
+		`{ at(t: "yesterday") }`: `argument t: "yesterday" is not an RFC 3339 DateTime`,
+		`{ key(u: "nope") }`:     "argument u: nope is not a valid UUID",
+		`{ bad }`:                "DateTime cannot represent int",
+	} {
+		errs, _ := run(query, nil)["errors"].([]*graphql.Error)
+		if len(errs) != 1 || errs[0].Message != want {
+			t.Errorf("%s: expected error %q, got %v", query, want, errs)
+		}
+	}
+
+	exec.RegisterScalar("UUID", graphql.Scalar{})
+	result = run(`{ key(u: "nope") }`, nil)
+	if key := result["data"].(map[string]interface{})["key"]; key != "nope" {
+		t.Errorf("registered scalar: got %v", key)
+	}
+}
+
+type leafPoint struct{ X, Y int }
+
+func (p leafPoint) String() string { return fmt.Sprintf("(%d, %d)", p.X, p.Y) }
+
+type leafMoney struct{ cents int }
+
+func (m leafMoney) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"amount":%d.%02d}`, m.cents/100, m.cents%100)), nil
+}
+
+type leafColor struct{ r, g, b uint8 }
+
+func (c leafColor) MarshalGraphQL() (interface{}, error) {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b), nil
+}
+
+func (c leafColor) String() string { return "not used" }
+
+func TestLeafSerialization(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+type Query { at: String, atPtr: String, point: String, price: Money, color: String, points: [String] }
+scalar Money`)).ParseDocument())
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	for field, value := range map[string]interface{}{
+		"at":     at,
+		"atPtr":  &at,
+		"point":  leafPoint{1, 2},
+		"price":  leafMoney{1999},
+		"color":  leafColor{255, 128, 0},
+		"points": []leafPoint{{0, 0}, {3, 4}},
+	} {
+		value := value
+		exec.RegisterQueryResolver(field, func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return value, nil
+		})
+	}
+	doc := graphql.NewParser(graphql.NewLexer(`{ at atPtr point price color points }`)).ParseDocument()
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"at":     "2024-05-01T10:30:00Z",
+		"atPtr":  "2024-05-01T10:30:00Z",
+		"point":  "(1, 2)",
+		"price":  map[string]interface{}{"amount": 19.99},
+		"color":  "#ff8000",
+		"points": []interface{}{"(0, 0)", "(3, 4)"},
+	}
+	if !reflect.DeepEqual(result["data"], want) {
+		t.Errorf("expected %v, got %v", want, result["data"])
+	}
+}
+
+type typedUserArgs struct {
+	ID     string
+	Limit  int `graphql:"first"`
+	Filter *struct {
+		Roles []string
+	}
+}
+
+func TestTypedResolvers(t *testing.T) {
+	exec := graphql.NewExecutor()
+	var got typedUserArgs
+	exec.RegisterQueryResolverContext("user", graphql.Typed(func(ctx context.Context, args typedUserArgs) (map[string]interface{}, error) {
+		got = args
+		return map[string]interface{}{"id": args.ID}, nil
+	}))
+	exec.RegisterQueryResolverContext("count", graphql.Typed(func(ctx context.Context, args struct{}) (int, error) {
+		return 3, nil
+	}))
+
+	run := func(query string) map[string]interface{} {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		return result
+	}
+
+	result := run(`{ user(id: "7", first: 2, filter: {roles: ["ADMIN"]}) { id } count }`)
+	if want := map[string]interface{}{"user": map[string]interface{}{"id": "7"}, "count": 3}; !reflect.DeepEqual(result["data"], want) {
+		t.Errorf("expected %v, got %v", want, result["data"])
+	}
+	if got.ID != "7" || got.Limit != 2 || got.Filter == nil || !reflect.DeepEqual(got.Filter.Roles, []string{"ADMIN"}) {
+		t.Errorf("unexpected args %+v", got)
+	}
+
+	errs, _ := run(`{ user(first: "many") { id } }`)["errors"].([]*graphql.Error)
+	if want := "invalid arguments: variables: field Limit: cannot unmarshal string into int"; len(errs) != 1 || errs[0].Message != want {
+		t.Errorf("expected error %q, got %v", want, errs)
+	}
+}
+
+func TestInputObjects(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+input Address { street: String, zip: String!, country: String = "PL" }
+input UserInput { name: String!, tags: [String!], address: Address }
+type Query { save(input: UserInput): Boolean }`)).ParseDocument())
+	var got map[string]interface{}
+	exec.RegisterQueryResolver("save", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got, _ = args["input"].(map[string]interface{})
+		return true, nil
+	})
+	run := func(query string, variables map[string]interface{}) (map[string]interface{}, error) {
+		return exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), variables)
+	}
+
+	_, err := run(`query ($in: UserInput) { save(input: $in) }`, map[string]interface{}{
+		"in": map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"zip": "00-001"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"zip": "00-001", "country": "PL"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for _, tc := range []struct {
+		in   map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"street": "Main"}}, "invalid value at variables.in.address.zip: required field of type String! not provided"},
+		{map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"zip": nil}}, "invalid value at variables.in.address.zip: null for non-null type String!"},
+		{map[string]interface{}{"name": "Ann", "nickname": "A"}, "invalid value at variables.in.nickname: field is not defined by input type UserInput"},
+		{map[string]interface{}{"name": "Ann", "address": "Main St"}, "invalid value at variables.in.address: Main St is not an object of input type Address"},
+	} {
+		result, err := run(`query ($in: UserInput) { save(input: $in) }`, map[string]interface{}{"in": tc.in})
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%v: expected error %q, got %v", tc.in, tc.want, err)
+		}
+		if result["data"] != nil {
+			t.Errorf("%v: expected no data, got %v", tc.in, result["data"])
+		}
+	}
+
+	for _, tc := range []struct {
+		variables map[string]interface{}
+		want      string
+	}{
+		{nil, "invalid value at variables.in: variable of required type UserInput! was not provided"},
+		{map[string]interface{}{"in": nil}, "invalid value at variables.in: null for non-null type UserInput!"},
+	} {
+		_, err := run(`query ($in: UserInput!) { save(input: $in) }`, tc.variables)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%v: expected error %q, got %v", tc.variables, tc.want, err)
+		}
+	}
+	if _, err := run(`query ($in: UserInput! = {name: "Ann"}) { save(input: $in) }`, nil); err != nil {
+		t.Errorf("expected a default to provide a required variable, got %v", err)
+	}
+
+	result, err := run(`{ save(input: {name: "Ann", address: {zip: "1", city: "X"}}) }`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	errs, _ := result["errors"].([]*graphql.Error)
+	if want := "argument input.address.city: field is not defined by input type Address"; len(errs) != 1 || errs[0].Message != want {
+		t.Errorf("literal: expected error %q, got %v", want, errs)
+	}
+}
+
+func TestResponseLimits(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+type Row { id: Int, name: String }
+type Query { ids: [Int], rows: [Row] }`)).ParseDocument())
+	exec.RegisterQueryResolver("ids", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ids := make([]int, 1000000)
+		for i := range ids {
+			ids[i] = i
+		}
+		return ids, nil
+	})
+	exec.RegisterQueryResolver("rows", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		rows := make([]map[string]interface{}, 1000000)
+		for i := range rows {
+			rows[i] = map[string]interface{}{"id": i, "name": "row"}
+		}
+		return rows, nil
+	})
+	run := func(query string) map[string]interface{} {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		return result
+	}
+
+	exec.SetMaxNodes(5)
+	result := run(`{ ids }`)
+	// The list itself is the first node.
+	if ids := result["data"].(map[string]interface{})["ids"]; !reflect.DeepEqual(ids, []interface{}{0, 1, 2, 3}) {
+		t.Errorf("nodes: got %v", ids)
+	}
+	errs, _ := result["errors"].([]*graphql.Error)
+	if len(errs) != 1 || errs[0].Message != "response exceeds the limit of 5 nodes and was truncated" || !reflect.DeepEqual(errs[0].Path, []interface{}{"ids", 4}) {
+		t.Errorf("nodes: unexpected errors %v", errs)
+	}
+
+	exec.SetMaxNodes(0)
+	exec.SetMaxResponseSize(100)
+	result = run(`{ rows { id name } }`)
+	data, _ := json.Marshal(result["data"])
+	// The limit is approximate: the field names of the last object started
+	// may exceed it.
+	if len(data) > 120 || !strings.HasPrefix(string(data), `{"rows":[{"id":null,"name":null},`) {
+		t.Errorf("size: got %d bytes: %s", len(data), data)
+	}
+	errs, _ = result["errors"].([]*graphql.Error)
+	if len(errs) != 1 || errs[0].Message != "response exceeds the limit of 100 bytes and was truncated" {
+		t.Errorf("size: unexpected errors %v", errs)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`type Query { hello: String }`)).ParseDocument())
+	calls := 0
+	exec.RegisterQueryResolver("hello", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return "world", nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ hello(name: "x") { length } }`)).ParseDocument()
+
+	if _, err := exec.Execute(doc, nil); err != nil || calls != 1 {
+		t.Fatalf("without validation: expected the field to resolve, got %v", err)
+	}
+	exec.SetValidation(true)
+	_, err := exec.Execute(doc, nil)
+	want := `unknown argument "name" on field "Query.hello"` + "\n" + `field "hello" must not have a selection since type "String" has no subfields`
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no field to resolve after a validation error")
+	}
+}
+
+func TestArgsPresence(t *testing.T) {
+	exec := graphql.NewExecutor()
+	var got graphql.Args
+	exec.RegisterMutationResolver("updateUser", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return true, nil
+	})
+	run := func(query string, variables map[string]interface{}) {
+		t.Helper()
+		if _, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), variables); err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+	}
+
+	run(`mutation { updateUser(name: "Ann", nickname: null) }`, nil)
+	if !got.Has("name") || got.IsNull("name") || !got.Has("nickname") || !got.IsNull("nickname") || got.Has("email") {
+		t.Errorf("literals: unexpected args %v", got)
+	}
+
+	run(`mutation ($nick: String, $email: String) { updateUser(nickname: $nick, email: $email, input: {nickname: $nick}) }`,
+		map[string]interface{}{"nick": nil})
+	if !got.IsNull("nickname") || got.Has("email") {
+		t.Errorf("variables: unexpected args %v", got)
+	}
+	if input, _ := got.Get("input"); !graphql.Args(input.(map[string]interface{})).IsNull("nickname") {
+		t.Errorf("input object: unexpected value %v", input)
+	}
+
+	run(`mutation ($email: String) { updateUser(input: {email: $email}) }`, nil)
+	if input, _ := got.Get("input"); graphql.Args(input.(map[string]interface{})).Has("email") {
+		t.Errorf("input object: expected email to be absent, got %v", input)
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+scalar Int64
+scalar BigInt
+type Query { id(v: Int64): Int64, total(v: BigInt): BigInt }`)).ParseDocument())
+	var got interface{}
+	exec.RegisterQueryResolver("id", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args["v"]
+		return args["v"], nil
+	})
+	exec.RegisterQueryResolver("total", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args["v"]
+		return new(big.Int).Mul(args["v"].(*big.Int), big.NewInt(10)), nil
+	})
+	run := func(query string, variables map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), variables)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		return result
+	}
+
+	for _, v := range []interface{}{json.Number("9007199254740993"), "9007199254740993"} {
+		result := run(`query ($v: Int64) { id(v: $v) }`, map[string]interface{}{"v": v})
+		if got != int64(9007199254740993) || result["data"].(map[string]interface{})["id"] != "9007199254740993" {
+			t.Errorf("Int64 from %T: got argument %#v and data %v", v, got, result["data"])
+		}
+	}
+	result := run(`{ id(v: 42) }`, nil)
+	if got != int64(42) || result["data"].(map[string]interface{})["id"] != "42" {
+		t.Errorf("Int64 literal: got argument %#v and data %v", got, result["data"])
+	}
+
+	result = run(`{ total(v: 123456789012345678901234567890) }`, nil)
+	if result["data"].(map[string]interface{})["total"] != "1234567890123456789012345678900" {
+		t.Errorf("BigInt literal: got %v", result["data"])
+	}
+
+	errs, _ := run(`{ id(v: 123456789012345678901234567890) }`, nil)["errors"].([]*graphql.Error)
+	if want := "argument v: Int64 cannot represent 123456789012345678901234567890"; len(errs) != 1 || errs[0].Message != want {
+		t.Errorf("expected error %q, got %v", want, errs)
+	}
+}
+
+func TestConcurrencyLimits(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetFieldTimeout(20 * time.Millisecond)
+	exec.SetMaxGoroutines(1)
+	release := make(chan struct{})
+	defer close(release)
+	// Each call ignores its context, so its goroutine outlives the timeout.
+	exec.RegisterQueryResolver("stuck", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		<-release
+		return "late", nil
+	})
+	exec.RegisterQueryResolver("quick", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ stuck quick }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]interface{}{"stuck": nil, "quick": "ok"}; !reflect.DeepEqual(result["data"], want) {
+		t.Errorf("expected %v, got %v", want, result["data"])
+	}
+	// quick ran on the executing goroutine as stuck used up the budget.
+	if stats := exec.PoolStats(); stats.Running != 1 || stats.Peak != 1 || stats.Saturated != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	exec.SetMaxGoroutines(0)
+	exec.SetWorkerPool(1)
+	if _, err := exec.Execute(graphql.NewParser(graphql.NewLexer(`{ quick }`)).ParseDocument(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The pool was replaced, so the stuck goroutine no longer holds a slot.
+	if stats := exec.PoolStats(); stats.Saturated != 1 || stats.Peak != 2 {
+		t.Errorf("unexpected stats after resizing the pool %+v", stats)
+	}
+}
+
+type resolverRow struct {
+	ID    string
+	Name  string
+	Title string
+}
+
+func TestTypeResolvers(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+interface Node { id: ID! }
+type User implements Node { id: ID! name: String }
+type Post implements Node { id: ID! title: String }
+union Result = User | Post
+type Query { nodes: [Node], search: [Result] }`)).ParseDocument())
+	resolveType := func(value interface{}) string {
+		if strings.HasPrefix(value.(*resolverRow).ID, "u") {
+			return "User"
+		}
+		return "Post"
+	}
+	exec.RegisterTypeResolver("Node", resolveType)
+	exec.RegisterTypeResolver("Result", resolveType)
+	rows := func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []*resolverRow{{ID: "u1", Name: "Ada"}, {ID: "p1", Title: "Hello"}}, nil
+	}
+	exec.RegisterQueryResolver("nodes", rows)
+	exec.RegisterQueryResolver("search", rows)
+
+	result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(`{
+  nodes { __typename id ... on User { name } ... on Post { title } }
+  search { __typename ...PostTitle }
+}
+fragment PostTitle on Post { title }`)).ParseDocument(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"nodes":[{"__typename":"User","id":"u1","name":"Ada"},{"__typename":"Post","id":"p1","title":"Hello"}],` +
+		`"search":[{"__typename":"User"},{"__typename":"Post","title":"Hello"}]}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestMapSources(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"id":     "1",
+			"Name":   "Ada",
+			"posts":  []map[string]interface{}{{"title": "Hello"}, {"title": "World"}},
+			"author": json.RawMessage(`{"__typename":"Admin","name":"Grace"}`),
+		}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(
+		`{ user { id name missing posts { title } author { ... on Admin { name } } } }`)).ParseDocument()
+
+	for _, filter := range []bool{false, true} {
+		exec.SetFilterRawJSON(filter)
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, _ := json.Marshal(result)
+		author := `{"__typename":"Admin","name":"Grace"}`
+		if filter {
+			author = `{"name":"Grace"}`
+		}
+		want := `{"data":{"user":{"author":` + author + `,"id":"1","missing":null,"name":"Ada","posts":[{"title":"Hello"},{"title":"World"}]}}}`
+		if string(out) != want {
+			t.Errorf("filter=%v: expected %s, got %s", filter, want, out)
+		}
+	}
+}
+
+type methodUser struct {
+	Name    string
+	friends []*methodUser
+}
+
+func (u *methodUser) Friends(ctx context.Context, args map[string]interface{}) ([]*methodUser, error) {
+	if first, ok := args["first"].(int); ok && first < len(u.friends) {
+		return u.friends[:first], nil
+	}
+	return u.friends, nil
+}
+
+func (u methodUser) Greeting() string { return "Hello, " + u.Name }
+
+func (u *methodUser) Secret(ctx context.Context) (string, error) {
+	return "", errors.New("forbidden")
+}
+
+func TestMethodResolution(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+		type Query { user: User }
+		type User { name: String greeting: String secret: String friends(first: Int): [User] }
+	`)).ParseDocument())
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return methodUser{Name: "Ada", friends: []*methodUser{{Name: "Grace"}, {Name: "Alan"}}}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(
+		`{ user { greeting secret friends(first: 1) { name greeting } } }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"user":{"friends":[{"greeting":"Hello, Grace","name":"Grace"}],"greeting":"Hello, Ada","secret":null}},"errors":[{"message":"forbidden","locations":[{"line":1,"column":19}],"path":["user","secret"]}]}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+type taggedRow struct {
+	UserID   string `db:"user_id" json:"user_id" graphql:"userId"`
+	Email    string `json:"email" graphql:"-"`
+	FullName string `db:"full_name" graphql:",omitempty"`
+}
+
+func TestGraphqlStructTag(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetMissingFieldPolicy(graphql.MissingFieldNull)
+	exec.RegisterQueryResolver("row", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return taggedRow{UserID: "u1", Email: "ada@example.com", FullName: "Ada"}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ row { userId user_id email fullName } }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The graphql tag replaces the json tag and hides fields tagged "-";
+	// an empty name keeps the default names.
+	want := map[string]interface{}{"userId": "u1", "user_id": nil, "email": nil, "fullName": "Ada"}
+	if got := result["data"].(map[string]interface{})["row"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	newExec := func() *graphql.Executor {
+		exec := graphql.NewExecutor()
+		exec.RegisterQueryResolver("fast", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return "done", nil
+		})
+		// stuck ignores its context.
+		exec.RegisterQueryResolver("stuck", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			time.Sleep(time.Second)
+			return "late", nil
+		})
+		exec.RegisterQueryResolverContext("wait", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		return exec
+	}
+	run := func(exec *graphql.Executor, query string) string {
+		t.Helper()
+		result, err := exec.Execute(graphql.NewParser(graphql.NewLexer(query)).ParseDocument(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, _ := json.Marshal(result)
+		return string(out)
+	}
+
+	exec := newExec()
+	exec.SetTimeout(20 * time.Millisecond)
+	start := time.Now()
+	got := run(exec, `{ fast stuck wait again: fast }`)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("execution took %v despite the timeout", elapsed)
+	}
+	want := `{"data":{"again":null,"fast":"done","stuck":null,"wait":null},"errors":[{"message":"execution timed out","locations":[{"line":1,"column":8}],"path":["stuck"]}]}`
+	if got != want {
+		t.Errorf("execution timeout: expected %s, got %s", want, got)
+	}
+
+	exec = newExec()
+	exec.SetFieldTimeout(20 * time.Millisecond)
+	got = run(exec, `{ wait stuck fast }`)
+	want = `{"data":{"fast":"done","stuck":null,"wait":null},"errors":[{"message":"field timed out","locations":[{"line":1,"column":3}],"path":["wait"]},{"message":"field timed out","locations":[{"line":1,"column":8}],"path":["stuck"]}]}`
+	if got != want {
+		t.Errorf("field timeout: expected %s, got %s", want, got)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	exec := graphql.NewExecutor()
+	resolved := false
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		resolved = true
+		return map[string]interface{}{"name": "Ada"}, nil
+	})
+	exec.SetMaxDepth(3)
+
+	ok := graphql.NewParser(graphql.NewLexer(`{ user { ...F } } fragment F on User { friends { name } }`)).ParseDocument()
+	if _, err := exec.Execute(ok, nil); err != nil {
+		t.Fatalf("depth 3: %v", err)
+	}
+
+	resolved = false
+	deep := graphql.NewParser(graphql.NewLexer(`{ user { ...F } } fragment F on User { friends { friends { name } } }`)).ParseDocument()
+	want := "operation depth 4 exceeds the limit of 3"
+	if _, err := exec.Execute(deep, nil); err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+	if resolved {
+		t.Error("expected no field to be resolved")
+	}
+
+	// Cyclic fragments do not recurse forever.
+	cyclic := graphql.NewParser(graphql.NewLexer(`{ user { ...A } } fragment A on User { name ...B } fragment B on User { ...A }`)).ParseDocument()
+	if _, err := exec.Execute(cyclic, nil); err != nil {
+		t.Errorf("cyclic fragments: %v", err)
+	}
+}
+
+func TestCheckDepthMeasuresFragmentsOnce(t *testing.T) {
+	// Each fragment spreads the next twice, so expanding every spread
+	// would measure the last fragment 2^25 times.
+	var sb strings.Builder
+	sb.WriteString(`{ user { ...F0 } }`)
+	for i := 0; i < 26; i++ {
+		fmt.Fprintf(&sb, " fragment F%d on User { friends { id }", i)
+		if i < 25 {
+			fmt.Fprintf(&sb, " ...F%d ...F%d", i+1, i+1)
+		}
+		sb.WriteString(" }")
+	}
+	doc := graphql.NewParser(graphql.NewLexer(sb.String())).ParseDocument()
+	start := time.Now()
+	if err := executor.CheckDepth(doc, 3); err != nil {
+		t.Errorf("depth 3: %v", err)
+	}
+	if err := executor.CheckDepth(doc, 2); err == nil || err.Error() != "operation depth 3 exceeds the limit of 2" {
+		t.Errorf("expected depth 3 to exceed the limit, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected each fragment to be measured once, took %v", elapsed)
+	}
+
+	// Walking stops once the limit is exceeded.
+	deep := graphql.NewParser(graphql.NewLexer(`{ a { b { c { d { e } } } } }`)).ParseDocument()
+	if err := executor.CheckDepth(deep, 2); err == nil || err.Error() != "operation depth 3 exceeds the limit of 2" {
+		t.Errorf("expected the depth past the limit, got %v", err)
+	}
+}
+
+func TestResolveInfo(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+		type Query { user(id: ID): User }
+		type User { id: ID name: String email: String }
+	`)).ParseDocument())
+	var info *graphql.ResolveInfo
+	var selected []string
+	exec.RegisterQueryResolverContext("user", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		info = graphql.ResolveInfoFromContext(ctx)
+		selected = info.SelectedFields()
+		return map[string]interface{}{"id": "1", "name": "Ada"}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(
+		`query Q($id: ID) { me: user(id: $id) { id ...F name } } fragment F on User { name email }`)).ParseDocument()
+	if _, err := exec.Execute(doc, map[string]interface{}{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if info == nil {
+		t.Fatal("expected resolve info in the resolver context")
+	}
+	if info.FieldName != "user" || info.Alias != "me" || info.ParentType != "Query" {
+		t.Errorf("unexpected field info %q %q %q", info.FieldName, info.Alias, info.ParentType)
+	}
+	if !reflect.DeepEqual(info.Path, []interface{}{"me"}) {
+		t.Errorf("unexpected path %v", info.Path)
+	}
+	if info.ReturnType.NamedType() != "User" || info.Variables["id"] != "1" {
+		t.Errorf("unexpected return type %v or variables %v", info.ReturnType, info.Variables)
+	}
+	if want := []string{"id", "name", "email"}; !reflect.DeepEqual(selected, want) {
+		t.Errorf("expected selected fields %v, got %v", want, selected)
+	}
+	if graphql.ResolveInfoFromContext(context.Background()) != nil {
+		t.Error("expected no resolve info outside of a resolver")
+	}
+}
+
+func TestDataLoaderScope(t *testing.T) {
+	exec := graphql.NewExecutor()
+	calls := 0
+	load := func(ctx context.Context, keys []string) ([]string, []error) {
+		calls++
+		return keys, nil
+	}
+	exec.RegisterQueryResolverContext("user", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return dataloader.For(ctx, "users", load).Load(ctx, args["id"].(string))
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ a: user(id: "1") b: user(id: "1") }`)).ParseDocument()
+
+	for i := 1; i <= 2; i++ {
+		result, err := exec.Execute(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]interface{}{"a": "1", "b": "1"}; !reflect.DeepEqual(result["data"], want) {
+			t.Errorf("expected %v, got %v", want, result["data"])
+		}
+		// The loader caches within an operation but not across operations.
+		if calls != i {
+			t.Errorf("operation %d: expected %d batch calls, got %d", i, i, calls)
+		}
+	}
+}
+
+type memoUser struct{ calls *int }
+
+func (u *memoUser) Score(ctx context.Context, args map[string]interface{}) (int, error) {
+	*u.calls++
+	if args["fail"] == true {
+		return 0, errors.New("no score")
+	}
+	return 42, nil
+}
+
+func TestMemoization(t *testing.T) {
+	rootCalls, scoreCalls := 0, 0
+	user := &memoUser{calls: &scoreCalls}
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		rootCalls++
+		return user, nil
+	})
+	exec.RegisterMutationResolver("bump", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		rootCalls++
+		return rootCalls, nil
+	})
+	query := graphql.NewParser(graphql.NewLexer(
+		`{ a: user { s: score t: score f: score(fail: true) g: score(fail: true) } b: user { score } }`)).ParseDocument()
+	mutation := graphql.NewParser(graphql.NewLexer(`mutation { a: bump b: bump }`)).ParseDocument()
+
+	exec.SetMemoization(true)
+	result, err := exec.Execute(query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootCalls != 1 || scoreCalls != 2 {
+		t.Errorf("expected 1 user and 2 score calls, got %d and %d", rootCalls, scoreCalls)
+	}
+	out, _ := json.Marshal(result["errors"])
+	if want := `[{"message":"no score","locations":[{"line":1,"column":31}],"path":["a","f"]},{"message":"no score","locations":[{"line":1,"column":52}],"path":["a","g"]}]`; string(out) != want {
+		t.Errorf("expected errors %s, got %s", want, out)
+	}
+
+	rootCalls = 0
+	if _, err := exec.Execute(mutation, nil); err != nil {
+		t.Fatal(err)
+	}
+	if rootCalls != 2 {
+		t.Errorf("expected mutation fields to run every time, got %d calls", rootCalls)
+	}
+
+	rootCalls, scoreCalls = 0, 0
+	exec.SetMemoization(false)
+	if _, err := exec.Execute(query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if rootCalls != 2 || scoreCalls != 5 {
+		t.Errorf("expected no memoization when disabled, got %d and %d calls", rootCalls, scoreCalls)
+	}
+}
+
+type thunkUser struct {
+	ID        string
+	FriendIDs []string
+}
+
+func (u *thunkUser) Friends(ctx context.Context) func() ([]*thunkUser, error) {
+	loader := dataloader.For(ctx, "users", func(ctx context.Context, keys []string) ([]*thunkUser, []error) {
+		batches := ctx.Value(batchesKey{}).(*[][]string)
+		*batches = append(*batches, keys)
+		users := make([]*thunkUser, len(keys))
+		for i, key := range keys {
+			users[i] = &thunkUser{ID: key}
+		}
+		return users, nil
+	})
+	thunks := make([]func() (*thunkUser, error), len(u.FriendIDs))
+	for i, id := range u.FriendIDs {
+		thunks[i] = loader.LoadThunk(ctx, id)
+	}
+	return func() ([]*thunkUser, error) {
+		friends := make([]*thunkUser, len(thunks))
+		for i, thunk := range thunks {
+			friend, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			friends[i] = friend
+		}
+		return friends, nil
+	}
+}
+
+type batchesKey struct{}
+
+func TestThunks(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []*thunkUser{{ID: "1", FriendIDs: []string{"2", "3"}}, {ID: "2", FriendIDs: []string{"3", "4"}}}, nil
+	})
+	exec.RegisterQueryResolver("answer", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return graphql.Thunk(func() (interface{}, error) { return 42, nil }), nil
+	})
+	exec.RegisterQueryResolver("broken", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return func() (interface{}, error) { return nil, errors.New("boom") }, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ users { id friends { id } } answer broken }`)).ParseDocument()
+
+	var batches [][]string
+	result, err := exec.ExecuteContext(context.WithValue(context.Background(), batchesKey{}, &batches), doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	want := `{"data":{"answer":42,"broken":null,"users":[{"friends":[{"id":"2"},{"id":"3"}],"id":"1"},{"friends":[{"id":"3"},{"id":"4"}],"id":"2"}]},"errors":[{"message":"boom","locations":[{"line":1,"column":38}],"path":["broken"]}]}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	// The friends of every user are loaded in a single batch.
+	if want := [][]string{{"2", "3", "4"}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("expected batches %v, got %v", want, batches)
+	}
+}
+
+type timedUser struct{ ID int }
+
+func (u *timedUser) Name(ctx context.Context) func() (string, error) {
+	return dataloader.For(ctx, "names", func(ctx context.Context, keys []int) ([]string, []error) {
+		if err := ctx.Err(); err != nil {
+			return nil, []error{err}
+		}
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = fmt.Sprintf("user%d", key)
+		}
+		return names, nil
+	}).LoadThunk(ctx, u.ID)
+}
+
+func TestThunksWithFieldTimeout(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetFieldTimeout(time.Second)
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		users := make([]*timedUser, 20)
+		for i := range users {
+			users[i] = &timedUser{ID: i}
+		}
+		return users, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ users { name } }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["errors"] != nil {
+		t.Errorf("expected batches to outlive the field timeout contexts, got %v", result["errors"])
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, gqlerrors.WithCode(gqlerrors.Newf("user %v not found", args["id"]), "NOT_FOUND")
+	})
+	exec.RegisterQueryResolver("custom", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, &gqlerrors.Error{Message: "custom", Path: []interface{}{"elsewhere"}, Locations: []gqlerrors.Location{{Line: 9, Column: 9}}}
+	})
+	doc := graphql.NewParser(graphql.NewLexer("{\n  user(id: 7)\n  custom\n}")).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result["errors"])
+	want := `[{"message":"user 7 not found","locations":[{"line":2,"column":3}],"path":["user"],"extensions":{"code":"NOT_FOUND"}},` +
+		`{"message":"custom","locations":[{"line":9,"column":9}],"path":["elsewhere"]}]`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestErrorPresenter(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New(`pq: relation "users" does not exist`)
+	})
+	exec.RegisterQueryResolver("me", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, gqlerrors.WithCode(gqlerrors.New("not signed in"), "UNAUTHENTICATED")
+	})
+	var logged []string
+	exec.SetErrorPresenter(func(ctx context.Context, err error) *graphql.Error {
+		var public *gqlerrors.Error
+		if errors.As(err, &public) && public.Err == nil {
+			return public
+		}
+		logged = append(logged, err.Error())
+		return gqlerrors.New("internal server error")
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ user me }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result["errors"])
+	want := `[{"message":"internal server error","locations":[{"line":1,"column":3}],"path":["user"]},` +
+		`{"message":"not signed in","locations":[{"line":1,"column":8}],"path":["me"],"extensions":{"code":"UNAUTHENTICATED"}}]`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	if want := []string{`pq: relation "users" does not exist`}; !reflect.DeepEqual(logged, want) {
+		t.Errorf("expected the original error to be logged, got %v", logged)
+	}
+}
+
+type traceKey struct{}
+
+// recordingTracer records the phases it observes.
+type recordingTracer struct {
+	graphql.NopTracer
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingTracer) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingTracer) StartExecute(ctx context.Context, op *graphql.OperationDefinition) (context.Context, graphql.FinishFunc) {
+	r.record("execute " + op.Operation)
+	return ctx, func(d time.Duration, err error) { r.record(fmt.Sprintf("executed %v", err)) }
+}
+
+func (r *recordingTracer) StartField(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.FinishFunc) {
+	r.record("field " + formatTracePath(info.Path))
+	ctx = context.WithValue(ctx, traceKey{}, info.FieldName)
+	return ctx, func(d time.Duration, err error) {
+		r.record(fmt.Sprintf("resolved %s %v", formatTracePath(info.Path), err))
+	}
+}
+
+func formatTracePath(path []interface{}) string {
+	return strings.Trim(fmt.Sprint(path), "[]")
+}
+
+func TestTracer(t *testing.T) {
+	exec := graphql.NewExecutor()
+	tracer := &recordingTracer{}
+	exec.SetTracer(tracer)
+	exec.RegisterQueryResolverContext("user", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		// The context returned by StartField reaches the resolver.
+		if ctx.Value(traceKey{}) != "user" {
+			t.Error("expected the tracer's context in the resolver")
+		}
+		return map[string]interface{}{"name": "Ada"}, nil
+	})
+	exec.RegisterQueryResolver("broken", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ user { name } broken }`)).ParseDocument()
+	if _, err := exec.Execute(doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"execute query",
+		"field user", "resolved user <nil>",
+		"field broken", "resolved broken boom",
+		"field user name", "resolved user name <nil>",
+		"executed <nil>",
+	}
+	if !reflect.DeepEqual(tracer.events, want) {
+		t.Errorf("expected events %q, got %q", want, tracer.events)
+	}
+}
+
+func TestResponseExtensions(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetMissingFieldPolicy(graphql.MissingFieldNullWithWarning)
+	exec.RegisterQueryResolverContext("user", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		graphql.SetExtension(ctx, "cacheControl", map[string]interface{}{"maxAge": 60})
+		return &devModeUser{Name: "Ada"}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ user { name nickname } }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result["extensions"])
+	want := `{"cacheControl":{"maxAge":60},"warnings":[{"message":"no resolver found for field nickname via reflection","path":["user","nickname"]}]}`
+	if string(out) != want {
+		t.Errorf("expected extensions %s, got %s", want, out)
+	}
+
+	// Outside of an execution, SetExtension does nothing.
+	graphql.SetExtension(context.Background(), "ignored", true)
+}
+
+func TestResponseExtensionsAfterTimeout(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetFieldTimeout(10 * time.Millisecond)
+	release, done := make(chan struct{}), make(chan struct{})
+	// The resolver ignores its context, so it sets its extension after
+	// the response was returned.
+	exec.RegisterQueryResolverContext("slow", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		defer close(done)
+		graphql.SetExtension(ctx, "early", true)
+		<-release
+		graphql.SetExtension(ctx, "late", true)
+		return "late", nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ slow }`)).ParseDocument()
+
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	out, _ := json.Marshal(result["extensions"])
+	<-done
+	if want := `{"early":true}`; string(out) != want {
+		t.Errorf("expected extensions %s, got %s", want, out)
+	}
+}
+
+func TestOrderedFields(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"name": "Ada", "id": "1", "age": 36}, nil
+	})
+	exec.RegisterQueryResolver("zone", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "UTC", nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`{ zone user { name ...F id } } fragment F on User { age name }`)).ParseDocument()
+
+	exec.SetOrderedFields(true)
+	result, err := exec.Execute(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(result)
+	if want := `{"data":{"zone":"UTC","user":{"name":"Ada","age":36,"id":"1"}}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	user, _ := result["data"].(*graphql.OrderedMap).Get("user")
+	if name, _ := user.(*graphql.OrderedMap).Get("name"); name != "Ada" {
+		t.Errorf("expected name Ada, got %v", name)
+	}
+
+	exec.SetOrderedFields(false)
+	result, _ = exec.Execute(doc, nil)
+	out, _ = json.Marshal(result)
+	if want := `{"data":{"user":{"age":36,"id":"1","name":"Ada"},"zone":"UTC"}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+}
+
+func TestPreparedQuery(t *testing.T) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+		type Query { user(id: ID!): User }
+		type User { id: ID name: String }
+	`)).ParseDocument())
+	exec.RegisterQueryResolver("user", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"id": args["id"], "name": "user" + args["id"].(string)}, nil
+	})
+	doc := graphql.NewParser(graphql.NewLexer(`
+		query Ids($id: ID!) { user(id: $id) { id } }
+		query Names($id: ID!) { user(id: $id) { ...F } }
+		fragment F on User { name }
+	`)).ParseDocument()
+
+	q, err := exec.Prepare(doc, "Names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "2"} {
+		result, err := q.Execute(map[string]interface{}{"id": id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{"user": map[string]interface{}{"name": "user" + id}}
+		if !reflect.DeepEqual(result["data"], want) {
+			t.Errorf("id %s: expected %v, got %v", id, want, result["data"])
+		}
+	}
+
+	if _, err := exec.Prepare(doc, "Missing"); err == nil || !strings.Contains(err.Error(), `unknown operation "Missing"`) {
+		t.Errorf("expected an unknown operation error, got %v", err)
+	}
+	invalid := graphql.NewParser(graphql.NewLexer(`{ user(id: "1") { email } }`)).ParseDocument()
+	if _, err := exec.Prepare(invalid, ""); err == nil {
+		t.Error("expected a validation error")
+	}
+}
+
+// benchmarkQuery is a typical query with arguments, aliases, nested lists
+// and a fragment.
+const benchmarkQuery = `query Users($first: Int) {
+  users(first: $first) {
+    id
+    name
+    friends { ...friend }
+    best: friends(first: 1) { id name }
+  }
+}
+fragment friend on User { id name email }`
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		graphql.NewParser(graphql.NewLexer(benchmarkQuery)).ParseDocument()
+	}
+}
+
+func BenchmarkParseAndExecute(b *testing.B) {
+	exec := graphql.NewExecutor()
+	exec.SetSchema(graphql.NewParser(graphql.NewLexer(`
+type User { id: ID!, name: String, email: String, friends(first: Int): [User] }
+type Query { users(first: Int): [User] }`)).ParseDocument())
+	type user struct {
+		ID      string
+		Name    string
+		Email   string
+		Friends []*user
+	}
+	users := make([]*user, 20)
+	for i := range users {
+		users[i] = &user{ID: fmt.Sprint(i), Name: "User", Email: "user@example.com"}
+	}
+	for _, u := range users {
+		u.Friends = users[:5]
+	}
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return users, nil
+	})
+	variables := map[string]interface{}{"first": 20}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc := graphql.NewParser(graphql.NewLexer(benchmarkQuery)).ParseDocument()
+		if _, err := exec.Execute(doc, variables); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures the cross-origin requests browsers may make, so that
+// single-page applications served from other origins can call the API.
+type CORS struct {
+	// AllowedOrigins are the origins allowed to call the API, such as
+	// "https://app.example.com". "*" allows any origin, and a "*." prefix
+	// of the host allows its subdomains, as in "https://*.example.com".
+	AllowedOrigins []string
+	// AllowedHeaders are the request headers clients may send, such as
+	// "Authorization". Content-Type is always allowed.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers clients may read.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies or HTTP
+	// authentication from the allowed origins. It is ignored if
+	// AllowedOrigins contains "*", since any website could then read the
+	// responses to its visitors' credentialed requests.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request. Zero leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// cors configures cross-origin requests to the package-level handlers; nil
+// allows none.
+var cors *CORS
+
+// SetCORS configures the cross-origin requests the package-level handlers
+// accept, including the upgrade requests of Subscription. nil, the
+// default, sets no CORS headers and accepts subscriptions from any origin.
+func SetCORS(c *CORS) {
+	cors = c
+}
+
+// allowsOrigin reports whether requests from origin are allowed.
+func (c *CORS) allowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		scheme, host, ok := strings.Cut(allowed, "://*.")
+		if ok && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(host)) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsUpgrade reports whether the WebSocket upgrade request r comes from
+// the API's own origin or one c allows. Browsers do not apply CORS to
+// WebSockets, so this keeps other sites from using a visitor's cookies.
+// A nil c allows any origin.
+func (c *CORS) allowsUpgrade(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if c == nil || origin == "" || c.allowsOrigin(origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// handle sets the CORS headers of the response to r. It answers preflight
+// requests itself, rejecting those from origins that are not allowed, and
+// reports whether r still needs to be served. A nil c does nothing.
+func (c *CORS) handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if c == nil || origin == "" {
+		return true
+	}
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	w.Header().Add("Vary", "Origin")
+	if !c.allowsOrigin(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+	if c.allowsOrigin("*") {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if c.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+	if !preflight {
+		if len(c.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+		return true
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(append([]string{"Content-Type"}, c.AllowedHeaders...), ", "))
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return false
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	h := New(Config{CORS: &CORS{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}})
+
+	r := httptest.NewRequest("OPTIONS", "/graphql", nil)
+	r.Header.Set("Origin", "https://pr-1.preview.example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://pr-1.preview.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Max-Age":           "3600",
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for a preflight request, got %d", w.Code)
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	r = httptest.NewRequest("OPTIONS", "/graphql", nil)
+	r.Header.Set("Origin", "https://evil.example.org")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected a rejected preflight for another origin, got %d %v", w.Code, w.Header())
+	}
+
+	r = httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ __typename }"}`))
+	r.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("expected the request to be served with CORS headers, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestCORSIgnoresCredentialsForAnyOrigin(t *testing.T) {
+	h := New(Config{CORS: &CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}})
+	for _, method := range []string{"OPTIONS", "POST"} {
+		r := httptest.NewRequest(method, "/graphql", strings.NewReader(`{"query": "{ __typename }"}`))
+		r.Header.Set("Origin", "https://evil.example.org")
+		r.Header.Set("Access-Control-Request-Method", "POST")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s: expected Access-Control-Allow-Origin *, got %q", method, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("%s: expected no credentials for any origin, got %q", method, got)
+		}
+	}
+}
+
+func TestCORSSubscriptionOrigin(t *testing.T) {
+	SetCORS(&CORS{AllowedOrigins: []string{"https://app.example.com"}})
+	defer SetCORS(nil)
+
+	r := httptest.NewRequest("GET", "http://api.example.com/subscriptions", nil)
+	r.Header.Set("Origin", "https://evil.example.org")
+	w := httptest.NewRecorder()
+	Subscription(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a subscription from another origin, got %d", w.Code)
+	}
+
+	for _, origin := range []string{"https://app.example.com", "https://api.example.com"} {
+		r = httptest.NewRequest("GET", "http://api.example.com/subscriptions", nil)
+		r.Header.Set("Origin", origin)
+		if !cors.allowsUpgrade(r) {
+			t.Errorf("expected subscriptions from %s to be allowed", origin)
+		}
+	}
+}
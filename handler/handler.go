@@ -1,145 +1,593 @@
+// Package handler serves GraphQL over HTTP and subscriptions over
+// WebSocket.
+//
+// New creates a Handler configured by a Config. The package-level handler
+// functions, such as GraphQL and Subscription, serve the global executor
+// configured by the package-level Set functions. Those functions, like the
+// settings shared by all handlers (idempotency, tenants, the upgrader and
+// connection auth), are not synchronized: call them during initialization,
+// before serving requests.
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Protocol-Lattice/graphql/ast"
-	"github.com/Protocol-Lattice/graphql/lexer"
-	"github.com/Protocol-Lattice/graphql/parser"
-	"github.com/Protocol-Lattice/graphql/registry"
-	"github.com/gorilla/websocket"
+	"github.com/Protocol-Lattice/graphql/complexity"
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/executor"
 )
 
 // GraphQLRequest represents a standard GraphQL request.
 type GraphQLRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+	// DocumentID names a persisted document to run instead of Query; see
+	// SetPersistedDocuments.
+	DocumentID string `json:"documentId,omitempty"`
 }
 
-// GraphQL handles standard GraphQL HTTP requests.
-func GraphQL(w http.ResponseWriter, r *http.Request) {
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "unable to read body", http.StatusBadRequest)
+// Config configures a Handler. The zero value serves POST requests with
+// the global executor.
+type Config struct {
+	// Executor executes the operations of requests that have no tenant.
+	// It defaults to the global executor.
+	Executor *executor.Executor
+	// MaxBodySize limits the size of request bodies in bytes, including
+	// multipart uploads, as SetMaxBodySize does. Zero means no limit.
+	MaxBodySize int64
+	// AllowGET serves queries in GET requests. Mutations are never allowed
+	// in GET requests.
+	AllowGET bool
+	// MaxDepth rejects operations nesting fields deeper than MaxDepth, as
+	// SetMaxDepth does. Zero means no limit.
+	MaxDepth int
+	// Complexity estimates the cost of operations for MaxCost.
+	Complexity *complexity.Analyzer
+	// MaxCost rejects operations that Complexity estimates to cost more
+	// than MaxCost before they are executed. Zero means no limit.
+	MaxCost int
+	// ErrorPresenter presents the errors of responses instead of the
+	// executor's presenter.
+	ErrorPresenter executor.ErrorPresenter
+	// Tracer traces requests instead of the executor's tracer.
+	Tracer executor.Tracer
+	// LocaleFunc picks the locale of framework error messages. It defaults
+	// to AcceptLanguage.
+	LocaleFunc LocaleFunc
+	// PersistedDocuments are the only operations requests may run, as set
+	// by SetPersistedDocuments. nil allows any query.
+	PersistedDocuments *PersistedDocuments
+	// CORS configures cross-origin requests, as SetCORS does. nil allows
+	// none.
+	CORS *CORS
+	// ContextFunc derives the context resolvers receive from the request,
+	// such as to authenticate it by its Authorization header or session
+	// cookie. An error rejects the request with 401; an *executor.Error is
+	// presented as is and others with the UNAUTHENTICATED code.
+	ContextFunc func(ctx context.Context, r *http.Request) (context.Context, error)
+	// Hooks observe the lifecycle of requests. nil observes none. The
+	// execution of a subscription lasts until it ends, and its response is
+	// written once the connection closes.
+	Hooks *Hooks
+	// Logger logs requests and failures to serve them, as SetLogger does.
+	// nil logs failures to slog.Default and requests not at all.
+	Logger *slog.Logger
+}
+
+// Handler serves GraphQL over HTTP as configured by its Config. Tenants,
+// idempotency keys and the parse cache are shared by all handlers.
+type Handler struct {
+	config Config
+}
+
+// New returns a Handler serving requests as config describes.
+func New(config Config) *Handler {
+	if config.LocaleFunc == nil {
+		config.LocaleFunc = AcceptLanguage
+	}
+	return &Handler{config: config}
+}
+
+// defaultHandler returns the handler behind the package-level handler
+// functions, configured by the package-level settings.
+func defaultHandler() *Handler {
+	return New(Config{
+		AllowGET:           true,
+		MaxDepth:           maxDepth,
+		MaxBodySize:        maxBodySize,
+		LocaleFunc:         localeFunc,
+		PersistedDocuments: persisted,
+		CORS:               cors,
+		Logger:             logger,
+	})
+}
+
+// ServeHTTP serves r as GraphQL does, as Upload does for multipart requests
+// and as Subscription does for WebSocket upgrade requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.config.Tracer != nil {
+		ctx = executor.WithTracer(ctx, h.config.Tracer)
+	}
+	if h.config.ErrorPresenter != nil {
+		ctx = executor.WithErrorPresenter(ctx, h.config.ErrorPresenter)
+	}
+	r = r.WithContext(ctx)
+	if !h.config.CORS.handle(w, r) {
+		return
+	}
+	w, r, finish := h.startLifecycle(w, r)
+	defer finish()
+	if h.config.ContextFunc != nil {
+		ctx, err := h.config.ContextFunc(r.Context(), r)
+		if err != nil {
+			var gqlErr *executor.Error
+			if !errors.As(err, &gqlErr) {
+				err = errcode.New(errcode.Unauthenticated, "detail", err)
+			}
+			h.writeError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+		r = r.WithContext(ctx)
+	}
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		h.serveSubscription(w, r)
 		return
 	}
-	defer r.Body.Close()
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		h.serveUpload(w, r)
+		return
+	}
+	h.serveGraphQL(w, r)
+}
+
+// GraphQL handles standard GraphQL HTTP requests as the GraphQL over HTTP
+// specification describes. POST requests carry the request as a JSON body;
+// GET requests carry it in the query, documentId, operationName, variables
+// and extensions URL parameters, the last two JSON encoded, and may only
+// execute queries. A GET request with a "sdl" query parameter is served the
+// schema as by Schema.
+//
+// Responses are application/graphql-response+json if the Accept header
+// asks for it and application/json otherwise. Malformed requests get 400,
+// other methods than GET and POST 405 and bodies other than JSON 415.
+// Requests that fail before execution, such as those with syntax or
+// validation errors, get 400 as application/graphql-response+json and 200
+// as application/json, like requests with field errors.
+//
+// GraphQL uses the global executor and the package-level settings; New
+// creates handlers configured otherwise.
+func GraphQL(w http.ResponseWriter, r *http.Request) {
+	if h := defaultHandler(); h.config.CORS.handle(w, r) {
+		w, r, finish := h.startLifecycle(w, r)
+		defer finish()
+		h.serveGraphQL(w, r)
+	}
+}
 
+// serveGraphQL serves a GraphQL request that is not multipart.
+func (h *Handler) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	if sdlRequested(r) {
+		h.serveSchema(w, r)
+		return
+	}
 	var req GraphQLRequest
-	if err := json.Unmarshal(body, &req); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+	switch {
+	case r.Method == http.MethodGet && h.config.AllowGET:
+		var err error
+		if req, err = queryRequest(r); err != nil {
+			h.writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+	case r.Method == http.MethodPost:
+		if !jsonContent(r) {
+			h.writeError(w, r, http.StatusUnsupportedMediaType, errors.New("request body must be application/json"))
+			return
+		}
+		h.limitBody(w, r)
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			if !h.bodyTooLarge(w, r, err) {
+				h.writeError(w, r, http.StatusBadRequest, errors.New("unable to read body"))
+			}
+			return
+		}
+		defer r.Body.Close()
+		if err := decodeRequest(body, &req); err != nil {
+			h.writeError(w, r, http.StatusBadRequest, errors.New("invalid JSON"))
+			return
+		}
+		if req.Query == "" && documentID(req) == "" {
+			h.writeError(w, r, http.StatusBadRequest, errors.New("missing query"))
+			return
+		}
+	default:
+		allowed := "POST"
+		if h.config.AllowGET {
+			allowed = "GET, POST"
+		}
+		w.Header().Set("Allow", allowed)
+		h.writeError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("only %s requests are supported", strings.ReplaceAll(allowed, ", ", " and ")))
 		return
 	}
 	if req.Variables == nil {
 		req.Variables = make(map[string]interface{})
 	}
 
-	// Lex and parse the query
-	l := lexer.New(req.Query)
-	p := parser.New(l)
-	doc := p.ParseDocument()
+	h.executeRequest(w, r, req)
+}
 
-	// Execute the query using the global executor
-	exec := registry.GetGlobalExecutor()
-	result, err := exec.Execute(doc, req.Variables)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// queryRequest reads the request of a GET request from its URL parameters.
+func queryRequest(r *http.Request) (GraphQLRequest, error) {
+	params := r.URL.Query()
+	req := GraphQLRequest{Query: params.Get("query"), OperationName: params.Get("operationName"), DocumentID: params.Get("documentId")}
+	if v := params.Get("variables"); v != "" {
+		if err := decodeRequest([]byte(v), &req.Variables); err != nil {
+			return req, errors.New("invalid variables JSON")
+		}
+	}
+	if v := params.Get("extensions"); v != "" {
+		if err := decodeRequest([]byte(v), &req.Extensions); err != nil {
+			return req, errors.New("invalid extensions JSON")
+		}
 	}
+	if req.Query == "" && documentID(req) == "" {
+		return req, errors.New("missing query parameter")
+	}
+	return req, nil
+}
 
-	// Return the JSON result
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+// decodeRequest decodes the JSON request data into req, keeping numbers as
+// json.Number; executeRequest converts them with exactNumbers.
+func decodeRequest(data []byte, req interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(req)
 }
 
-// upgrader upgrades HTTP connections to WebSocket connections.
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// exactNumbers replaces the json.Number values in v with float64 values,
+// except for integers beyond the 2^53 float64 represents exactly, which
+// keep their precision for the Int64, BigInt and ID scalars. Maps and
+// slices are modified in place.
+func exactNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			n, err := strconv.ParseInt(v.String(), 10, 64)
+			if err != nil || n > 1<<53 || n < -1<<53 {
+				return v
+			}
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = exactNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = exactNumbers(value)
+		}
+	}
+	return v
 }
 
-// Subscription handles GraphQL subscriptions over WebSocket.
-func Subscription(w http.ResponseWriter, r *http.Request) {
-	// Upgrade HTTP to WebSocket
-	conn, err := upgrader.Upgrade(w, r, nil)
+// DebugHeader is the request header that adds an extensions.debug block
+// with timings and cache statistics to the response. It is only honored
+// when the executor is in development mode.
+const DebugHeader = "X-GraphQL-Debug"
+
+// executeRequest lexes, parses and executes req using the executor of the
+// request's tenant (or the handler's executor) and writes the JSON result,
+// honoring idempotency keys.
+func (h *Handler) executeRequest(w http.ResponseWriter, r *http.Request, req GraphQLRequest) {
+	exactNumbers(req.Variables)
+	tr, err := withTenant(r)
 	if err != nil {
-		http.Error(w, "unable to upgrade to websocket", http.StatusBadRequest)
+		h.writeError(w, r, http.StatusForbidden, err)
+		return
+	}
+	r = tr
+	if err := h.config.PersistedDocuments.resolve(&req); err != nil {
+		h.writeError(w, r, requestErrorStatus(r), err)
 		return
 	}
-	defer conn.Close()
+	if idempotency.store != nil {
+		if key := idempotencyKey(r, req); key != "" {
+			h.executeIdempotent(w, r, req, key)
+			return
+		}
+	}
+	h.runRequest(w, r, req)
+}
 
-	// Read the subscription request from the WebSocket
-	_, msg, err := conn.ReadMessage()
-	if err != nil {
-		conn.WriteMessage(websocket.TextMessage, []byte("failed to read subscription message"))
+// runRequest executes req and writes the JSON result.
+func (h *Handler) runRequest(w http.ResponseWriter, r *http.Request, req GraphQLRequest) {
+	exec := h.executorFor(r)
+	ctx := r.Context()
+	var debug *executor.Debug
+	if exec.DevMode() && debugRequested(r) {
+		debug = &executor.Debug{}
+		ctx = executor.WithDebug(ctx, debug)
+	}
+
+	// Lex and parse the query
+	_, finishParse := exec.TraceParse(ctx)
+	start := time.Now()
+	doc, syntaxErrs := parseQuery(req.Query, debug)
+	parseTime := time.Since(start)
+	debug.RecordParse(parseTime)
+	if len(syntaxErrs) > 0 {
+		errs := make([]error, len(syntaxErrs))
+		for i, msg := range syntaxErrs {
+			errs[i] = errcode.New(errcode.SyntaxError, "detail", msg)
+		}
+		finishParse(errs[0])
+		h.writeError(w, r, requestErrorStatus(r), errs...)
+		return
+	}
+	finishParse(nil)
+	lifecycle := lifecycleFrom(ctx)
+	lifecycle.parsed(ctx, req.OperationName, operationType(doc, req.OperationName), parseTime)
+	if r.Method == http.MethodGet && operationType(doc, req.OperationName) == "mutation" {
+		// GET requests must be safe, so that they may be cached or retried.
+		w.Header().Set("Allow", http.MethodPost)
+		h.writeError(w, r, http.StatusMethodNotAllowed, errors.New("mutations are only allowed in POST requests"))
+		return
+	}
+	if err := h.checkLimits(doc, req.Variables); err != nil {
+		h.writeError(w, r, requestErrorStatus(r), err)
 		return
 	}
 
-	var req GraphQLRequest
-	if err := json.Unmarshal(msg, &req); err != nil {
-		conn.WriteMessage(websocket.TextMessage, []byte("invalid subscription JSON"))
+	// Execute the query
+	start = time.Now()
+	result, err := exec.ExecuteOperation(ctx, doc, req.OperationName, req.Variables)
+	if err != nil {
+		// The request failed before any data was produced, such as by
+		// failing validation or exceeding a limit.
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			// Validation reports each violation as a separate error.
+			errs = joined.Unwrap()
+		}
+		lifecycle.executed(ctx, len(errs), time.Since(start))
+		h.writeError(w, r, requestErrorStatus(r), errs...)
 		return
 	}
+	fieldErrs, _ := result["errors"].([]*executor.Error)
+	lifecycle.executed(ctx, len(fieldErrs), time.Since(start))
+	if debug != nil {
+		extensions, _ := result["extensions"].(map[string]interface{})
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+			result["extensions"] = extensions
+		}
+		extensions["debug"] = debug.Extension()
+	}
 
-	// Lex, parse, and extract the subscription operation
-	l := lexer.New(req.Query)
-	p := parser.New(l)
-	doc := p.ParseDocument()
+	// Return the JSON result
+	w.Header().Set("Content-Type", responseType(r))
+	json.NewEncoder(w).Encode(result)
+}
 
-	if len(doc.Definitions) == 0 {
-		conn.WriteMessage(websocket.TextMessage, []byte("no subscription definition found"))
-		return
+// operationType returns the type of the operation named operationName in
+// doc, or of its first operation if operationName is empty, or "" if there
+// is no such operation.
+func operationType(doc *ast.Document, operationName string) string {
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && (operationName == "" || op.Name == operationName) {
+			return op.Operation
+		}
 	}
+	return ""
+}
 
-	op, ok := doc.Definitions[0].(*ast.OperationDefinition)
-	if !ok || op.Operation != "subscription" {
-		conn.WriteMessage(websocket.TextMessage, []byte("provided operation is not a subscription"))
-		return
+// maxBodySize limits the size of request bodies to the package-level
+// handlers; zero means no limit.
+var maxBodySize int64
+
+// SetMaxBodySize limits the size of the bodies of requests to the
+// package-level handlers to n bytes. JSON and multipart requests that are
+// larger are rejected with 413 Request Entity Too Large, and subscription
+// connections whose messages are larger are closed. Zero, the default,
+// means no limit.
+func SetMaxBodySize(n int64) {
+	maxBodySize = n
+}
+
+// limitBody applies the body size limit of h to r.
+func (h *Handler) limitBody(w http.ResponseWriter, r *http.Request) {
+	if h.config.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxBodySize)
 	}
+}
 
-	if len(op.SelectionSet.Selections) == 0 {
-		conn.WriteMessage(websocket.TextMessage, []byte("subscription selection set is empty"))
-		return
+// bodyTooLarge writes a 413 response and reports true if err is the error
+// of reading a body beyond the limit set by limitBody.
+func (h *Handler) bodyTooLarge(w http.ResponseWriter, r *http.Request, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
 	}
+	h.writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds the limit of %d bytes", tooLarge.Limit))
+	return true
+}
 
-	field, ok := op.SelectionSet.Selections[0].(*ast.Field)
-	if !ok {
-		conn.WriteMessage(websocket.TextMessage, []byte("invalid subscription field"))
-		return
+// checkLimits returns an error if an operation in doc nests fields deeper
+// than h allows or costs more with variables.
+func (h *Handler) checkLimits(doc *ast.Document, variables map[string]interface{}) error {
+	if h.config.MaxDepth > 0 {
+		if err := executor.CheckDepth(doc, h.config.MaxDepth); err != nil {
+			return err
+		}
+	}
+	if h.config.Complexity != nil && h.config.MaxCost > 0 {
+		return h.config.Complexity.Check(doc, variables, h.config.MaxCost)
 	}
+	return nil
+}
 
-	// Execute the subscription
-	exec := registry.GetGlobalExecutor()
-	subCh, err := exec.ExecuteSubscription(field, req.Variables)
-	if err != nil {
-		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("subscription error: %v", err)))
-		return
+// maxDepth limits the nesting of fields in requests to the package-level
+// handlers; zero means no limit.
+var maxDepth int
+
+// SetMaxDepth rejects requests whose operations nest fields deeper than n,
+// root fields being at depth 1, before they are executed. Zero, the
+// default, means no limit. Executors may also set their own limit with
+// Executor.SetMaxDepth.
+func SetMaxDepth(n int) {
+	maxDepth = n
+}
+
+// debugRequested reports whether the request asks for debug extensions.
+func debugRequested(r *http.Request) bool {
+	enabled, err := strconv.ParseBool(r.Header.Get(DebugHeader))
+	return err == nil && enabled
+}
+
+// GraphQLResponseType is the media type of GraphQL responses defined by the
+// GraphQL over HTTP specification.
+const GraphQLResponseType = "application/graphql-response+json"
+
+// responseType returns the media type of the response to r: the GraphQL
+// response type if the Accept header prefers it to application/json, and
+// application/json otherwise, including when neither is acceptable.
+func responseType(r *http.Request) string {
+	best, bestQ := "application/json", 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case GraphQLResponseType, "application/json", "application/*", "*/*":
+			if mediaType != GraphQLResponseType {
+				mediaType = "application/json"
+			}
+			if q > bestQ {
+				best, bestQ = mediaType, q
+			}
+		}
 	}
+	return best
+}
 
-	// Stream events from the subscription channel to the WebSocket
-	for event := range subCh {
-		if err := conn.WriteJSON(event); err != nil {
-			fmt.Printf("failed to write event: %v\n", err)
-			break
+// requestErrorStatus returns the status of a response to r with errors that
+// prevented execution: 400 for the GraphQL response type, and 200 for
+// application/json, whose clients expect it for any well-formed request.
+func requestErrorStatus(r *http.Request) int {
+	if responseType(r) == GraphQLResponseType {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
+
+// jsonContent reports whether the body of r is JSON. A missing Content-Type
+// is taken to be JSON for clients that do not set one.
+func jsonContent(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// writeError writes errs as a JSON GraphQL response with an errors array.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, status int, errs ...error) {
+	lifecycleFrom(r.Context()).failed(len(errs))
+	gqlErrs := make([]*executor.Error, len(errs))
+	for i, err := range errs {
+		gqlErrs[i] = h.presentError(r, err)
+	}
+	w.Header().Set("Content-Type", responseType(r))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":   nil,
+		"errors": gqlErrs,
+	})
+}
+
+// presentError converts err into a response error. Execution errors keep
+// their path and extensions; coded framework errors are localized for the
+// request and carry their code in extensions.code. Other errors go through
+// the executor's error presenter.
+func (h *Handler) presentError(r *http.Request, err error) *executor.Error {
+	var gqlErr *executor.Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	var coded *errcode.Error
+	if errors.As(err, &coded) {
+		return &executor.Error{
+			Message:    coded.Localize(errcode.Default, h.config.LocaleFunc(r)),
+			Extensions: map[string]interface{}{"code": coded.Code},
+			Err:        err,
 		}
 	}
+	return h.executorFor(r).PresentError(r.Context(), err)
 }
 
-// Upload handles GraphQL requests with file uploads (multipart/form-data).
-func Upload(w http.ResponseWriter, r *http.Request) {
-	// If not multipart, delegate to regular GraphQL handler
-	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
-		GraphQL(w, r)
-		return
+// LocaleFunc returns the locale in which framework error messages are
+// presented for a request, such as "de" or "pt-BR".
+type LocaleFunc func(r *http.Request) string
+
+// localeFunc picks the locale of error messages of the package-level
+// handlers.
+var localeFunc LocaleFunc = AcceptLanguage
+
+// SetLocaleFunc sets how the locale of error messages is chosen. It
+// defaults to AcceptLanguage; messages are looked up in errcode.Default.
+func SetLocaleFunc(fn LocaleFunc) {
+	localeFunc = fn
+}
+
+// AcceptLanguage returns the first language of the Accept-Language header.
+func AcceptLanguage(r *http.Request) string {
+	first, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	tag, _, _ := strings.Cut(first, ";")
+	if tag = strings.TrimSpace(tag); tag == "*" {
+		return ""
 	}
+	return tag
+}
+
+// Upload handles GraphQL requests with file uploads (multipart/form-data),
+// and other requests as GraphQL does.
+func Upload(w http.ResponseWriter, r *http.Request) {
+	defaultHandler().ServeHTTP(w, r)
+}
 
+// serveUpload serves a multipart request with file uploads.
+func (h *Handler) serveUpload(w http.ResponseWriter, r *http.Request) {
+	h.limitBody(w, r)
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		if h.bodyTooLarge(w, r, err) {
+			return
+		}
 		http.Error(w, "failed to parse multipart form: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -151,7 +599,7 @@ func Upload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req GraphQLRequest
-	if err := json.Unmarshal([]byte(operations), &req); err != nil {
+	if err := decodeRequest([]byte(operations), &req); err != nil {
 		http.Error(w, "invalid operations JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -180,16 +628,16 @@ func Upload(w http.ResponseWriter, r *http.Request) {
 			defer wg.Done()
 			file, header, err := r.FormFile(fileKey)
 			if err != nil {
-				log.Printf("failed to retrieve file %s: %v", fileKey, err)
+				h.logger().Warn("failed to retrieve uploaded file", "file", fileKey, "error", err)
 				return
 			}
 			defer file.Close()
 			fileData, err := ioutil.ReadAll(file)
 			if err != nil {
-				log.Printf("failed to read file %s: %v", header.Filename, err)
+				h.logger().Warn("failed to read uploaded file", "file", header.Filename, "error", err)
 				return
 			}
-			log.Printf("Uploaded file %q with %d bytes", header.Filename, len(fileData))
+			h.logger().Debug("received uploaded file", "file", header.Filename, "bytes", len(fileData))
 			for _, path := range paths {
 				// Remove the "variables." prefix if present
 				adjustedPath := strings.TrimPrefix(path, "variables.")
@@ -217,19 +665,7 @@ func Upload(w http.ResponseWriter, r *http.Request) {
 	wg.Wait()
 
 	// Continue processing the GraphQL query
-	l := lexer.New(req.Query)
-	p := parser.New(l)
-	doc := p.ParseDocument()
-
-	exec := registry.GetGlobalExecutor()
-	result, err := exec.Execute(doc, req.Variables)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	h.executeRequest(w, r, req)
 }
 
 // setNestedValue updates nested maps (non-array paths).
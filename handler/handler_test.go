@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/complexity"
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+	"github.com/Protocol-Lattice/graphql/registry"
+)
+
+func TestSyntaxErrorsAreLocalized(t *testing.T) {
+	errcode.Default.Register("de", map[errcode.Code]string{errcode.SyntaxError: "Syntaxfehler: {detail}"})
+
+	for _, tt := range []struct{ lang, prefix string }{
+		{"de-DE,de;q=0.9,en;q=0.8", "Syntaxfehler: "},
+		{"", "Syntax error: "},
+	} {
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ user(id: ) }"}`))
+		r.Header.Set("Accept-Language", tt.lang)
+		r.Header.Set("Accept", GraphQLResponseType)
+		w := httptest.NewRecorder()
+		GraphQL(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+		var resp struct {
+			Errors []struct {
+				Message    string                 `json:"message"`
+				Extensions map[string]interface{} `json:"extensions"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Errors) == 0 || !strings.HasPrefix(resp.Errors[0].Message, tt.prefix) {
+			t.Errorf("Accept-Language %q: expected message starting with %q, got %+v", tt.lang, tt.prefix, resp.Errors)
+		} else if code := resp.Errors[0].Extensions["code"]; code != string(errcode.SyntaxError) {
+			t.Errorf("expected code %s, got %v", errcode.SyntaxError, code)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	SetMaxDepth(2)
+	defer SetMaxDepth(0)
+
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ user { friends { name } } }"}`))
+	r.Header.Set("Accept", GraphQLResponseType)
+	w := httptest.NewRecorder()
+	GraphQL(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), string(errcode.DepthLimitExceeded)) {
+		t.Errorf("expected %s, got %s", errcode.DepthLimitExceeded, w.Body.String())
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	schema := parser.New(lexer.New(`
+		directive @cost(weight: Int, multipliers: [String]) on FIELD_DEFINITION
+		type Query { users(limit: Int): [User] @cost(weight: 1, multipliers: ["limit"]) }
+		type User { name: String }
+	`)).ParseDocument()
+	exec := executor.New()
+	exec.RegisterQueryResolver("users", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []interface{}{}, nil
+	})
+	h := New(Config{Executor: exec, Complexity: complexity.New(schema), MaxCost: 10})
+
+	for _, tt := range []struct {
+		limit int
+		code  int
+	}{
+		{5, http.StatusOK},
+		{50, http.StatusBadRequest},
+	} {
+		body := fmt.Sprintf(`{"query": "query ($n: Int) { users(limit: $n) { name } }", "variables": {"n": %d}}`, tt.limit)
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		r.Header.Set("Accept", GraphQLResponseType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("limit %d: expected %d, got %d: %s", tt.limit, tt.code, w.Code, w.Body.String())
+		}
+		if tt.code != http.StatusOK && !strings.Contains(w.Body.String(), string(errcode.CostLimitExceeded)) {
+			t.Errorf("expected %s, got %s", errcode.CostLimitExceeded, w.Body.String())
+		}
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	exec.SetSchema(parser.New(lexer.New(`type Query { user(id: ID!): User } type User { name: String }`)).ParseDocument())
+	exec.SetValidation(true)
+	defer exec.SetSchema(nil)
+	defer exec.SetValidation(false)
+
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ user(id: \"1\", name: \"x\") { email } }"}`))
+	r.Header.Set("Accept", GraphQLResponseType)
+	w := httptest.NewRecorder()
+	GraphQL(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	var resp struct {
+		Errors []executor.Error `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) != 2 || resp.Errors[0].Extensions["code"] != string(errcode.ArgumentNotFound) || resp.Errors[1].Extensions["code"] != string(errcode.FieldNotFound) {
+		t.Errorf("expected an unknown argument and an unknown field, got %s", w.Body.String())
+	}
+}
+
+func TestLargeIntegerVariables(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	var got map[string]interface{}
+	exec.RegisterQueryResolver("lookup", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return nil, nil
+	})
+
+	body := `{"query": "query ($id: ID, $n: Int, $f: Float) { lookup(id: $id, n: $n, f: $f, raw: {big: 9007199254740993}) }", "variables": {"id": 9007199254740993, "n": 7, "f": 1.5}}`
+	w := httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	want := map[string]interface{}{"id": "9007199254740993", "n": 7, "f": 1.5, "raw": map[string]interface{}{"big": 9007199254740993}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// parseTracer records the outcome of parse phases.
+type parseTracer struct {
+	executor.NopTracer
+	errs []error
+}
+
+func (p *parseTracer) StartParse(ctx context.Context) (context.Context, executor.FinishFunc) {
+	return ctx, func(d time.Duration, err error) { p.errs = append(p.errs, err) }
+}
+
+func TestParseIsTraced(t *testing.T) {
+	tracer := &parseTracer{}
+	exec := registry.GetGlobalExecutor()
+	exec.SetTracer(tracer)
+	defer exec.SetTracer(nil)
+
+	for _, query := range []string{`{ __typename }`, `{ user(id: ) }`} {
+		body, _ := json.Marshal(GraphQLRequest{Query: query})
+		GraphQL(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+	}
+	if len(tracer.errs) != 2 || tracer.errs[0] != nil || tracer.errs[1] == nil {
+		t.Errorf("expected a successful and a failed parse, got %v", tracer.errs)
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	SetParseCacheSize(2)
+	defer SetParseCacheSize(0)
+	before := ParseCacheStats()
+
+	for _, query := range []string{`{ a: __typename }`, `{ b: __typename }`, `{ a: __typename }`, `{ c: __typename }`, `{ b: __typename }`, `{ bad( }`} {
+		body, _ := json.Marshal(GraphQLRequest{Query: query})
+		GraphQL(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+	}
+
+	stats := ParseCacheStats()
+	got := CacheStats{
+		Hits:      stats.Hits - before.Hits,
+		Misses:    stats.Misses - before.Misses,
+		Evictions: stats.Evictions - before.Evictions,
+		Size:      stats.Size,
+		Capacity:  stats.Capacity,
+	}
+	// a and b are cached; a is hit; c evicts b; b evicts a; bad is not cached.
+	if want := (CacheStats{Hits: 1, Misses: 5, Evictions: 2, Size: 2, Capacity: 2}); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestGetRequests(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	exec.RegisterQueryResolver("greet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hello " + args["name"].(string), nil
+	})
+	exec.RegisterMutationResolver("rename", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("mutation executed over GET")
+		return nil, nil
+	})
+
+	params := url.Values{
+		"query":         {`query A { a: greet(name: "a") } query B($name: String) { b: greet(name: $name) }`},
+		"operationName": {"B"},
+		"variables":     {`{"name": "b"}`},
+	}
+	w := httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"data":{"b":"hello b"}`) {
+		t.Errorf("expected operation B to run, got %d: %s", w.Code, w.Body.String())
+	}
+
+	params = url.Values{"query": {`mutation { rename(name: "x") }`}}
+	w = httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("expected 405 for a mutation over GET, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("GET", "/graphql?variables=%7B", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a query, got %d", w.Code)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	exec.RegisterQueryResolver("fail", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	tests := []struct {
+		name, method, contentType, accept, body string
+		status                                  int
+		responseType                            string
+	}{
+		{"field error", "POST", "application/json", "", `{"query": "{ fail }"}`, http.StatusOK, "application/json"},
+		{"field error, graphql response", "POST", "application/json", GraphQLResponseType, `{"query": "{ fail }"}`, http.StatusOK, GraphQLResponseType},
+		{"syntax error", "POST", "application/json", "application/json", `{"query": "{ fail(id: ) }"}`, http.StatusOK, "application/json"},
+		{"syntax error, graphql response", "POST", "application/json", "application/json;q=0.9, " + GraphQLResponseType, `{"query": "{ fail(id: ) }"}`, http.StatusBadRequest, GraphQLResponseType},
+		{"unknown operation", "POST", "", GraphQLResponseType, `{"query": "{ fail }", "operationName": "Other"}`, http.StatusBadRequest, GraphQLResponseType},
+		{"preferred json", "POST", "application/json; charset=utf-8", GraphQLResponseType + ";q=0.5, */*", `{"query": "{ fail(id: ) }"}`, http.StatusOK, "application/json"},
+		{"malformed body", "POST", "application/json", GraphQLResponseType, `{"query":`, http.StatusBadRequest, GraphQLResponseType},
+		{"bad content type", "POST", "text/plain", "", `{"query": "{ fail }"}`, http.StatusUnsupportedMediaType, "application/json"},
+		{"bad method", "PUT", "application/json", "", `{"query": "{ fail }"}`, http.StatusMethodNotAllowed, "application/json"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/graphql", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		GraphQL(w, r)
+		if w.Code != tt.status || w.Header().Get("Content-Type") != tt.responseType {
+			t.Errorf("%s: expected %d %s, got %d %s: %s", tt.name, tt.status, tt.responseType, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"errors"`) {
+			t.Errorf("%s: expected errors, got %s", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestHandlerConfig(t *testing.T) {
+	exec := executor.New()
+	exec.RegisterQueryResolver("fail", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("database password leaked")
+	})
+	tracer := &parseTracer{}
+	h := New(Config{
+		Executor:    exec,
+		MaxBodySize: 64,
+		Tracer:      tracer,
+		ErrorPresenter: func(ctx context.Context, err error) *executor.Error {
+			return &executor.Error{Message: "internal error"}
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ fail }"}`)))
+	if body := w.Body.String(); !strings.Contains(body, `"message":"internal error"`) || !strings.Contains(body, `"path":["fail"]`) {
+		t.Errorf("expected the handler's executor and presenter, got %s", body)
+	}
+	if len(tracer.errs) != 1 {
+		t.Errorf("expected the handler's tracer to trace the parse, got %v", tracer.errs)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ fail `+strings.Repeat("fail ", 20)+`}"}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a large body, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/graphql?query={fail}", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("expected 405 for GET without AllowGET, got %d", w.Code)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	SetMaxBodySize(64)
+	defer SetMaxBodySize(0)
+
+	w := httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ user { `+strings.Repeat("name ", 20)+`} }"}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a large JSON body, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("operations", `{"query": "mutation($file: Upload!) { upload(file: $file) }", "variables": {"file": null}}`)
+	mw.WriteField("map", `{"0": ["variables.file"]}`)
+	part, _ := mw.CreateFormFile("0", "large.txt")
+	part.Write(bytes.Repeat([]byte("x"), 1024))
+	mw.Close()
+	r := httptest.NewRequest("POST", "/graphql", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	Upload(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a large upload, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestContextFunc(t *testing.T) {
+	type userKey struct{}
+	exec := executor.New()
+	exec.RegisterQueryResolverContext("whoami", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return ctx.Value(userKey{}), nil
+	})
+	h := New(Config{
+		Executor: exec,
+		ContextFunc: func(ctx context.Context, r *http.Request) (context.Context, error) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token != "secret" {
+				return nil, errors.New("invalid token")
+			}
+			return context.WithValue(ctx, userKey{}, "alice"), nil
+		},
+	})
+
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ whoami }"}`))
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if body := w.Body.String(); !strings.Contains(body, `"whoami":"alice"`) {
+		t.Errorf("expected the resolver to see the context, got %d: %s", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ whoami }"}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, string(errcode.Unauthenticated)) || !strings.Contains(body, "invalid token") {
+		t.Errorf("expected an %s error, got %s", errcode.Unauthenticated, body)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	exec := executor.New()
+	exec.RegisterQueryResolver("fail", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	var events []string
+	var written RequestInfo
+	h := New(Config{
+		Executor: exec,
+		Hooks: &Hooks{
+			RequestReceived: func(ctx context.Context, info RequestInfo) { events = append(events, "received") },
+			OperationParsed: func(ctx context.Context, info RequestInfo) {
+				events = append(events, "parsed "+info.OperationType+" "+info.OperationName)
+			},
+			ExecutionFinished: func(ctx context.Context, info RequestInfo) { events = append(events, "executed") },
+			ResponseWritten: func(ctx context.Context, info RequestInfo) {
+				events = append(events, "written")
+				written = info
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "query Failing { fail }", "operationName": "Failing"}`)))
+	if want := []string{"received", "parsed query Failing", "executed", "written"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("expected hooks %v, got %v", want, events)
+	}
+	if written.ErrorCount != 1 || written.Status != http.StatusOK || written.Duration <= 0 {
+		t.Errorf("unexpected info %+v", written)
+	}
+
+	events = nil
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ fail(id: ) }"}`))
+	r.Header.Set("Accept", GraphQLResponseType)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if want := []string{"received", "written"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("expected hooks %v for a syntax error, got %v", want, events)
+	}
+	if written.ErrorCount == 0 || written.Status != http.StatusBadRequest {
+		t.Errorf("unexpected info %+v", written)
+	}
+}
+
+func TestHooksKeepFlushing(t *testing.T) {
+	h := New(Config{Hooks: &Hooks{}})
+	rec := httptest.NewRecorder()
+	w, _, finish := h.startLifecycle(rec, httptest.NewRequest("GET", "/events", nil))
+	defer finish()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatal("expected the response to flush")
+	}
+	flusher.Flush()
+	if !rec.Flushed {
+		t.Error("expected the flush to reach the underlying response")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "query Me { user { name } }", "operationName": "Me"}`))
+	r.RemoteAddr = "203.0.113.7:4711"
+	GraphQL(httptest.NewRecorder(), r)
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q", buf.String())
+	}
+	if record["msg"] != "graphql request" || record["operation"] != "Me" || record["type"] != "query" || record["client_ip"] != "203.0.113.7" {
+		t.Errorf("unexpected record %v", record)
+	}
+	if record["status"] != float64(http.StatusOK) {
+		t.Errorf("expected status 200, got %v", record["status"])
+	}
+}
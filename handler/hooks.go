@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Hooks observe the lifecycle of the requests a Handler serves, such as to
+// keep an audit trail. Every hook is optional and runs on the request's
+// goroutine, so it should return quickly.
+type Hooks struct {
+	// RequestReceived is called before a request is read.
+	RequestReceived func(ctx context.Context, info RequestInfo)
+	// OperationParsed is called once the operation of a request is parsed.
+	OperationParsed func(ctx context.Context, info RequestInfo)
+	// ExecutionFinished is called once the operation of a request is
+	// executed, whether or not it failed.
+	ExecutionFinished func(ctx context.Context, info RequestInfo)
+	// ResponseWritten is called once the response to a request is written,
+	// including responses to requests that failed before being parsed.
+	ResponseWritten func(ctx context.Context, info RequestInfo)
+}
+
+// RequestInfo describes a request as far as it has been served.
+type RequestInfo struct {
+	// Request is the HTTP request.
+	Request *http.Request
+	// OperationName is the name of the operation to execute, if the
+	// request names one.
+	OperationName string
+	// OperationType is "query", "mutation" or "subscription" once the
+	// operation is parsed.
+	OperationType string
+	// Start is when the request was received.
+	Start time.Time
+	// ParseDuration is how long the operation took to parse.
+	ParseDuration time.Duration
+	// ExecutionDuration is how long the operation took to execute.
+	ExecutionDuration time.Duration
+	// Duration is how long the request took to serve, once the response is
+	// written.
+	Duration time.Duration
+	// ErrorCount is the number of errors in the response.
+	ErrorCount int
+	// Status is the HTTP status of the response, once it is written.
+	Status int
+}
+
+// lifecycleKey is the context key of the lifecycle of a request.
+type lifecycleKey struct{}
+
+// lifecycle tracks a request for the hooks and the request log of its
+// handler.
+type lifecycle struct {
+	hooks Hooks
+	info  RequestInfo
+}
+
+// startLifecycle calls the RequestReceived hook of h and returns w and r
+// tracking the request for the other hooks, and a function that calls the
+// ResponseWritten hook and logs the request. Without hooks or a Logger, it
+// returns w and r unchanged.
+func (h *Handler) startLifecycle(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	if h.config.Hooks == nil && h.config.Logger == nil {
+		return w, r, func() {}
+	}
+	l := &lifecycle{info: RequestInfo{Request: r, Start: time.Now()}}
+	if h.config.Hooks != nil {
+		l.hooks = *h.config.Hooks
+	}
+	r = r.WithContext(context.WithValue(r.Context(), lifecycleKey{}, l))
+	if l.hooks.RequestReceived != nil {
+		l.hooks.RequestReceived(r.Context(), l.info)
+	}
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	return sw, r, func() {
+		l.info.Status = sw.status
+		l.info.Duration = time.Since(l.info.Start)
+		if l.hooks.ResponseWritten != nil {
+			l.hooks.ResponseWritten(r.Context(), l.info)
+		}
+		h.logRequest(l.info)
+	}
+}
+
+// lifecycleFrom returns the lifecycle of the request with context ctx, or
+// nil if its handler has neither hooks nor a Logger. The methods of a nil
+// lifecycle do nothing.
+func lifecycleFrom(ctx context.Context) *lifecycle {
+	l, _ := ctx.Value(lifecycleKey{}).(*lifecycle)
+	return l
+}
+
+// parsed records that the operation was parsed and calls the
+// OperationParsed hook.
+func (l *lifecycle) parsed(ctx context.Context, operationName, operationType string, d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.info.OperationName = operationName
+	l.info.OperationType = operationType
+	l.info.ParseDuration = d
+	if l.hooks.OperationParsed != nil {
+		l.hooks.OperationParsed(ctx, l.info)
+	}
+}
+
+// executed records that the operation was executed with errorCount errors
+// and calls the ExecutionFinished hook.
+func (l *lifecycle) executed(ctx context.Context, errorCount int, d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.info.ErrorCount = errorCount
+	l.info.ExecutionDuration = d
+	if l.hooks.ExecutionFinished != nil {
+		l.hooks.ExecutionFinished(ctx, l.info)
+	}
+}
+
+// failed records that the response carries errorCount errors.
+func (l *lifecycle) failed(errorCount int) {
+	if l != nil {
+		l.info.ErrorCount = errorCount
+	}
+}
+
+// statusWriter passes a response through while keeping its status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets subscriptions take over the connection.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush sends buffered data to the client, if the response can.
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// IdempotencyKeyHeader is the request header carrying an idempotency key.
+// The key may also be sent as the "idempotencyKey" request extension.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// StoredResponse is a response recorded for an idempotency key.
+type StoredResponse struct {
+	// Fingerprint identifies the request that produced the response, so a
+	// key reused for a different operation is rejected instead of replayed.
+	Fingerprint string
+	Status      int
+	Body        []byte
+}
+
+// IdempotencyStore persists responses by idempotency key. Implementations
+// backed by a shared cache let retries land on any server instance.
+type IdempotencyStore interface {
+	// Get returns the unexpired response stored for key.
+	Get(key string) (*StoredResponse, bool)
+	// Set stores resp for key until ttl elapses.
+	Set(key string, resp *StoredResponse, ttl time.Duration)
+}
+
+// idempotency holds the configured store and retention.
+var idempotency struct {
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+// SetIdempotencyStore enables idempotency keys. The first successful
+// response for a key, one with a 2xx status and no errors, is kept in store
+// for ttl, and retries carrying the same key get that response back
+// (marked with an Idempotent-Replayed header) instead of running the
+// operation again. Failed responses are not kept, so retries run the
+// operation again. A key reused for a different request is rejected with
+// 422 and an errcode.IdempotencyKeyReused error. A nil store disables it.
+func SetIdempotencyStore(store IdempotencyStore, ttl time.Duration) {
+	idempotency.store = store
+	idempotency.ttl = ttl
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// memoryEntry is a stored response and its expiry.
+type memoryEntry struct {
+	resp    *StoredResponse
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the unexpired response stored for key.
+func (s *MemoryIdempotencyStore) Get(key string) (*StoredResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Set stores resp for key until ttl elapses, evicting expired entries.
+func (s *MemoryIdempotencyStore) Set(key string, resp *StoredResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryEntry{resp: resp, expires: now.Add(ttl)}
+}
+
+// inflight tracks keys whose first request is still executing, so
+// concurrent retries wait for its result rather than running twice.
+var inflight = struct {
+	sync.Mutex
+	keys map[string]chan struct{}
+}{keys: make(map[string]chan struct{})}
+
+// idempotencyKey returns the key sent with the request, if any.
+// Keys are scoped to the request's tenant.
+func idempotencyKey(r *http.Request, req GraphQLRequest) string {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		key, _ = req.Extensions["idempotencyKey"].(string)
+	}
+	if t := TenantFromContext(r.Context()); t != nil && key != "" {
+		key = t.Key(key)
+	}
+	return key
+}
+
+// fingerprint identifies the operation and variables of req.
+func fingerprint(req GraphQLRequest) string {
+	vars, _ := json.Marshal(req.Variables)
+	sum := sha256.Sum256(append([]byte(req.Query+"\x00"+req.OperationName+"\x00"), vars...))
+	return hex.EncodeToString(sum[:])
+}
+
+// executeIdempotent serves req under an idempotency key: it replays the
+// stored response if there is one, and otherwise executes req and stores
+// a successful response.
+func (h *Handler) executeIdempotent(w http.ResponseWriter, r *http.Request, req GraphQLRequest, key string) {
+	store := idempotency.store
+	fp := fingerprint(req)
+	for {
+		if stored, ok := store.Get(key); ok {
+			if stored.Fingerprint != fp {
+				h.writeError(w, r, http.StatusUnprocessableEntity, errcode.New(errcode.IdempotencyKeyReused))
+				return
+			}
+			w.Header().Set("Content-Type", responseType(r))
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
+		}
+		inflight.Lock()
+		wait, busy := inflight.keys[key]
+		if !busy {
+			inflight.keys[key] = make(chan struct{})
+		}
+		inflight.Unlock()
+		if !busy {
+			break
+		}
+		select {
+		case <-wait:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	defer func() {
+		inflight.Lock()
+		close(inflight.keys[key])
+		delete(inflight.keys, key)
+		inflight.Unlock()
+	}()
+
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.runRequest(rec, r, req)
+	if succeeded(rec.status, rec.body.Bytes()) {
+		store.Set(key, &StoredResponse{Fingerprint: fp, Status: rec.status, Body: rec.body.Bytes()}, idempotency.ttl)
+	}
+}
+
+// succeeded reports whether a response with status and body is a success
+// worth replaying: a 2xx response without errors. Since request and field
+// errors are answered with 200 as application/json, the status alone does
+// not tell.
+func succeeded(status int, body []byte) bool {
+	if status < 200 || status >= 300 {
+		return false
+	}
+	var resp struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(body, &resp) == nil && len(resp.Errors) == 0
+}
+
+// responseRecorder passes a response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+// Hijack lets subscriptions take over the connection. Hijacked responses
+// are not stored.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush sends buffered data to the client, if the response can.
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
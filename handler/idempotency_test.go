@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/registry"
+)
+
+func TestIdempotencyKeyReplaysResponse(t *testing.T) {
+	var charges atomic.Int32
+	registry.RegisterMutationResolver("charge", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return int(charges.Add(1)), nil
+	})
+	SetIdempotencyStore(NewMemoryIdempotencyStore(), time.Minute)
+	defer SetIdempotencyStore(nil, 0)
+
+	post := func(body, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		GraphQL(w, r)
+		return w
+	}
+
+	const charge = `{"query": "mutation { charge }"}`
+	first := post(charge, "order-1")
+	retry := post(charge, "order-1")
+	if charges.Load() != 1 {
+		t.Fatalf("expected one charge, got %d", charges.Load())
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected replayed response %q, got %q", first.Body.String(), retry.Body.String())
+	}
+
+	// The key may also arrive as a request extension.
+	post(`{"query": "mutation { charge }", "extensions": {"idempotencyKey": "order-2"}}`, "")
+	post(`{"query": "mutation { charge }", "extensions": {"idempotencyKey": "order-2"}}`, "")
+	if charges.Load() != 2 {
+		t.Errorf("expected two charges, got %d", charges.Load())
+	}
+
+	if w := post(`{"query": "mutation { charge }", "variables": {"amount": 5}}`, "order-1"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a reused key, got %d", w.Code)
+	} else if !strings.Contains(w.Body.String(), `"code":"`+string(errcode.IdempotencyKeyReused)+`"`) {
+		t.Errorf("expected a %s error, got %s", errcode.IdempotencyKeyReused, w.Body.String())
+	}
+	post(charge, "")
+	if charges.Load() != 3 {
+		t.Errorf("expected requests without a key to execute, got %d charges", charges.Load())
+	}
+}
+
+func TestIdempotencyKeyRetriesFailures(t *testing.T) {
+	var attempts atomic.Int32
+	registry.RegisterMutationResolver("pay", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		if attempts.Add(1) == 1 {
+			return nil, errors.New("payment gateway timeout")
+		}
+		return "paid", nil
+	})
+	SetIdempotencyStore(NewMemoryIdempotencyStore(), time.Minute)
+	defer SetIdempotencyStore(nil, 0)
+
+	post := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "mutation { pay }"}`))
+		r.Header.Set(IdempotencyKeyHeader, "order-3")
+		w := httptest.NewRecorder()
+		GraphQL(w, r)
+		return w
+	}
+
+	if w := post(); !strings.Contains(w.Body.String(), "payment gateway timeout") {
+		t.Fatalf("expected the first attempt to fail, got %s", w.Body.String())
+	}
+	retry := post()
+	if retry.Header().Get("Idempotent-Replayed") != "" || !strings.Contains(retry.Body.String(), `"pay":"paid"`) {
+		t.Errorf("expected the retry to run again, got %s", retry.Body.String())
+	}
+	replay := post()
+	if replay.Header().Get("Idempotent-Replayed") != "true" || attempts.Load() != 2 {
+		t.Errorf("expected the successful response to be replayed, got %d attempts", attempts.Load())
+	}
+}
+
+func TestIdempotencyRecorderForwards(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	rec.Flush()
+	if !w.Flushed {
+		t.Error("expected the flush to reach the underlying response")
+	}
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("expected an error hijacking a response that cannot be hijacked")
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// logger records the requests to the package-level handlers and their
+// failures; nil logs failures to slog.Default and requests not at all.
+var logger *slog.Logger
+
+// SetLogger sets the logger of the package-level handlers. Each request is
+// logged at Info level, or Warn level if its response has errors, with its
+// operation, duration, error count, status and client IP; failures such as
+// unwritable subscription events are logged at Warn level. nil, the
+// default, logs failures to slog.Default and requests not at all.
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// logger returns the logger of failures to serve requests.
+func (h *Handler) logger() *slog.Logger {
+	if h.config.Logger == nil {
+		return slog.Default()
+	}
+	return h.config.Logger
+}
+
+// logRequest logs the request described by info, if h has a Logger.
+func (h *Handler) logRequest(info RequestInfo) {
+	if h.config.Logger == nil {
+		return
+	}
+	level := slog.LevelInfo
+	if info.ErrorCount > 0 || info.Status >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	h.config.Logger.LogAttrs(info.Request.Context(), level, "graphql request",
+		slog.String("operation", info.OperationName),
+		slog.String("type", info.OperationType),
+		slog.Duration("duration", info.Duration),
+		slog.Int("errors", info.ErrorCount),
+		slog.Int("status", info.Status),
+		slog.String("client_ip", clientIP(info.Request)),
+	)
+}
+
+// clientIP returns the IP address of the client that sent r. Proxy headers
+// are not trusted, so behind a proxy it is the proxy's address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+// CacheStats reports the activity of the parse cache.
+type CacheStats struct {
+	Hits      uint64 // queries served from the cache
+	Misses    uint64 // queries parsed
+	Evictions uint64 // documents dropped to make room
+	Size      int    // documents currently cached
+	Capacity  int    // maximum number of documents cached
+}
+
+// parseCache is a least recently used cache of parsed documents keyed by
+// query text. Documents are shared between requests and must not be
+// modified.
+var parseCache = struct {
+	sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // of *cachedDoc, most recently used first
+	stats    CacheStats
+}{entries: make(map[string]*list.Element), order: list.New()}
+
+// cachedDoc is a parse cache entry.
+type cachedDoc struct {
+	query string
+	doc   *ast.Document
+}
+
+// SetParseCacheSize caches the parsed documents of the n most recently
+// used queries, so repeated queries skip lexing and parsing. Queries with
+// syntax errors are not cached. Zero, the default, disables the cache and
+// drops its contents.
+func SetParseCacheSize(n int) {
+	parseCache.Lock()
+	defer parseCache.Unlock()
+	parseCache.capacity = n
+	parseCache.stats.Capacity = n
+	for parseCache.order.Len() > n {
+		evictOldest()
+	}
+}
+
+// ParseCacheStats returns the parse cache counters.
+func ParseCacheStats() CacheStats {
+	parseCache.Lock()
+	defer parseCache.Unlock()
+	stats := parseCache.stats
+	stats.Size = parseCache.order.Len()
+	return stats
+}
+
+// parseQuery returns the document of query and its syntax errors, using
+// the parse cache if it is enabled.
+func parseQuery(query string, debug *executor.Debug) (*ast.Document, []string) {
+	parseCache.Lock()
+	if el, ok := parseCache.entries[query]; ok {
+		parseCache.order.MoveToFront(el)
+		parseCache.stats.Hits++
+		parseCache.Unlock()
+		debug.RecordCacheHit()
+		return el.Value.(*cachedDoc).doc, nil
+	}
+	enabled := parseCache.capacity > 0
+	if enabled {
+		parseCache.stats.Misses++
+	}
+	parseCache.Unlock()
+	if enabled {
+		debug.RecordCacheMiss()
+	}
+
+	l := lexers.Get().(*lexer.Lexer)
+	l.Reset(query)
+	p := parser.New(l)
+	doc := p.ParseDocument()
+	lexers.Put(l)
+	if errs := p.Errors(); len(errs) > 0 || !enabled {
+		return doc, errs
+	}
+	parseCache.Lock()
+	defer parseCache.Unlock()
+	if _, ok := parseCache.entries[query]; !ok && parseCache.capacity > 0 {
+		parseCache.entries[query] = parseCache.order.PushFront(&cachedDoc{query: query, doc: doc})
+		for parseCache.order.Len() > parseCache.capacity {
+			evictOldest()
+		}
+	}
+	return doc, nil
+}
+
+// lexers holds lexers for reuse by parseQuery, which saves allocating
+// their buffers for every request.
+var lexers = sync.Pool{New: func() interface{} { return lexer.New("") }}
+
+// evictOldest drops the least recently used document. The cache must be
+// locked.
+func evictOldest() {
+	el := parseCache.order.Back()
+	parseCache.order.Remove(el)
+	delete(parseCache.entries, el.Value.(*cachedDoc).query)
+	parseCache.stats.Evictions++
+}
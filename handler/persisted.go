@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// PersistedDocuments is a manifest of the operations clients may run,
+// identified by document ID. Documents are also known by the SHA-256 hash
+// of their text, as Apollo clients send it.
+type PersistedDocuments struct {
+	byID   map[string]string
+	byHash map[string]string
+}
+
+// NewPersistedDocuments creates a manifest from query texts by ID.
+func NewPersistedDocuments(docs map[string]string) *PersistedDocuments {
+	d := &PersistedDocuments{byID: make(map[string]string, len(docs)), byHash: make(map[string]string, len(docs))}
+	for id, query := range docs {
+		d.byID[id] = query
+		d.byHash[hashQuery(query)] = query
+	}
+	return d
+}
+
+// ParsePersistedDocuments reads a manifest in the Relay format, a JSON
+// object of query texts by ID, or in the Apollo persisted query manifest
+// format.
+func ParsePersistedDocuments(data []byte) (*PersistedDocuments, error) {
+	var apollo struct {
+		Format     string `json:"format"`
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format != "" {
+		if apollo.Format != "apollo-persisted-query-manifest" {
+			return nil, fmt.Errorf("unsupported persisted documents format %q", apollo.Format)
+		}
+		docs := make(map[string]string, len(apollo.Operations))
+		for _, op := range apollo.Operations {
+			docs[op.ID] = op.Body
+		}
+		return NewPersistedDocuments(docs), nil
+	}
+	var relay map[string]string
+	if err := json.Unmarshal(data, &relay); err != nil {
+		return nil, fmt.Errorf("invalid persisted documents manifest: %w", err)
+	}
+	return NewPersistedDocuments(relay), nil
+}
+
+// Lookup returns the query text of the document with the given ID or
+// SHA-256 hash, which may have a "sha256:" prefix.
+func (d *PersistedDocuments) Lookup(id string) (string, bool) {
+	if query, ok := d.byID[id]; ok {
+		return query, true
+	}
+	query, ok := d.byHash[strings.TrimPrefix(id, "sha256:")]
+	return query, ok
+}
+
+// hashQuery returns the hex encoded SHA-256 hash of query.
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// persisted is the manifest of allowed documents; nil allows any query.
+var persisted *PersistedDocuments
+
+// SetPersistedDocuments only allows the operations in docs to run. Requests
+// name them by ID in documentId, or by hash in the Apollo
+// extensions.persistedQuery.sha256Hash; requests with query text run only
+// if the text is that of a document in docs. Other requests are rejected
+// with PersistedDocumentNotFound or PersistedDocumentRequired. nil, the
+// default, allows any query.
+func SetPersistedDocuments(docs *PersistedDocuments) {
+	persisted = docs
+}
+
+// documentID returns the persisted document req names, if any.
+func documentID(req GraphQLRequest) string {
+	if req.DocumentID != "" {
+		return req.DocumentID
+	}
+	pq, _ := req.Extensions["persistedQuery"].(map[string]interface{})
+	hash, _ := pq["sha256Hash"].(string)
+	return hash
+}
+
+// resolve replaces the query of req with the text of the persisted document
+// it names, allowing only the documents of d. A nil d allows any query.
+func (d *PersistedDocuments) resolve(req *GraphQLRequest) error {
+	if d == nil {
+		return nil
+	}
+	if id := documentID(*req); id != "" {
+		query, ok := d.Lookup(id)
+		if !ok {
+			return errcode.New(errcode.PersistedDocumentNotFound, "id", id)
+		}
+		req.Query = query
+		return nil
+	}
+	if _, ok := d.byHash[hashQuery(req.Query)]; !ok {
+		return errcode.New(errcode.PersistedDocumentRequired)
+	}
+	return nil
+}
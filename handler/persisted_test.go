@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/registry"
+)
+
+func TestParsePersistedDocuments(t *testing.T) {
+	relay, err := ParsePersistedDocuments([]byte(`{"a1": "{ ping }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query, ok := relay.Lookup("a1"); !ok || query != "{ ping }" {
+		t.Errorf("expected the Relay document, got %q", query)
+	}
+	hash := hashQuery("{ pong }")
+	apollo, err := ParsePersistedDocuments([]byte(`{"format": "apollo-persisted-query-manifest", "version": 1, "operations": [{"id": "` + hash + `", "name": "Pong", "type": "query", "body": "{ pong }"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query, ok := apollo.Lookup("sha256:" + hash); !ok || query != "{ pong }" {
+		t.Errorf("expected the Apollo document by hash, got %q", query)
+	}
+	if _, err := ParsePersistedDocuments([]byte(`{"format": "other"}`)); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestPersistedDocuments(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	exec.RegisterQueryResolver("ping", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "pong", nil
+	})
+	SetPersistedDocuments(NewPersistedDocuments(map[string]string{"ping1": "{ ping }"}))
+	defer SetPersistedDocuments(nil)
+
+	tests := []struct {
+		name, body string
+		code       errcode.Code
+	}{
+		{"document ID", `{"documentId": "ping1"}`, ""},
+		{"Apollo hash", `{"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "` + hashQuery("{ ping }") + `"}}}`, ""},
+		{"registered text", `{"query": "{ ping }"}`, ""},
+		{"unknown ID", `{"documentId": "other"}`, errcode.PersistedDocumentNotFound},
+		{"arbitrary text", `{"query": "{ ping ping2: ping }"}`, errcode.PersistedDocumentRequired},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		GraphQL(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(tt.body)))
+		var resp struct {
+			Data   map[string]interface{}
+			Errors []struct{ Extensions map[string]interface{} }
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		switch {
+		case tt.code == "" && resp.Data["ping"] != "pong":
+			t.Errorf("%s: expected the document to run, got %s", tt.name, w.Body.String())
+		case tt.code != "" && (len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != string(tt.code)):
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("GET", "/graphql?"+url.Values{"documentId": {"ping1"}}.Encode(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"pong"`) {
+		t.Errorf("expected the document to run over GET, got %d: %s", w.Code, w.Body.String())
+	}
+}
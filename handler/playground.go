@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+)
+
+// PlaygroundUI selects the user interface served by Playground.
+type PlaygroundUI int
+
+const (
+	// GraphiQL serves the GraphiQL IDE.
+	GraphiQL PlaygroundUI = iota
+	// ApolloSandbox serves the embeddable Apollo Sandbox.
+	ApolloSandbox
+	// GraphQLPlayground serves GraphQL Playground.
+	GraphQLPlayground
+)
+
+// PlaygroundConfig configures Playground.
+type PlaygroundConfig struct {
+	// UI is the interface to serve. It defaults to GraphiQL.
+	UI PlaygroundUI
+	// Title is the title of the page. It defaults to "GraphQL".
+	Title string
+	// Endpoint is the URL of the GraphQL handler, relative to the page or
+	// absolute. It defaults to "/graphql".
+	Endpoint string
+	// SubscriptionEndpoint is the URL of the subscription handler, if any.
+	SubscriptionEndpoint string
+	// Headers are sent with every request unless the user changes them,
+	// such as an Authorization header for local development.
+	Headers map[string]string
+	// Nonce returns the Content-Security-Policy nonce of the page's scripts
+	// and styles when the application sets its own policy. Without it,
+	// every response carries a policy that allows the page's scripts by a
+	// fresh nonce.
+	Nonce func(r *http.Request) string
+}
+
+// Playground returns a handler serving an in-browser IDE for exploring the
+// schema and running operations against config.Endpoint. The IDE is loaded
+// from its public CDN.
+func Playground(config PlaygroundConfig) http.HandlerFunc {
+	if config.Title == "" {
+		config.Title = "GraphQL"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "/graphql"
+	}
+	if config.Headers == nil {
+		config.Headers = map[string]string{}
+	}
+	page := playgroundPages[config.UI]
+	if page == nil {
+		page = playgroundPages[GraphiQL]
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var nonce string
+		if config.Nonce != nil {
+			nonce = config.Nonce(r)
+		} else {
+			nonce = newNonce()
+			w.Header().Set("Content-Security-Policy", "default-src 'self'; "+
+				"script-src 'nonce-"+nonce+"' 'strict-dynamic' https:; "+
+				"style-src 'self' 'unsafe-inline' https:; img-src 'self' data: https:; font-src 'self' data: https:; "+
+				"connect-src 'self' https: wss: ws:; frame-src https:; worker-src 'self' blob:; object-src 'none'; base-uri 'none'")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+		page.Execute(w, map[string]interface{}{
+			"Title":                config.Title,
+			"Endpoint":             config.Endpoint,
+			"SubscriptionEndpoint": config.SubscriptionEndpoint,
+			"Headers":              config.Headers,
+			"Nonce":                nonce,
+		})
+	}
+}
+
+// newNonce returns a random Content-Security-Policy nonce.
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// playgroundPages are the pages of the playground UIs. Endpoints are
+// resolved against the page URL since not every UI accepts relative URLs.
+var playgroundPages = map[PlaygroundUI]*template.Template{
+	GraphiQL: template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style nonce="{{.Nonce}}">body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+<link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+</head>
+<body>
+<div id="graphiql">Loading…</div>
+<script nonce="{{.Nonce}}" src="https://unpkg.com/react@18/umd/react.production.min.js" crossorigin></script>
+<script nonce="{{.Nonce}}" src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js" crossorigin></script>
+<script nonce="{{.Nonce}}" src="https://unpkg.com/graphiql@3/graphiql.min.js" crossorigin></script>
+<script nonce="{{.Nonce}}">
+var url = new URL({{.Endpoint}}, location.href).href;
+var subscriptionUrl = {{.SubscriptionEndpoint}};
+if (subscriptionUrl) {
+  subscriptionUrl = new URL(subscriptionUrl, location.href.replace(/^http/, "ws")).href;
+}
+var fetcher = GraphiQL.createFetcher({url: url, subscriptionUrl: subscriptionUrl || undefined, headers: {{.Headers}}});
+ReactDOM.createRoot(document.getElementById("graphiql")).render(
+  React.createElement(GraphiQL, {fetcher: fetcher, defaultHeaders: JSON.stringify({{.Headers}}, null, 2)})
+);
+</script>
+</body>
+</html>
+`)),
+	ApolloSandbox: template.Must(template.New("sandbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style nonce="{{.Nonce}}">body { margin: 0; } #sandbox { height: 100vh; width: 100vw; }</style>
+</head>
+<body>
+<div id="sandbox"></div>
+<script nonce="{{.Nonce}}" src="https://embeddable-sandbox.cdn.apollographql.com/_latest/embeddable-sandbox.umd.production.min.js"></script>
+<script nonce="{{.Nonce}}">
+new window.EmbeddedSandbox({
+  target: "#sandbox",
+  initialEndpoint: new URL({{.Endpoint}}, location.href).href,
+  initialSubscriptionEndpoint: {{.SubscriptionEndpoint}} ? new URL({{.SubscriptionEndpoint}}, location.href.replace(/^http/, "ws")).href : undefined,
+  initialState: {sharedHeaders: {{.Headers}}},
+});
+</script>
+</body>
+</html>
+`)),
+	GraphQLPlayground: template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/graphql-playground-react/build/static/css/index.css">
+</head>
+<body>
+<div id="root"></div>
+<script nonce="{{.Nonce}}" src="https://cdn.jsdelivr.net/npm/graphql-playground-react/build/static/js/middleware.js"></script>
+<script nonce="{{.Nonce}}">
+window.addEventListener("load", function () {
+  GraphQLPlayground.init(document.getElementById("root"), {
+    endpoint: new URL({{.Endpoint}}, location.href).href,
+    subscriptionEndpoint: {{.SubscriptionEndpoint}} ? new URL({{.SubscriptionEndpoint}}, location.href.replace(/^http/, "ws")).href : undefined,
+    headers: {{.Headers}},
+  });
+});
+</script>
+</body>
+</html>
+`)),
+}
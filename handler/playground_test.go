@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlayground(t *testing.T) {
+	for _, ui := range []PlaygroundUI{GraphiQL, ApolloSandbox, GraphQLPlayground} {
+		h := Playground(PlaygroundConfig{
+			UI:       ui,
+			Title:    "Shop </title>",
+			Endpoint: "/api/graphql",
+			Headers:  map[string]string{"Authorization": "Bearer dev"},
+		})
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", "/playground", nil))
+		body := w.Body.String()
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Fatalf("UI %d: expected an HTML page, got %d %s", ui, w.Code, w.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(body, "<title>Shop &lt;/title&gt;</title>") {
+			t.Errorf("UI %d: expected the escaped title, got %s", ui, body)
+		}
+		if !strings.Contains(body, `"/api/graphql"`) || !strings.Contains(body, `{"Authorization":"Bearer dev"}`) {
+			t.Errorf("UI %d: expected the endpoint and headers, got %s", ui, body)
+		}
+		csp := w.Header().Get("Content-Security-Policy")
+		nonce, _, _ := strings.Cut(strings.TrimPrefix(csp[strings.Index(csp, "'nonce-"):], "'nonce-"), "'")
+		if nonce == "" || !strings.Contains(body, `<script nonce="`+nonce+`">`) {
+			t.Errorf("UI %d: expected scripts with the nonce of %q", ui, csp)
+		}
+	}
+
+	h := Playground(PlaygroundConfig{Nonce: func(r *http.Request) string { return "app-nonce" }})
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/playground", nil))
+	if w.Header().Get("Content-Security-Policy") != "" || !strings.Contains(w.Body.String(), `nonce="app-nonce"`) {
+		t.Errorf("expected the application's nonce and no policy, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/format"
+)
+
+// Schema serves the schema of the request's executor as printed SDL.
+// Responses carry an ETag so clients can poll cheaply with If-None-Match. It
+// responds 404 if no schema is set.
+func Schema(w http.ResponseWriter, r *http.Request) {
+	if h := defaultHandler(); h.config.CORS.handle(w, r) {
+		h.serveSchema(w, r)
+	}
+}
+
+// serveSchema serves the schema of the handler's executor as Schema does.
+func (h *Handler) serveSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, err := withTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	schema := h.executorFor(r).Schema()
+	if schema == nil {
+		http.Error(w, "no schema configured", http.StatusNotFound)
+		return
+	}
+
+	sdl := format.Schema(schema, format.Options{})
+	sum := sha256.Sum256([]byte(sdl))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write([]byte(sdl))
+}
+
+// etagMatch reports whether an If-None-Match header value matches etag.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// sdlRequested reports whether r asks for the schema with a "?sdl" query.
+func sdlRequested(r *http.Request) bool {
+	_, ok := r.URL.Query()["sdl"]
+	return r.Method == http.MethodGet && ok
+}
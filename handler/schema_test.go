@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+	"github.com/Protocol-Lattice/graphql/registry"
+)
+
+func TestSchemaETag(t *testing.T) {
+	exec := registry.GetGlobalExecutor()
+	exec.SetSchema(parser.New(lexer.New(`type Query { hello(name: String!): String }`)).ParseDocument())
+	defer exec.SetSchema(nil)
+
+	w := httptest.NewRecorder()
+	GraphQL(w, httptest.NewRequest("GET", "/graphql?sdl", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if want := "type Query {\n  hello(name: String!): String\n}\n"; w.Body.String() != want {
+		t.Errorf("expected SDL %q, got %q", want, w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	r := httptest.NewRequest("GET", "/graphql/schema", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	Schema(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected empty 304, got %d with %q", w.Code, w.Body.String())
+	}
+}
+
+func TestSchemaNotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	Schema(w, httptest.NewRequest("GET", "/graphql/schema", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
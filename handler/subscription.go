@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/errcode"
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/transport"
+	"github.com/Protocol-Lattice/graphql/transport/websocket"
+)
+
+// Upgrader turns an HTTP request into a transport connection used to
+// stream subscription events.
+type Upgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request) (transport.Conn, error)
+}
+
+// upgrader upgrades HTTP connections for subscriptions (WebSocket by default).
+var upgrader Upgrader = websocket.New()
+
+// SetUpgrader replaces the transport used by the Subscription handler.
+func SetUpgrader(u Upgrader) {
+	upgrader = u
+}
+
+// idleTimeout is how long a subscription may go without events or client
+// messages before it is torn down. Zero disables reaping.
+var idleTimeout time.Duration
+
+// SetSubscriptionIdleTimeout sets how long a subscription may go without
+// delivering an event or receiving a client message before the server
+// sends a complete message and closes it, stopping iterator-based
+// producers. Idle subscriptions are detected within half the timeout, or
+// a millisecond for shorter timeouts. Zero (the default) disables reaping.
+func SetSubscriptionIdleTimeout(d time.Duration) {
+	idleTimeout = d
+}
+
+// ConnectionAuthFunc authenticates a subscription connection from the
+// upgrade request and the payload sent by the client. It returns when the
+// credentials expire (the zero time if they never do), or an error to
+// reject the connection.
+type ConnectionAuthFunc func(r *http.Request, payload map[string]interface{}) (expiresAt time.Time, err error)
+
+// connectionAuth authenticates subscription connections; nil accepts all.
+var connectionAuth ConnectionAuthFunc
+
+// SetConnectionAuth installs the hook used to authenticate subscriptions.
+// The payload comes from the "payload" field of the subscription request.
+// When the returned expiry passes, the hook is called again with the most
+// recent payload and the connection is terminated unless it succeeds with
+// a later expiry. Clients can refresh credentials without reconnecting by
+// sending {"type": "auth", "payload": {...}}, which is answered with
+// {"type": "auth_ack"} on success.
+func SetConnectionAuth(fn ConnectionAuthFunc) {
+	connectionAuth = fn
+}
+
+// Server-to-client protocol messages.
+var (
+	completeMessage = map[string]interface{}{"type": "complete"}
+	authAckMessage  = map[string]interface{}{"type": "auth_ack"}
+)
+
+// errorMessage builds a protocol error message.
+func errorMessage(msg string) map[string]interface{} {
+	return map[string]interface{}{"type": "error", "payload": map[string]interface{}{"message": msg}}
+}
+
+// subscriptionRequest is the first message of a subscription connection.
+type subscriptionRequest struct {
+	GraphQLRequest
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// clientMessage is a protocol message sent by the client after the
+// subscription has started.
+type clientMessage struct {
+	Type    string                 `json:"type"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// authenticate runs the connection auth hook, returning the credential
+// expiry (zero for none). A past expiry is treated as a failure.
+func authenticate(r *http.Request, payload map[string]interface{}) (time.Time, error) {
+	if connectionAuth == nil {
+		return time.Time{}, nil
+	}
+	expiresAt, err := connectionAuth(r, payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("credentials expired")
+	}
+	return expiresAt, nil
+}
+
+// writeSubscriptionErrors writes errs to conn as a message with an errors
+// array, presented as the errors of HTTP responses are.
+func (h *Handler) writeSubscriptionErrors(conn transport.Conn, r *http.Request, errs ...error) {
+	gqlErrs := make([]*executor.Error, len(errs))
+	for i, err := range errs {
+		gqlErrs[i] = h.presentError(r, err)
+	}
+	conn.WriteJSON(map[string]interface{}{"errors": gqlErrs})
+}
+
+// activity tracks the last event and last client message of a subscription.
+type activity struct {
+	lastEvent  atomic.Int64 // Unix nanoseconds
+	lastClient atomic.Int64 // Unix nanoseconds
+}
+
+// idleFor returns how long it has been since any activity.
+func (a *activity) idleFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, max(a.lastEvent.Load(), a.lastClient.Load())))
+}
+
+// Subscription handles GraphQL subscriptions over WebSocket. With CORS
+// configured by SetCORS, connections from other origins than the API's own
+// and those it allows are rejected.
+//
+// Subscription uses the global executor and the package-level settings; a
+// Handler created by New serves subscriptions by its own Config.
+func Subscription(w http.ResponseWriter, r *http.Request) {
+	defaultHandler().serveSubscription(w, r)
+}
+
+// serveSubscription serves a subscription over the connection upgraded
+// from r.
+func (h *Handler) serveSubscription(w http.ResponseWriter, r *http.Request) {
+	if !h.config.CORS.allowsUpgrade(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	r, err := withTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// Upgrade HTTP to WebSocket
+	conn, err := upgrader.Upgrade(w, r)
+	if err != nil {
+		http.Error(w, "unable to upgrade to websocket", http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+	if limiter, ok := conn.(transport.ReadLimiter); ok && h.config.MaxBodySize > 0 {
+		// Larger messages close the connection.
+		limiter.SetReadLimit(h.config.MaxBodySize)
+	}
+
+	// Read the subscription request from the WebSocket
+	msg, err := conn.ReadMessage()
+	if err != nil {
+		conn.WriteMessage([]byte("failed to read subscription message"))
+		return
+	}
+
+	var req subscriptionRequest
+	if err := decodeRequest(msg, &req); err != nil {
+		conn.WriteMessage([]byte("invalid subscription JSON"))
+		return
+	}
+	exactNumbers(req.Variables)
+	exactNumbers(req.Payload)
+
+	// Authenticate the connection
+	payload := req.Payload
+	expiresAt, err := authenticate(r, payload)
+	if err != nil {
+		conn.WriteJSON(errorMessage("unauthorized: " + err.Error()))
+		return
+	}
+
+	if err := h.config.PersistedDocuments.resolve(&req.GraphQLRequest); err != nil {
+		conn.WriteJSON(errorMessage(err.Error()))
+		return
+	}
+
+	// Lex and parse the subscription, checking it like queries
+	start := time.Now()
+	doc, syntaxErrs := parseQuery(req.Query, nil)
+	parseTime := time.Since(start)
+	if len(syntaxErrs) > 0 {
+		errs := make([]error, len(syntaxErrs))
+		for i, msg := range syntaxErrs {
+			errs[i] = errcode.New(errcode.SyntaxError, "detail", msg)
+		}
+		h.writeSubscriptionErrors(conn, r, errs...)
+		return
+	}
+	lifecycle := lifecycleFrom(r.Context())
+	lifecycle.parsed(r.Context(), req.OperationName, operationType(doc, req.OperationName), parseTime)
+	if err := h.checkLimits(doc, req.Variables); err != nil {
+		h.writeSubscriptionErrors(conn, r, err)
+		return
+	}
+
+	// Cancel the subscription once the client goes away
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var act activity
+	act.lastEvent.Store(time.Now().UnixNano())
+	reauth := make(chan map[string]interface{})
+	go func() {
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				cancel()
+				return
+			}
+			act.lastClient.Store(time.Now().UnixNano())
+			var cm clientMessage
+			if json.Unmarshal(msg, &cm) == nil && cm.Type == "auth" {
+				select {
+				case reauth <- cm.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// Execute the subscription
+	start = time.Now()
+	var errorCount int
+	defer func() {
+		lifecycle.executed(ctx, errorCount, time.Since(start))
+	}()
+	exec := h.executorFor(r)
+	subCh, err := exec.ExecuteSubscriptionOperation(ctx, doc, req.OperationName, req.Variables)
+	if err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			// Validation reports each violation as a separate error.
+			errs = joined.Unwrap()
+		}
+		errorCount = len(errs)
+		h.writeSubscriptionErrors(conn, r, errs...)
+		return
+	}
+
+	// Reap the subscription if it goes idle
+	timeout := idleTimeout
+	var reap <-chan time.Time
+	if timeout > 0 {
+		ticker := time.NewTicker(max(timeout/2, time.Millisecond))
+		defer ticker.Stop()
+		reap = ticker.C
+	}
+
+	// Re-validate the connection when its credentials expire
+	var expiry <-chan time.Time
+	resetExpiry := func() {
+		expiry = nil
+		if !expiresAt.IsZero() {
+			expiry = time.After(time.Until(expiresAt))
+		}
+	}
+	resetExpiry()
+
+	// Stream events from the subscription channel to the WebSocket
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case newPayload := <-reauth:
+			newExpiry, err := authenticate(r, newPayload)
+			if err != nil {
+				conn.WriteJSON(errorMessage("unauthorized: " + err.Error()))
+				return
+			}
+			payload, expiresAt = newPayload, newExpiry
+			resetExpiry()
+			conn.WriteJSON(authAckMessage)
+		case <-expiry:
+			newExpiry, err := authenticate(r, payload)
+			if err != nil {
+				conn.WriteJSON(errorMessage("authentication expired"))
+				return
+			}
+			expiresAt = newExpiry
+			resetExpiry()
+		case now := <-reap:
+			if act.idleFor(now) >= timeout {
+				conn.WriteJSON(completeMessage)
+				return
+			}
+		case event, ok := <-subCh:
+			if !ok {
+				return
+			}
+			if err, isErr := event.(error); isErr {
+				errorCount++
+				h.writeSubscriptionErrors(conn, r, err)
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				h.logger().Warn("failed to write subscription event", "error", err)
+				return
+			}
+			act.lastEvent.Store(time.Now().UnixNano())
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+	"github.com/Protocol-Lattice/graphql/registry"
+	"github.com/Protocol-Lattice/graphql/transport"
+)
+
+// fakeConn is an in-memory transport.Conn driven by the test.
+type fakeConn struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+	once   sync.Once
+	limit  int64
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{in: make(chan []byte, 8), out: make(chan []byte, 64), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) ReadMessage() ([]byte, error) {
+	select {
+	case msg := <-c.in:
+		return msg, nil
+	case <-c.closed:
+		return nil, errors.New("closed")
+	}
+}
+
+func (c *fakeConn) WriteMessage(data []byte) error {
+	c.out <- data
+	return nil
+}
+
+func (c *fakeConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(data)
+}
+
+func (c *fakeConn) SetReadLimit(n int64) {
+	c.limit = n
+}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+// fakeUpgrader hands out a prepared fakeConn.
+type fakeUpgrader struct{ conn *fakeConn }
+
+func (u fakeUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (transport.Conn, error) {
+	return u.conn, nil
+}
+
+// serveSubscription runs the Subscription handler against conn in the background.
+func serveSubscription(t *testing.T, conn *fakeConn) <-chan struct{} {
+	t.Helper()
+	prev := upgrader
+	SetUpgrader(fakeUpgrader{conn: conn})
+	t.Cleanup(func() { SetUpgrader(prev) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Subscription(httptest.NewRecorder(), httptest.NewRequest("GET", "/subscriptions", nil))
+	}()
+	return done
+}
+
+func TestSubscriptionIdleReaping(t *testing.T) {
+	stopped := make(chan struct{})
+	registry.RegisterSubscriptionResolver("quiet", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq[int](func(yield func(int) bool) {
+			defer close(stopped)
+			for i := 1; yield(i); i++ {
+				time.Sleep(200 * time.Millisecond) // far longer than the idle timeout
+			}
+		}), nil
+	})
+	SetSubscriptionIdleTimeout(50 * time.Millisecond)
+	defer SetSubscriptionIdleTimeout(0)
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"query": "subscription { quiet }"}`)
+	done := serveSubscription(t, conn)
+
+	if msg := <-conn.out; string(msg) != "1" {
+		t.Fatalf("expected first event, got %s", msg)
+	}
+
+	select {
+	case msg := <-conn.out:
+		if string(msg) != `{"type":"complete"}` {
+			t.Fatalf("expected complete message, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle subscription was not reaped")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return after reaping")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("producer was not stopped after reaping")
+	}
+}
+
+func TestSubscriptionTinyIdleTimeout(t *testing.T) {
+	registry.RegisterSubscriptionResolver("silent", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return make(chan interface{}), nil
+	})
+	SetSubscriptionIdleTimeout(time.Nanosecond)
+	defer SetSubscriptionIdleTimeout(0)
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"query": "subscription { silent }"}`)
+	done := serveSubscription(t, conn)
+
+	select {
+	case msg := <-conn.out:
+		if string(msg) != `{"type":"complete"}` {
+			t.Fatalf("expected complete message, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle subscription was not reaped")
+	}
+	<-done
+}
+
+func TestSubscriptionAuthExpiry(t *testing.T) {
+	registry.RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return make(chan interface{}), nil // never emits
+	})
+	// Tokens are "short", "long" or anything else (rejected); each is only
+	// valid once, so expiry re-validation with the same payload fails.
+	var mu sync.Mutex
+	used := map[string]bool{}
+	SetConnectionAuth(func(r *http.Request, payload map[string]interface{}) (time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		token, _ := payload["token"].(string)
+		if used[token] {
+			return time.Time{}, errors.New("token already used")
+		}
+		used[token] = true
+		switch token {
+		case "short":
+			return time.Now().Add(50 * time.Millisecond), nil
+		case "long":
+			return time.Now().Add(150 * time.Millisecond), nil
+		}
+		return time.Time{}, errors.New("invalid token")
+	})
+	defer SetConnectionAuth(nil)
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"query": "subscription { ticks }", "payload": {"token": "short"}}`)
+	done := serveSubscription(t, conn)
+
+	conn.in <- []byte(`{"type": "auth", "payload": {"token": "long"}}`)
+	if msg := <-conn.out; string(msg) != `{"type":"auth_ack"}` {
+		t.Fatalf("expected auth_ack, got %s", msg)
+	}
+
+	start := time.Now()
+	select {
+	case msg := <-conn.out:
+		if string(msg) != `{"payload":{"message":"authentication expired"},"type":"error"}` {
+			t.Fatalf("expected expiry error, got %s", msg)
+		}
+		if time.Since(start) < 75*time.Millisecond {
+			t.Fatal("connection expired before the refreshed token")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expired connection was not terminated")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return after expiry")
+	}
+}
+
+func TestSubscriptionAuthRejected(t *testing.T) {
+	SetConnectionAuth(func(r *http.Request, payload map[string]interface{}) (time.Time, error) {
+		return time.Time{}, errors.New("missing token")
+	})
+	defer SetConnectionAuth(nil)
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"query": "subscription { ticks }"}`)
+	done := serveSubscription(t, conn)
+
+	if msg := <-conn.out; string(msg) != `{"payload":{"message":"unauthorized: missing token"},"type":"error"}` {
+		t.Fatalf("expected unauthorized error, got %s", msg)
+	}
+	<-done
+}
+
+func TestSubscriptionReadLimit(t *testing.T) {
+	SetMaxBodySize(1024)
+	defer SetMaxBodySize(0)
+
+	conn := newFakeConn()
+	done := serveSubscription(t, conn)
+	conn.Close()
+	<-done
+	if conn.limit != 1024 {
+		t.Errorf("expected a read limit of 1024, got %d", conn.limit)
+	}
+}
+
+func TestHandlerServesSubscriptions(t *testing.T) {
+	exec := executor.New()
+	exec.RegisterSubscriptionResolver("greetings", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq[string](func(yield func(string) bool) { yield("hello") }), nil
+	})
+	conn := newFakeConn()
+	prev := upgrader
+	SetUpgrader(fakeUpgrader{conn: conn})
+	defer SetUpgrader(prev)
+	var operation string
+	h := New(Config{
+		Executor:    exec,
+		MaxBodySize: 2048,
+		Hooks: &Hooks{OperationParsed: func(ctx context.Context, info RequestInfo) {
+			operation = info.OperationType
+		}},
+	})
+
+	conn.in <- []byte(`{"query": "subscription { greetings }"}`)
+	r := httptest.NewRequest("GET", "/graphql", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if msg := <-conn.out; string(msg) != `"hello"` {
+		t.Errorf("expected the handler's executor to serve the subscription, got %s", msg)
+	}
+	if conn.limit != 2048 || operation != "subscription" {
+		t.Errorf("expected the handler's config to apply, got limit %d and operation %q", conn.limit, operation)
+	}
+}
+
+func TestSubscriptionErrorsArePresented(t *testing.T) {
+	exec := executor.New()
+	exec.RegisterSubscriptionResolver("feed", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq2[int, error](func(yield func(int, error) bool) {
+			yield(0, errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+		}), nil
+	})
+	conn := newFakeConn()
+	prev := upgrader
+	SetUpgrader(fakeUpgrader{conn: conn})
+	defer SetUpgrader(prev)
+	h := New(Config{
+		Executor: exec,
+		ErrorPresenter: func(ctx context.Context, err error) *executor.Error {
+			return &executor.Error{Message: "internal error"}
+		},
+	})
+
+	conn.in <- []byte(`{"query": "subscription { feed }"}`)
+	r := httptest.NewRequest("GET", "/graphql", nil)
+	r.Header.Set("Upgrade", "websocket")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if msg := <-conn.out; string(msg) != `{"errors":[{"message":"internal error"}]}` {
+		t.Errorf("expected the presented error, got %s", msg)
+	}
+}
+
+func TestHandlerChecksSubscriptions(t *testing.T) {
+	exec := executor.New()
+	exec.SetSchema(parser.New(lexer.New(`type Query { hello: String } type Subscription { greetings: Greeting } type Greeting { text: String from: Greeting }`)).ParseDocument())
+	exec.SetValidation(true)
+	exec.RegisterSubscriptionResolver("greetings", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return iter.Seq[map[string]interface{}](func(yield func(map[string]interface{}) bool) {
+			yield(map[string]interface{}{"text": "hello"})
+		}), nil
+	})
+	h := New(Config{Executor: exec, MaxDepth: 2})
+
+	tests := []struct {
+		name, request, want string
+	}{
+		{"syntax error", `{"query": "subscription { greetings(from: ) }"}`, `SYNTAX_ERROR`},
+		{"invalid", `{"query": "subscription { farewells }"}`, `farewells`},
+		{"too deep", `{"query": "subscription { greetings { from { text } } }"}`, `depth`},
+		{"unknown operation", `{"query": "subscription A { greetings { text } }", "operationName": "B"}`, `unknown operation \"B\"`},
+		{"not a subscription", `{"query": "subscription A { greetings { text } } query B { hello }", "operationName": "B"}`, `not a subscription`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newFakeConn()
+			prev := upgrader
+			SetUpgrader(fakeUpgrader{conn: conn})
+			defer SetUpgrader(prev)
+			conn.in <- []byte(tt.request)
+			r := httptest.NewRequest("GET", "/graphql", nil)
+			r.Header.Set("Upgrade", "websocket")
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			msg := string(<-conn.out)
+			if !strings.HasPrefix(msg, `{"errors":[`) || !strings.Contains(msg, tt.want) {
+				t.Errorf("expected errors mentioning %s, got %s", tt.want, msg)
+			}
+		})
+	}
+}
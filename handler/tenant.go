@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/registry"
+)
+
+// Tenant is the isolated execution environment of one tenant: its own
+// executor (and so its own resolvers and schema) plus a namespace for
+// shared infrastructure such as pubsub topics and cache keys.
+type Tenant struct {
+	// ID identifies the tenant.
+	ID string
+	// Executor runs the tenant's operations.
+	Executor *executor.Executor
+	// Namespace prefixes the tenant's topics and keys. It defaults to ID.
+	Namespace string
+}
+
+// Key returns name scoped to the tenant's namespace, for use as a pubsub
+// topic or cache key.
+func (t *Tenant) Key(name string) string {
+	ns := t.Namespace
+	if ns == "" {
+		ns = t.ID
+	}
+	return ns + ":" + name
+}
+
+// TenantExtractor returns the tenant ID of a request.
+type TenantExtractor func(r *http.Request) (string, error)
+
+// HeaderTenant extracts the tenant ID from a request header.
+func HeaderTenant(header string) TenantExtractor {
+	return func(r *http.Request) (string, error) {
+		if id := r.Header.Get(header); id != "" {
+			return id, nil
+		}
+		return "", fmt.Errorf("missing %s header", header)
+	}
+}
+
+// SubdomainTenant extracts the tenant ID from the first label of the host
+// name, so "acme.api.example.com" belongs to tenant "acme".
+func SubdomainTenant() TenantExtractor {
+	return func(r *http.Request) (string, error) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if label, _, ok := strings.Cut(host, "."); ok && label != "" {
+			return label, nil
+		}
+		return "", errors.New("host has no tenant subdomain")
+	}
+}
+
+// tenants holds the configured tenant routing.
+var tenants struct {
+	extract TenantExtractor
+	lookup  func(id string) (*Tenant, bool)
+}
+
+// SetTenants routes every request to the tenant chosen by extract and
+// looked up by lookup, instead of the global executor. Requests whose
+// tenant cannot be determined or is unknown are rejected. Tenants can be
+// chosen from a JWT claim by an extractor that reads the claims stored in
+// the request context by authentication middleware. Passing nil for
+// extract disables routing.
+func SetTenants(extract TenantExtractor, lookup func(id string) (*Tenant, bool)) {
+	tenants.extract = extract
+	tenants.lookup = lookup
+}
+
+// tenantKey is the context key for the request's tenant.
+type tenantKey struct{}
+
+// TenantFromContext returns the tenant serving a request, or nil if tenant
+// routing is disabled.
+func TenantFromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return t
+}
+
+// withTenant resolves the tenant of r, if routing is enabled, and returns
+// r with the tenant in its context.
+func withTenant(r *http.Request) (*http.Request, error) {
+	if tenants.extract == nil {
+		return r, nil
+	}
+	id, err := tenants.extract(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine tenant: %v", err)
+	}
+	t, ok := tenants.lookup(id)
+	if !ok || t.Executor == nil {
+		return nil, fmt.Errorf("unknown tenant %q", id)
+	}
+	return r.WithContext(context.WithValue(r.Context(), tenantKey{}, t)), nil
+}
+
+// executorFor returns the executor serving r: its tenant's, or the global one.
+func executorFor(r *http.Request) *executor.Executor {
+	if t := TenantFromContext(r.Context()); t != nil {
+		return t.Executor
+	}
+	return registry.GetGlobalExecutor()
+}
+
+// executorFor returns the executor serving r: its tenant's, or the
+// handler's.
+func (h *Handler) executorFor(r *http.Request) *executor.Executor {
+	if t := TenantFromContext(r.Context()); t != nil || h.config.Executor == nil {
+		return executorFor(r)
+	}
+	return h.config.Executor
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/executor"
+)
+
+func TestTenantRouting(t *testing.T) {
+	byID := map[string]*Tenant{}
+	for _, id := range []string{"acme", "globex"} {
+		exec := executor.New()
+		name := id
+		exec.RegisterQueryResolver("company", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return name, nil
+		})
+		byID[id] = &Tenant{ID: id, Executor: exec}
+	}
+	SetTenants(HeaderTenant("X-Tenant"), func(id string) (*Tenant, bool) {
+		t, ok := byID[id]
+		return t, ok
+	})
+	defer SetTenants(nil, nil)
+
+	query := func(tenant string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ company }"}`))
+		if tenant != "" {
+			r.Header.Set("X-Tenant", tenant)
+		}
+		w := httptest.NewRecorder()
+		GraphQL(w, r)
+		return w
+	}
+
+	for _, id := range []string{"acme", "globex"} {
+		w := query(id)
+		if want := `{"data":{"company":"` + id + `"}}`; strings.TrimSpace(w.Body.String()) != want {
+			t.Errorf("tenant %s: expected %s, got %s", id, want, w.Body.String())
+		}
+	}
+	for _, id := range []string{"initech", ""} {
+		if w := query(id); w.Code != http.StatusForbidden {
+			t.Errorf("tenant %q: expected 403, got %d", id, w.Code)
+		}
+	}
+}
+
+func TestSubdomainTenant(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://acme.api.example.com:8080/graphql", nil)
+	if id, err := SubdomainTenant()(r); err != nil || id != "acme" {
+		t.Errorf("expected tenant acme, got %q (%v)", id, err)
+	}
+	if got := (&Tenant{ID: "acme"}).Key("orders"); got != "acme:orders" {
+		t.Errorf("expected namespaced key, got %q", got)
+	}
+}
@@ -0,0 +1,186 @@
+// Package introspection builds GraphQL introspection results from a parsed
+// SDL document, in the shape returned by the `__schema` meta field.
+package introspection
+
+import "github.com/Protocol-Lattice/graphql/ast"
+
+// Type kinds as defined by the GraphQL specification.
+const (
+	KindScalar    = "SCALAR"
+	KindObject    = "OBJECT"
+	KindInterface = "INTERFACE"
+	KindUnion     = "UNION"
+	KindList      = "LIST"
+	KindNonNull   = "NON_NULL"
+)
+
+// builtinScalars lists the scalar types every schema provides.
+var builtinScalars = []string{"String", "Int", "Float", "Boolean", "ID"}
+
+// Schema returns the introspection representation of the schema described by doc.
+func Schema(doc *ast.Document) map[string]interface{} {
+	kinds := typeKinds(doc)
+
+	var types []interface{}
+	for _, name := range builtinScalars {
+		types = append(types, map[string]interface{}{
+			"kind":        KindScalar,
+			"name":        name,
+			"description": nil,
+			"fields":      nil,
+		})
+	}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			types = append(types, objectType(def, kinds))
+		case *ast.InterfaceTypeDefinition:
+			types = append(types, interfaceType(def, doc, kinds))
+		case *ast.UnionTypeDefinition:
+			types = append(types, unionType(def, kinds))
+		}
+	}
+
+	return map[string]interface{}{
+		"queryType":        rootType(kinds, doc.RootTypeName("query")),
+		"mutationType":     rootType(kinds, doc.RootTypeName("mutation")),
+		"subscriptionType": rootType(kinds, doc.RootTypeName("subscription")),
+		"types":            types,
+		"directives":       []interface{}{},
+	}
+}
+
+// typeKinds indexes the kinds of the object, interface and union types in
+// doc by name. Other names are treated as scalars.
+func typeKinds(doc *ast.Document) map[string]string {
+	kinds := make(map[string]string)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			kinds[def.Name] = KindObject
+		case *ast.InterfaceTypeDefinition:
+			kinds[def.Name] = KindInterface
+		case *ast.UnionTypeDefinition:
+			kinds[def.Name] = KindUnion
+		}
+	}
+	return kinds
+}
+
+// rootType returns a type reference for a root operation type, or nil if
+// the schema does not define it.
+func rootType(kinds map[string]string, name string) interface{} {
+	if kinds[name] != KindObject {
+		return nil
+	}
+	return map[string]interface{}{"name": name}
+}
+
+// objectType converts an object type definition to its introspection form.
+func objectType(def *ast.TypeDefinition, kinds map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":        KindObject,
+		"name":        def.Name,
+		"description": description(def.Description),
+		"fields":      fields(def.Fields, kinds),
+		"interfaces":  namedRefs(def.Interfaces, kinds),
+	}
+}
+
+// interfaceType converts an interface type definition to its introspection
+// form. Its possible types are the object types in doc implementing it.
+func interfaceType(def *ast.InterfaceTypeDefinition, doc *ast.Document, kinds map[string]string) map[string]interface{} {
+	var implementations []string
+	for _, other := range doc.Definitions {
+		if obj, ok := other.(*ast.TypeDefinition); ok {
+			for _, name := range obj.Interfaces {
+				if name == def.Name {
+					implementations = append(implementations, obj.Name)
+				}
+			}
+		}
+	}
+	return map[string]interface{}{
+		"kind":          KindInterface,
+		"name":          def.Name,
+		"description":   description(def.Description),
+		"fields":        fields(def.Fields, kinds),
+		"interfaces":    namedRefs(def.Interfaces, kinds),
+		"possibleTypes": namedRefs(implementations, kinds),
+	}
+}
+
+// unionType converts a union type definition to its introspection form.
+func unionType(def *ast.UnionTypeDefinition, kinds map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":          KindUnion,
+		"name":          def.Name,
+		"description":   description(def.Description),
+		"fields":        nil,
+		"possibleTypes": namedRefs(def.Types, kinds),
+	}
+}
+
+// fields converts field definitions to their introspection form.
+func fields(defs []*ast.Field, kinds map[string]string) []interface{} {
+	out := []interface{}{}
+	for _, f := range defs {
+		out = append(out, map[string]interface{}{
+			"name":              f.Name,
+			"description":       description(f.Description),
+			"args":              []interface{}{},
+			"type":              typeRef(f.Type, kinds),
+			"isDeprecated":      f.Deprecated,
+			"deprecationReason": deprecationReason(f),
+		})
+	}
+	return out
+}
+
+// namedRefs returns type references to the named types.
+func namedRefs(names []string, kinds map[string]string) []interface{} {
+	refs := []interface{}{}
+	for _, name := range names {
+		refs = append(refs, typeRef(&ast.Type{Name: name}, kinds))
+	}
+	return refs
+}
+
+// description returns the introspection form of a description, which is
+// null when there is none.
+func description(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// deprecationReason returns the deprecation reason of a field, or nil if it
+// is not deprecated.
+func deprecationReason(f *ast.Field) interface{} {
+	if !f.Deprecated {
+		return nil
+	}
+	return f.DeprecationReason
+}
+
+// typeRef converts an AST type into a nested introspection type reference.
+func typeRef(t *ast.Type, kinds map[string]string) interface{} {
+	if t == nil {
+		return nil
+	}
+	var ref map[string]interface{}
+	if t.IsList {
+		ref = map[string]interface{}{"kind": KindList, "name": nil, "ofType": typeRef(t.Elem, kinds)}
+	} else {
+		kind := KindScalar
+		if k, ok := kinds[t.Name]; ok {
+			kind = k
+		}
+		ref = map[string]interface{}{"kind": kind, "name": t.Name, "ofType": nil}
+	}
+	if t.NonNull {
+		return map[string]interface{}{"kind": KindNonNull, "name": nil, "ofType": ref}
+	}
+	return ref
+}
@@ -1,6 +1,10 @@
 package lexer
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
 	"unicode"
 
 	"github.com/Protocol-Lattice/graphql/token"
@@ -8,35 +12,125 @@ import (
 
 // Lexer tokenizes GraphQL source code.
 type Lexer struct {
-	input        string // The input string
-	position     int    // Current position in input (points to current char)
-	readPosition int    // Next reading position (after current char)
-	ch           byte   // Current char under examination
+	input *bufio.Reader     // The input, read one byte at a time
+	src   strings.Reader    // The input of lexers created by New
+	err   error             // The first read error other than io.EOF
+	ch    byte              // Current char under examination
+	pos   token.Position    // Position of ch
+	next  token.Position    // Position of the byte after ch
+	buf   []byte            // Bytes of the token being read
+	names map[string]string // Identifiers read so far, see intern
 }
 
 // New creates a new Lexer for the given input string.
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{}
+	l.Reset(input)
+	return l
+}
+
+// Reset makes l read input from the start as if it were new, reusing its
+// buffers, so that a pool of lexers can serve many queries.
+func (l *Lexer) Reset(input string) {
+	l.src.Reset(input)
+	// Short queries do not need the default 4KB buffer.
+	if size := min(max(len(input), 16), 4096); l.input == nil || l.input.Size() < size {
+		l.input = bufio.NewReaderSize(&l.src, size)
+	} else {
+		l.input.Reset(&l.src)
+	}
+	if len(l.names) > maxNames {
+		l.names = nil
+	}
+	l.err, l.pos, l.next = nil, token.Position{}, token.Position{Line: 1, Column: 1}
+	l.readChar()
+}
+
+// NewFromReader creates a new Lexer that reads its input from r as it
+// goes, so large schema files need not be loaded into memory first. Read
+// errors end the token stream and are reported by Err.
+func NewFromReader(r io.Reader) *Lexer {
+	l := &Lexer{input: bufio.NewReader(r), next: token.Position{Line: 1, Column: 1}}
 	l.readChar()
 	return l
 }
 
+// Err returns the first error encountered reading the input, if any.
+func (l *Lexer) Err() error {
+	return l.err
+}
+
 // readChar advances the lexer to the next character.
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
+	l.pos = l.next
+	ch, err := l.input.ReadByte()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
 		l.ch = 0 // ASCII 0 signifies end-of-input
-	} else {
-		l.ch = l.input[l.readPosition]
+		return
+	}
+	l.ch = ch
+	l.next.Offset++
+	switch {
+	case ch == '\n':
+		l.next.Line++
+		l.next.Column = 1
+	case ch&0xC0 != 0x80: // not a UTF-8 continuation byte
+		l.next.Column++
+	}
+}
+
+// peekChar returns the next character without advancing the lexer.
+func (l *Lexer) peekChar() byte {
+	return l.peekCharAt(0)
+}
+
+// peekCharAt returns the character offset positions after the next one
+// without advancing the lexer.
+func (l *Lexer) peekCharAt(offset int) byte {
+	b, err := l.input.Peek(offset + 1)
+	if err != nil {
+		return 0
 	}
-	l.position = l.readPosition
-	l.readPosition++
+	return b[offset]
+}
+
+// Tokenize returns the remaining tokens of the input, ending with EOF. The
+// error reports the first illegal character or read error; the tokens are
+// returned either way so tools can continue past problems.
+func (l *Lexer) Tokenize() ([]token.Token, error) {
+	var tokens []token.Token
+	var err error
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.ILLEGAL && err == nil {
+			err = fmt.Errorf("illegal character %q", tok.Literal)
+		}
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	if l.err != nil {
+		return tokens, l.err
+	}
+	return tokens, err
 }
 
 // NextToken returns the next token from the input.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
+	start := l.pos
+	tok := l.readToken()
+	tok.Pos, tok.End = start, l.pos
+	return tok
+}
+
+// readToken reads the token starting at the current character.
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
 	switch l.ch {
 	case '=':
 		tok = token.Token{Type: token.ASSIGN, Literal: string(l.ch)}
@@ -60,12 +154,30 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.Token{Type: token.RBRACKET, Literal: string(l.ch)}
 	case '"':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		if l.peekChar() == '"' && l.peekCharAt(1) == '"' {
+			tok.Literal = l.readBlockString()
+		} else {
+			tok.Literal = l.readString()
+		}
 		return tok
 	case '$':
 		tok = token.Token{Type: token.DOLLAR, Literal: string(l.ch)}
 	case '!':
 		tok = token.Token{Type: token.BANG, Literal: string(l.ch)}
+	case '@':
+		tok = token.Token{Type: token.AT, Literal: string(l.ch)}
+	case '|':
+		tok = token.Token{Type: token.PIPE, Literal: string(l.ch)}
+	case '&':
+		tok = token.Token{Type: token.AMP, Literal: string(l.ch)}
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.SPREAD, Literal: "..."}
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: string(l.ch)}
+		}
 	case 0:
 		tok = token.Token{Type: token.EOF, Literal: ""}
 	default:
@@ -73,10 +185,8 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.IDENT
 			return tok
-		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
-			return tok
+		} else if isDigit(l.ch) || (l.ch == '-' && isDigit(l.peekChar())) {
+			return l.readNumber()
 		} else {
 			tok = token.Token{Type: token.ILLEGAL, Literal: string(l.ch)}
 		}
@@ -94,34 +204,169 @@ func (l *Lexer) skipWhitespace() {
 
 // readIdentifier reads an identifier from the input.
 func (l *Lexer) readIdentifier() string {
-	start := l.position
+	l.buf = l.buf[:0]
 	for isLetter(l.ch) || isDigit(l.ch) {
+		l.buf = append(l.buf, l.ch)
 		l.readChar()
 	}
-	return l.input[start:l.position]
+	return l.intern(l.buf)
 }
 
-// readNumber reads a number from the input.
-func (l *Lexer) readNumber() string {
-	start := l.position
+// maxNames bounds the identifiers a lexer remembers across Reset.
+const maxNames = 1024
+
+// commonNames are the identifiers most documents use: keywords, built-in
+// types and directives, and the introspection fields.
+var commonNames = make(map[string]string)
+
+func init() {
+	for _, name := range []string{
+		"query", "mutation", "subscription", "fragment", "on", "true", "false", "null",
+		"schema", "scalar", "type", "interface", "union", "enum", "input", "extend",
+		"directive", "implements", "repeatable",
+		"Int", "Float", "String", "Boolean", "ID", "Query", "Mutation", "Subscription",
+		"skip", "include", "deprecated", "specifiedBy", "if", "reason",
+		"id", "name", "description", "kind", "fields", "args", "__typename",
+		"__schema", "__type", "ofType", "types", "interfaces", "possibleTypes",
+		"enumValues", "inputFields", "defaultValue", "isDeprecated", "deprecationReason",
+		"queryType", "mutationType", "subscriptionType", "directives", "locations",
+	} {
+		commonNames[name] = name
+	}
+}
+
+// intern returns b as a string, sharing the string of an identifier read
+// before so that repeated names are allocated once.
+func (l *Lexer) intern(b []byte) string {
+	if s, ok := commonNames[string(b)]; ok {
+		return s
+	}
+	if s, ok := l.names[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if l.names == nil {
+		l.names = make(map[string]string)
+	}
+	l.names[s] = s
+	return s
+}
+
+// readNumber reads an integer or float literal, with an optional leading
+// minus sign, fractional part and exponent, from the input.
+func (l *Lexer) readNumber() token.Token {
+	l.buf = l.buf[:0]
+	tok := token.Token{Type: token.INT}
+	if l.ch == '-' {
+		l.buf = append(l.buf, l.ch)
+		l.readChar()
+	}
+	l.readDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tok.Type = token.FLOAT
+		l.buf = append(l.buf, l.ch)
+		l.readChar()
+		l.readDigits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || ((next == '+' || next == '-') && isDigit(l.peekCharAt(1))) {
+			tok.Type = token.FLOAT
+			l.buf = append(l.buf, l.ch)
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.buf = append(l.buf, l.ch)
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+	tok.Literal = string(l.buf)
+	return tok
+}
+
+// readDigits appends a run of decimal digits to the token being read.
+func (l *Lexer) readDigits() {
 	for isDigit(l.ch) {
+		l.buf = append(l.buf, l.ch)
 		l.readChar()
 	}
-	return l.input[start:l.position]
 }
 
 // readString reads a string literal from the input.
 func (l *Lexer) readString() string {
 	// skip opening quote
 	l.readChar()
-	start := l.position
+	l.buf = l.buf[:0]
 	for l.ch != '"' && l.ch != 0 {
+		l.buf = append(l.buf, l.ch)
 		l.readChar()
 	}
-	str := l.input[start:l.position]
 	// skip closing quote
 	l.readChar()
-	return str
+	return string(l.buf)
+}
+
+// readBlockString reads a triple-quoted block string from the input and
+// returns its value: common indentation and leading and trailing blank
+// lines are removed, and \""" stands for """.
+func (l *Lexer) readBlockString() string {
+	// skip opening quotes
+	l.readChar()
+	l.readChar()
+	l.readChar()
+	var sb strings.Builder
+	for l.ch != 0 {
+		if l.ch == '"' && l.peekChar() == '"' && l.peekCharAt(1) == '"' {
+			// skip closing quotes
+			l.readChar()
+			l.readChar()
+			l.readChar()
+			break
+		}
+		if l.ch == '\\' && l.peekChar() == '"' && l.peekCharAt(1) == '"' && l.peekCharAt(2) == '"' {
+			sb.WriteString(`"""`)
+			for i := 0; i < 4; i++ {
+				l.readChar()
+			}
+			continue
+		}
+		sb.WriteByte(l.ch)
+		l.readChar()
+	}
+	return blockStringValue(sb.String())
+}
+
+// blockStringValue removes the indentation and blank lines of a raw block
+// string as the GraphQL specification describes.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
 }
 
 // isLetter checks if a byte is a letter or underscore.
@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/Protocol-Lattice/graphql/token"
 )
@@ -65,15 +69,15 @@ func TestLexer_Strings(t *testing.T) {
 }
 
 func TestLexer_IllegalCharacter(t *testing.T) {
-	input := "@"
+	input := "%"
 	lexer := New(input)
 
 	tok := lexer.NextToken()
 	if tok.Type != token.ILLEGAL {
 		t.Fatalf("expected token type ILLEGAL, got %s", tok.Type)
 	}
-	if tok.Literal != "@" {
-		t.Errorf("expected literal '@', got %q", tok.Literal)
+	if tok.Literal != "%" {
+		t.Errorf("expected literal '%%', got %q", tok.Literal)
 	}
 
 	tok = lexer.NextToken()
@@ -81,3 +85,147 @@ func TestLexer_IllegalCharacter(t *testing.T) {
 		t.Errorf("expected token type EOF, got %s", tok.Type)
 	}
 }
+
+func TestLexer_Punctuators(t *testing.T) {
+	input := `...UserFields @include | & .. .`
+	expected := []struct {
+		typ     token.TokenType
+		literal string
+	}{
+		{token.SPREAD, "..."},
+		{token.IDENT, "UserFields"},
+		{token.AT, "@"},
+		{token.IDENT, "include"},
+		{token.PIPE, "|"},
+		{token.AMP, "&"},
+		{token.ILLEGAL, "."},
+		{token.ILLEGAL, "."},
+		{token.ILLEGAL, "."},
+		{token.EOF, ""},
+	}
+	lexer := New(input)
+	for i, want := range expected {
+		tok := lexer.NextToken()
+		if tok.Type != want.typ || tok.Literal != want.literal {
+			t.Fatalf("token %d: expected %s %q, got %s %q", i, want.typ, want.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestTokenizeFromReader(t *testing.T) {
+	lexer := NewFromReader(strings.NewReader(`type Query { hello: String! }`))
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.Literal)
+	}
+	want := []string{"type", "Query", "{", "hello", ":", "String", "!", "}", ""}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if last := tokens[len(tokens)-1]; last.Type != token.EOF {
+		t.Errorf("expected stream to end with EOF, got %s", last.Type)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	if _, err := New(`{ a % b }`).Tokenize(); err == nil || !strings.Contains(err.Error(), `"%"`) {
+		t.Errorf("expected illegal character error, got %v", err)
+	}
+	failing := io.MultiReader(strings.NewReader("{ a"), iotest.ErrReader(errors.New("disk on fire")))
+	tokens, err := NewFromReader(failing).Tokenize()
+	if err == nil || err.Error() != "disk on fire" {
+		t.Errorf("expected read error, got %v", err)
+	}
+	if len(tokens) != 3 || tokens[1].Literal != "a" {
+		t.Errorf("expected tokens read before the error, got %v", tokens)
+	}
+}
+
+func TestLexer_Floats(t *testing.T) {
+	tests := []struct {
+		input string
+		want  token.Token
+	}{
+		{"3.14", token.Token{Type: token.FLOAT, Literal: "3.14"}},
+		{"-0.5", token.Token{Type: token.FLOAT, Literal: "-0.5"}},
+		{"1e10", token.Token{Type: token.FLOAT, Literal: "1e10"}},
+		{"6.02E+23", token.Token{Type: token.FLOAT, Literal: "6.02E+23"}},
+		{"-42", token.Token{Type: token.INT, Literal: "-42"}},
+	}
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.want.Type || tok.Literal != tt.want.Literal {
+			t.Errorf("%s: expected %+v, got %+v", tt.input, tt.want, tok)
+		}
+	}
+}
+
+func TestLexer_BlockStrings(t *testing.T) {
+	input := "\"\"\"\n    The root type.\n\n      Indented \\\"\"\" line.\n  \"\"\" \"plain\""
+	lexer := New(input)
+
+	tok := lexer.NextToken()
+	if tok.Type != token.STRING {
+		t.Fatalf("expected token type STRING, got %s", tok.Type)
+	}
+	if want := "The root type.\n\n  Indented \"\"\" line."; tok.Literal != want {
+		t.Errorf("expected literal %q, got %q", want, tok.Literal)
+	}
+
+	tok = lexer.NextToken()
+	if tok.Type != token.STRING || tok.Literal != "plain" {
+		t.Errorf("expected STRING \"plain\", got %s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestLexer_Positions(t *testing.T) {
+	lexer := New("{\n  hello(s: \"é\")\n}")
+	tests := []struct {
+		literal    string
+		start, end string
+		offset     int
+	}{
+		{"{", "1:1", "1:2", 0},
+		{"hello", "2:3", "2:8", 4},
+		{"(", "2:8", "2:9", 9},
+		{"s", "2:9", "2:10", 10},
+		{":", "2:10", "2:11", 11},
+		{"é", "2:12", "2:15", 13},
+		{")", "2:15", "2:16", 17},
+		{"}", "3:1", "3:2", 19},
+		{"", "3:2", "3:2", 20},
+	}
+	for _, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Literal != tt.literal || tok.Pos.String() != tt.start || tok.End.String() != tt.end || tok.Pos.Offset != tt.offset {
+			t.Errorf("expected %q at %s-%s (offset %d), got %q at %s-%s (offset %d)",
+				tt.literal, tt.start, tt.end, tt.offset, tok.Literal, tok.Pos, tok.End, tok.Pos.Offset)
+		}
+	}
+}
+
+func TestLexer_Reset(t *testing.T) {
+	lexer := New(`{ user(id: 12345678901234567890) { name } }`)
+	if _, err := lexer.Tokenize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lexer.Reset("{\n  user { name }\n}")
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var literals []string
+	for _, tok := range tokens {
+		literals = append(literals, tok.Literal)
+	}
+	if got := strings.Join(literals, " "); got != "{ user { name } } " {
+		t.Errorf("unexpected tokens after Reset: %q", got)
+	}
+	if pos := tokens[1].Pos.String(); pos != "2:3" {
+		t.Errorf("expected user at 2:3 after Reset, got %s", pos)
+	}
+}
@@ -0,0 +1,218 @@
+// Package otel traces GraphQL requests with OpenTelemetry. Its Tracer is an
+// executor.Tracer creating spans for parsing, validation, execution and
+// resolvers, and its Middleware creates the span of the HTTP request that
+// they belong to:
+//
+//	tracer := otel.New(otel.Config{})
+//	exec.SetTracer(tracer)
+//	http.Handle("/graphql", tracer.Middleware(handler.New(handler.Config{Executor: exec})))
+//
+// Resolvers receive the context of their field's span, so the spans of the
+// calls they make downstream are its children.
+package otel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/executor"
+)
+
+// instrumentationName names the tracer that creates the spans.
+const instrumentationName = "github.com/Protocol-Lattice/graphql/otel"
+
+// Attributes of field spans, named like those of the OpenTelemetry GraphQL
+// instrumentations of other languages.
+const (
+	FieldNameKey       = attribute.Key("graphql.field.name")
+	FieldAliasKey      = attribute.Key("graphql.field.alias")
+	FieldPathKey       = attribute.Key("graphql.field.path")
+	FieldParentTypeKey = attribute.Key("graphql.field.parent_type")
+	FieldTypeKey       = attribute.Key("graphql.field.type")
+)
+
+// Config configures a Tracer.
+type Config struct {
+	// TracerProvider creates the tracer of the spans. It defaults to the
+	// global provider.
+	TracerProvider trace.TracerProvider
+	// Propagator extracts the trace context of incoming requests in
+	// Middleware. It defaults to the global propagator.
+	Propagator propagation.TextMapPropagator
+	// Fields reports whether to create a span for a field. It defaults to
+	// creating spans for all fields; operations selecting many fields may
+	// want to limit them, such as to fields with their own resolvers.
+	Fields func(info *executor.ResolveInfo) bool
+}
+
+// Tracer is an executor.Tracer creating OpenTelemetry spans. It is safe for
+// concurrent use.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	fields     func(info *executor.ResolveInfo) bool
+}
+
+// New creates a Tracer as cfg describes.
+func New(cfg Config) *Tracer {
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.Propagator == nil {
+		cfg.Propagator = otel.GetTextMapPropagator()
+	}
+	return &Tracer{
+		tracer:     cfg.TracerProvider.Tracer(instrumentationName),
+		propagator: cfg.Propagator,
+		fields:     cfg.Fields,
+	}
+}
+
+// StartParse starts a "graphql.parse" span.
+func (t *Tracer) StartParse(ctx context.Context) (context.Context, executor.FinishFunc) {
+	return t.start(ctx, "graphql.parse")
+}
+
+// StartValidate starts a "graphql.validate" span.
+func (t *Tracer) StartValidate(ctx context.Context) (context.Context, executor.FinishFunc) {
+	return t.start(ctx, "graphql.validate")
+}
+
+// StartExecute starts a span named after the operation, such as
+// "query GetUser", with its type and name as attributes.
+func (t *Tracer) StartExecute(ctx context.Context, op *ast.OperationDefinition) (context.Context, executor.FinishFunc) {
+	opType := op.Operation
+	if opType == "" {
+		opType = "query"
+	}
+	name := opType
+	attrs := []attribute.KeyValue{semconv.GraphqlOperationTypeKey.String(opType)}
+	if op.Name != "" {
+		name += " " + op.Name
+		attrs = append(attrs, semconv.GraphqlOperationName(op.Name))
+	}
+	return t.start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartField starts a span named after the field, such as "User.name",
+// unless Config.Fields excludes the field.
+func (t *Tracer) StartField(ctx context.Context, info *executor.ResolveInfo) (context.Context, executor.FinishFunc) {
+	if t.fields != nil && !t.fields(info) {
+		return ctx, func(time.Duration, error) {}
+	}
+	attrs := []attribute.KeyValue{
+		FieldNameKey.String(info.FieldName),
+		FieldPathKey.String(formatPath(info.Path)),
+	}
+	if info.Alias != "" {
+		attrs = append(attrs, FieldAliasKey.String(info.Alias))
+	}
+	name := info.FieldName
+	if info.ParentType != "" {
+		name = info.ParentType + "." + name
+		attrs = append(attrs, FieldParentTypeKey.String(info.ParentType))
+	}
+	if info.ReturnType != nil {
+		attrs = append(attrs, FieldTypeKey.String(info.ReturnType.String()))
+	}
+	return t.start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// start starts a span and returns a FinishFunc ending it, recording the
+// error it finishes with.
+func (t *Tracer) start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, executor.FinishFunc) {
+	ctx, span := t.tracer.Start(ctx, name, opts...)
+	return ctx, func(_ time.Duration, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Middleware returns a handler serving requests with next within a server
+// span, such as "POST /graphql", continuing the trace of the incoming
+// request's headers.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.ServerAddress(r.Host),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(sw.status))
+		}
+	})
+}
+
+// statusWriter passes a response through while keeping its status. It
+// hijacks and flushes the underlying response, so Middleware may wrap
+// subscription endpoints and streamed responses.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets subscriptions take over the connection.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush sends buffered data to the client, if the response can.
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// formatPath renders a response path as "user.friends.0.name".
+func formatPath(path []interface{}) string {
+	var sb strings.Builder
+	for i, seg := range path {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		switch s := seg.(type) {
+		case string:
+			sb.WriteString(s)
+		case int:
+			sb.WriteString(strconv.Itoa(s))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Protocol-Lattice/graphql/executor"
+	"github.com/Protocol-Lattice/graphql/handler"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := New(Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Propagator:     propagation.TraceContext{},
+	})
+	exec := executor.New()
+	var resolverSpan trace.SpanContext
+	exec.RegisterQueryResolverContext("user", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		resolverSpan = trace.SpanContextFromContext(ctx)
+		return map[string]interface{}{"name": "Ada"}, nil
+	})
+	h := tracer.Middleware(handler.New(handler.Config{Executor: exec, Tracer: tracer}))
+
+	r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "query GetUser { user { name } }"}`))
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"POST /graphql", "graphql.parse", "query GetUser", "Query.user"} {
+		if _, ok := spans[name]; !ok {
+			t.Errorf("expected a %q span, got %v", name, recorder.Ended())
+		}
+	}
+	root := spans["POST /graphql"]
+	if root == nil {
+		t.FailNow()
+	}
+	if got := root.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the incoming trace to continue, got trace %s", got)
+	}
+	op, field := spans["query GetUser"], spans["Query.user"]
+	if op.Parent().SpanID() != root.SpanContext().SpanID() || field.Parent().SpanID() != op.SpanContext().SpanID() {
+		t.Errorf("expected request > operation > field spans")
+	}
+	if !hasAttribute(op, attribute.String("graphql.operation.name", "GetUser")) || !hasAttribute(op, attribute.String("graphql.operation.type", "query")) {
+		t.Errorf("unexpected operation attributes %v", op.Attributes())
+	}
+	if !hasAttribute(field, FieldPathKey.String("user")) {
+		t.Errorf("unexpected field attributes %v", field.Attributes())
+	}
+	if resolverSpan.SpanID() != field.SpanContext().SpanID() {
+		t.Errorf("expected the resolver to run within its field's span")
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, want attribute.KeyValue) bool {
+	for _, kv := range span.Attributes() {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMiddlewareHijacks(t *testing.T) {
+	tracer := New(Config{TracerProvider: sdktrace.NewTracerProvider()})
+	srv := httptest.NewServer(tracer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("expected the response to hijack, got %v", err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n")
+		buf.Flush()
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected 101, got %d", resp.StatusCode)
+	}
+}
@@ -1,42 +1,116 @@
 package parser
 
 import (
+	"fmt"
+
 	"github.com/Protocol-Lattice/graphql/ast"
 	"github.com/Protocol-Lattice/graphql/lexer"
 	"github.com/Protocol-Lattice/graphql/token"
 )
 
+// DefaultMaxDepth is the default maximum nesting depth of a document.
+const DefaultMaxDepth = 100
+
+// maxDepth is the maximum nesting depth of parsers created by New.
+var maxDepth = DefaultMaxDepth
+
+// SetMaxDepth sets the maximum nesting depth of parsers created afterwards.
+// Selection sets, values and list types each add a level;
+// a value of zero or less disables the limit.
+func SetMaxDepth(n int) {
+	maxDepth = n
+}
+
 // Parser parses GraphQL source code into an AST.
 type Parser struct {
-	l         *lexer.Lexer // The lexer to read tokens from
-	curToken  token.Token  // Current token
-	peekToken token.Token  // Next token
+	l         *lexer.Lexer   // The lexer to read tokens from
+	errors    []string       // Syntax errors encountered while parsing
+	curToken  token.Token    // Current token
+	peekToken token.Token    // Next token
+	prevEnd   token.Position // End of the last consumed token
+	depth     int            // Current nesting depth
+	maxDepth  int            // Maximum nesting depth, or <= 0 for none
+	aborted   bool           // Whether parsing stopped at the depth limit
 }
 
 // New creates a new Parser for the given lexer.
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l}
+	p := &Parser{l: l, maxDepth: maxDepth}
 	// Initialize two tokens
 	p.nextToken()
 	p.nextToken()
 	return p
 }
 
+// Errors returns the syntax errors encountered while parsing.
+// The document returned by ParseDocument is best-effort when this is non-empty.
+func (p *Parser) Errors() []string {
+	return p.errors
+}
+
+// SetMaxDepth sets the maximum nesting depth of the document p parses,
+// overriding the package default. A value of zero or less disables the
+// limit.
+func (p *Parser) SetMaxDepth(n int) {
+	p.maxDepth = n
+}
+
+// errorf records a syntax error. Errors after an abort are dropped since
+// they only describe the truncated input.
+func (p *Parser) errorf(format string, args ...interface{}) {
+	if p.aborted {
+		return
+	}
+	p.errors = append(p.errors, fmt.Sprintf(format, args...))
+}
+
+// enter descends one nesting level. If that exceeds the maximum depth it
+// records an error, discards the rest of the input and reports false.
+// Every call must be paired with a call to leave.
+func (p *Parser) enter() bool {
+	p.depth++
+	if p.maxDepth <= 0 || p.depth <= p.maxDepth {
+		return true
+	}
+	if !p.aborted {
+		p.errorf("maximum nesting depth of %d exceeded", p.maxDepth)
+		p.aborted = true
+	}
+	for p.curToken.Type != token.EOF {
+		p.nextToken()
+	}
+	return false
+}
+
+// leave ascends one nesting level.
+func (p *Parser) leave() {
+	p.depth--
+}
+
 // nextToken advances the parser to the next token.
 func (p *Parser) nextToken() {
+	p.prevEnd = p.curToken.End
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
 }
 
+// span returns the source span from start to the end of the last consumed
+// token.
+func (p *Parser) span(start token.Position) ast.Loc {
+	return ast.Loc{Start: start, End: p.prevEnd}
+}
+
 // ParseDocument parses a GraphQL document.
 func (p *Parser) ParseDocument() *ast.Document {
 	doc := &ast.Document{}
+	start := p.curToken.Pos
 	for p.curToken.Type != token.EOF {
 		def := p.parseDefinition()
 		if def != nil {
 			doc.Definitions = append(doc.Definitions, def)
 		}
 	}
+	doc.Loc = ast.Loc{Start: start, End: p.curToken.End}
 	return doc
 }
 
@@ -52,18 +126,211 @@ func (p *Parser) parseDefinition() ast.Definition {
 	if p.curToken.Type == token.LBRACE {
 		return p.parseOperationDefinition()
 	}
-	// Handle type definitions
-	if p.curToken.Literal == "type" {
-		return p.skipTypeDefinition()
+	// Handle fragment definitions
+	if p.curToken.Literal == "fragment" {
+		return p.parseFragmentDefinition()
+	}
+	// Handle type system definitions, which may have a description
+	if p.curToken.Type == token.STRING {
+		return p.parseDescribedDefinition()
+	}
+	if def, ok := p.parseTypeSystemDefinition(); ok {
+		return def
+	}
+	if p.curToken.Literal == "extend" {
+		return p.parseTypeExtension()
+	}
+	if p.curToken.Literal == "directive" {
+		return p.parseDirectiveDefinition()
+	}
+	if p.curToken.Literal == "schema" {
+		return p.parseSchemaDefinition()
 	}
 	// Unknown definition, skip it
-	p.nextToken()
+	p.errorf("unexpected %q at start of definition", p.curToken.Literal)
+	p.skipDefinition()
 	return nil
 }
 
+// parseDescribedDefinition parses a type system definition preceded by a
+// description.
+func (p *Parser) parseDescribedDefinition() ast.Definition {
+	start := p.curToken.Pos
+	description := p.parseDescription()
+	var def ast.Definition
+	switch p.curToken.Literal {
+	case "directive":
+		def = p.parseDirectiveDefinition()
+	case "schema":
+		def = p.parseSchemaDefinition()
+	default:
+		var ok bool
+		if def, ok = p.parseTypeSystemDefinition(); !ok {
+			p.errorf("unexpected %q after description", p.curToken.Literal)
+			p.skipDefinition()
+			return nil
+		}
+	}
+	switch def := def.(type) {
+	case *ast.TypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.InterfaceTypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.UnionTypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.EnumTypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.InputObjectTypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.ScalarTypeDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.DirectiveDefinition:
+		def.Description = description
+		def.Start = start
+	case *ast.SchemaDefinition:
+		def.Description = description
+		def.Start = start
+	}
+	return def
+}
+
+// parseDescription consumes a description string if there is one and
+// returns its value, or "" otherwise.
+func (p *Parser) parseDescription() string {
+	if p.curToken.Type != token.STRING {
+		return ""
+	}
+	description := p.curToken.Literal
+	p.nextToken()
+	return description
+}
+
+// atInputValueDefinition reports whether the current token starts an
+// argument or input field definition, possibly with a description.
+func (p *Parser) atInputValueDefinition() bool {
+	return p.curToken.Type == token.IDENT || p.curToken.Type == token.STRING && p.peekToken.Type == token.IDENT
+}
+
+// parseTypeSystemDefinition parses a named type definition if the current
+// token starts one, reporting whether it did.
+func (p *Parser) parseTypeSystemDefinition() (ast.Definition, bool) {
+	start := p.curToken.Pos
+	var def ast.Definition
+	switch p.curToken.Literal {
+	case "type":
+		def = p.parseObjectTypeDefinition()
+	case "input":
+		def = p.parseInputObjectTypeDefinition()
+	case "interface":
+		def = p.parseInterfaceTypeDefinition()
+	case "enum":
+		def = p.parseEnumTypeDefinition()
+	case "union":
+		def = p.parseUnionTypeDefinition()
+	case "scalar":
+		def = p.parseScalarTypeDefinition()
+	default:
+		return nil, false
+	}
+	loc := p.span(start)
+	switch def := def.(type) {
+	case *ast.TypeDefinition:
+		def.Loc = loc
+	case *ast.InputObjectTypeDefinition:
+		def.Loc = loc
+	case *ast.InterfaceTypeDefinition:
+		def.Loc = loc
+	case *ast.EnumTypeDefinition:
+		def.Loc = loc
+	case *ast.UnionTypeDefinition:
+		def.Loc = loc
+	case *ast.ScalarTypeDefinition:
+		def.Loc = loc
+	}
+	return def, true
+}
+
+// parseTypeExtension parses a type extension (e.g., "extend type Query { ... }").
+func (p *Parser) parseTypeExtension() ast.Definition {
+	start := p.curToken.Pos
+	p.nextToken() // Skip "extend"
+	def, ok := p.parseTypeSystemDefinition()
+	if !ok {
+		p.errorf("unexpected %q after extend", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	if def == nil {
+		return nil
+	}
+	return &ast.TypeExtension{Loc: p.span(start), Definition: def}
+}
+
+// skipDefinition skips tokens up to and including the end of the current
+// definition's first brace-delimited block (or EOF).
+func (p *Parser) skipDefinition() {
+	depth := 0
+	for p.curToken.Type != token.EOF {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+			if depth <= 0 {
+				p.nextToken()
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// parseFragmentDefinition parses a fragment definition
+// (e.g., "fragment UserFields on User { ... }").
+func (p *Parser) parseFragmentDefinition() ast.Definition {
+	start := p.curToken.Pos
+	p.nextToken() // Skip "fragment"
+	if p.curToken.Type != token.IDENT || p.curToken.Literal == "on" {
+		p.errorf("expected fragment name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	frag := &ast.FragmentDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Literal != "on" {
+		p.errorf("expected \"on\" after fragment name %q, got %q", frag.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip "on"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected type condition for fragment %q, got %q", frag.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	frag.TypeCondition = p.curToken.Literal
+	p.nextToken()
+	frag.Directives = p.parseDirectives()
+	if p.curToken.Type != token.LBRACE {
+		p.errorf("expected selection set for fragment %q, got %q", frag.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	frag.SelectionSet = p.parseSelectionSet()
+	frag.Loc = p.span(start)
+	return frag
+}
+
 // parseOperationDefinition parses a query, mutation, or subscription operation.
 func (p *Parser) parseOperationDefinition() *ast.OperationDefinition {
 	op := &ast.OperationDefinition{}
+	start := p.curToken.Pos
 	if p.curToken.Literal == "query" ||
 		p.curToken.Literal == "mutation" ||
 		p.curToken.Literal == "subscription" {
@@ -76,12 +343,14 @@ func (p *Parser) parseOperationDefinition() *ast.OperationDefinition {
 		if p.curToken.Type == token.LPAREN {
 			op.VariableDefinitions = p.parseVariableDefinitions()
 		}
+		op.Directives = p.parseDirectives()
 	} else {
 		op.Operation = "query"
 	}
 	if p.curToken.Type == token.LBRACE {
 		op.SelectionSet = p.parseSelectionSet()
 	}
+	op.Loc = p.span(start)
 	return op
 }
 
@@ -91,6 +360,7 @@ func (p *Parser) parseVariableDefinitions() []ast.VariableDefinition {
 	p.nextToken() // Skip '('
 	for p.curToken.Type != token.RPAREN && p.curToken.Type != token.EOF {
 		if p.curToken.Type == token.DOLLAR {
+			start := p.curToken.Pos
 			p.nextToken() // Skip '$'
 			if p.curToken.Type != token.IDENT {
 				return vars
@@ -105,7 +375,15 @@ func (p *Parser) parseVariableDefinitions() []ast.VariableDefinition {
 					varDef.Type = *typeParsed
 				}
 			}
+			if p.curToken.Type == token.ASSIGN {
+				p.nextToken()
+				varDef.DefaultValue = p.parseValue()
+			}
+			varDef.Loc = p.span(start)
 			vars = append(vars, varDef)
+		} else if p.curToken.Type != token.COMMA {
+			p.errorf("unexpected %q in variable definitions", p.curToken.Literal)
+			p.nextToken()
 		}
 		if p.curToken.Type == token.COMMA {
 			p.nextToken()
@@ -118,23 +396,79 @@ func (p *Parser) parseVariableDefinitions() []ast.VariableDefinition {
 // parseSelectionSet parses a selection set (fields within braces).
 func (p *Parser) parseSelectionSet() *ast.SelectionSet {
 	ss := &ast.SelectionSet{}
+	ss.Start = p.curToken.Pos
+	defer p.leave()
+	if !p.enter() {
+		return ss
+	}
 	p.nextToken() // skip '{'
 	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
 		sel := p.parseSelection()
 		if sel != nil {
 			ss.Selections = append(ss.Selections, sel)
+		} else {
+			p.errorf("unexpected %q in selection set", p.curToken.Literal)
+			p.nextToken()
 		}
 		if p.curToken.Type == token.COMMA {
 			p.nextToken()
 		}
 	}
 	p.nextToken() // skip '}'
+	ss.End = p.prevEnd
 	return ss
 }
 
-// parseSelection parses a single selection (currently only fields).
+// parseSelection parses a single selection (a field, fragment spread or
+// inline fragment).
 func (p *Parser) parseSelection() ast.Selection {
-	return p.parseField()
+	if p.curToken.Type == token.SPREAD {
+		if p.peekToken.Type == token.LBRACE || p.peekToken.Type == token.AT || p.peekToken.Literal == "on" {
+			return p.parseInlineFragment()
+		}
+		return p.parseFragmentSpread()
+	}
+	if field := p.parseField(); field != nil {
+		return field
+	}
+	return nil
+}
+
+// parseFragmentSpread parses a named fragment spread (e.g., "...UserFields").
+func (p *Parser) parseFragmentSpread() ast.Selection {
+	start := p.curToken.Pos
+	p.nextToken() // Skip '...'
+	if p.curToken.Type != token.IDENT || p.curToken.Literal == "on" {
+		return nil
+	}
+	spread := &ast.FragmentSpread{Name: p.curToken.Literal}
+	p.nextToken()
+	spread.Directives = p.parseDirectives()
+	spread.Loc = p.span(start)
+	return spread
+}
+
+// parseInlineFragment parses an inline fragment with an optional type
+// condition (e.g., "... on Admin { permissions }").
+func (p *Parser) parseInlineFragment() ast.Selection {
+	start := p.curToken.Pos
+	p.nextToken() // Skip '...'
+	inline := &ast.InlineFragment{}
+	if p.curToken.Literal == "on" {
+		p.nextToken()
+		if p.curToken.Type != token.IDENT {
+			return nil
+		}
+		inline.TypeCondition = p.curToken.Literal
+		p.nextToken()
+	}
+	inline.Directives = p.parseDirectives()
+	if p.curToken.Type != token.LBRACE {
+		return nil
+	}
+	inline.SelectionSet = p.parseSelectionSet()
+	inline.Loc = p.span(start)
+	return inline
 }
 
 // parseField parses a field selection.
@@ -143,14 +477,26 @@ func (p *Parser) parseField() *ast.Field {
 	if p.curToken.Type != token.IDENT {
 		return nil
 	}
+	start := p.curToken.Pos
 	field.Name = p.curToken.Literal
 	p.nextToken()
+	if p.curToken.Type == token.COLON {
+		p.nextToken()
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected field name after alias %q, got %q", field.Name, p.curToken.Literal)
+			return field
+		}
+		field.Alias, field.Name = field.Name, p.curToken.Literal
+		p.nextToken()
+	}
 	if p.curToken.Type == token.LPAREN {
 		field.Arguments = p.parseArguments()
 	}
+	field.Directives = p.parseDirectives()
 	if p.curToken.Type == token.LBRACE {
 		field.SelectionSet = p.parseSelectionSet()
 	}
+	field.Loc = p.span(start)
 	return field
 }
 
@@ -161,13 +507,18 @@ func (p *Parser) parseArguments() []ast.Argument {
 	for p.curToken.Type != token.RPAREN && p.curToken.Type != token.EOF {
 		arg := ast.Argument{}
 		if p.curToken.Type == token.IDENT {
+			start := p.curToken.Pos
 			arg.Name = p.curToken.Literal
 			p.nextToken()
 			if p.curToken.Type == token.COLON {
 				p.nextToken()
 				arg.Value = p.parseValue()
 			}
+			arg.Loc = p.span(start)
 			args = append(args, arg)
+		} else if p.curToken.Type != token.COMMA {
+			p.errorf("unexpected %q in arguments", p.curToken.Literal)
+			p.nextToken()
 		}
 		if p.curToken.Type == token.COMMA {
 			p.nextToken()
@@ -179,6 +530,18 @@ func (p *Parser) parseArguments() []ast.Argument {
 
 // parseValue parses a value (string, int, boolean, variable, object, array).
 func (p *Parser) parseValue() *ast.Value {
+	start := p.curToken.Pos
+	val := p.parseValueLiteral()
+	val.Loc = p.span(start)
+	return val
+}
+
+// parseValueLiteral parses a value without recording its location.
+func (p *Parser) parseValueLiteral() *ast.Value {
+	defer p.leave()
+	if !p.enter() {
+		return &ast.Value{Kind: "Illegal", Literal: "maximum nesting depth exceeded"}
+	}
 	// Handle object literals
 	if p.curToken.Type == token.LBRACE {
 		return p.parseObject()
@@ -194,15 +557,22 @@ func (p *Parser) parseValue() *ast.Value {
 		val.Kind = "Int"
 		val.Literal = p.curToken.Literal
 		p.nextToken()
+	case token.FLOAT:
+		val.Kind = "Float"
+		val.Literal = p.curToken.Literal
+		p.nextToken()
 	case token.STRING:
 		val.Kind = "String"
 		val.Literal = p.curToken.Literal
 		p.nextToken()
 	case token.IDENT:
-		// Handle booleans and enums
-		if p.curToken.Literal == "true" || p.curToken.Literal == "false" {
+		// Handle booleans, null and enums
+		switch p.curToken.Literal {
+		case "true", "false":
 			val.Kind = "Boolean"
-		} else {
+		case "null":
+			val.Kind = "Null"
+		default:
 			val.Kind = "Enum"
 		}
 		val.Literal = p.curToken.Literal
@@ -218,6 +588,7 @@ func (p *Parser) parseValue() *ast.Value {
 			val.Literal = ""
 		}
 	default:
+		p.errorf("unexpected %q in value", p.curToken.Literal)
 		val.Kind = "Illegal"
 		val.Literal = p.curToken.Literal
 		p.nextToken()
@@ -231,11 +602,13 @@ func (p *Parser) parseObject() *ast.Value {
 	p.nextToken() // Skip '{'
 	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
 		if p.curToken.Type != token.IDENT {
+			p.errorf("expected object key, got %q", p.curToken.Literal)
 			return &ast.Value{Kind: "Illegal", Literal: "expected object key"}
 		}
 		key := p.curToken.Literal
 		p.nextToken()
 		if p.curToken.Type != token.COLON {
+			p.errorf("expected ':' after object key %q, got %q", key, p.curToken.Literal)
 			return &ast.Value{Kind: "Illegal", Literal: "expected colon in object"}
 		}
 		p.nextToken() // skip colon
@@ -269,14 +642,31 @@ func (p *Parser) parseArray() *ast.Value {
 
 // parseType parses a GraphQL type (e.g., String, [Int!], User!).
 func (p *Parser) parseType() *ast.Type {
+	start := p.curToken.Pos
+	t := p.parseTypeReference()
+	if t != nil {
+		t.Loc = p.span(start)
+	}
+	return t
+}
+
+// parseTypeReference parses a type without recording its location.
+func (p *Parser) parseTypeReference() *ast.Type {
+	defer p.leave()
+	if !p.enter() {
+		return nil
+	}
 	var t ast.Type
 	if p.curToken.Type == token.LBRACKET {
 		// List type
 		p.nextToken()              // Skip '['
 		innerType := p.parseType() // Recursively parse the inner type
+		if innerType == nil {
+			p.errorf("expected element type in list type, got %q", p.curToken.Literal)
+		}
 		t = ast.Type{IsList: true, Elem: innerType}
 		if p.curToken.Type != token.RBRACKET {
-			// Handle error: expected closing bracket
+			p.errorf("expected ']' to close list type, got %q", p.curToken.Literal)
 		}
 		p.nextToken() // Skip ']'
 		// Check for non-null on the list type
@@ -299,19 +689,68 @@ func (p *Parser) parseType() *ast.Type {
 	return nil
 }
 
-// skipTypeDefinition parses and returns a type definition (e.g., "type Query { ... }").
-func (p *Parser) skipTypeDefinition() ast.Definition {
+// parseObjectTypeDefinition parses an object type definition (e.g., "type Query { ... }").
+func (p *Parser) parseObjectTypeDefinition() ast.Definition {
 	p.nextToken() // Skip "type"
 	if p.curToken.Type != token.IDENT {
 		return nil
 	}
 	typeName := p.curToken.Literal
 	p.nextToken() // Move past type name
+	def := &ast.TypeDefinition{Name: typeName, Interfaces: p.parseImplementsInterfaces()}
+	// The field list is optional, as in extensions that only add interfaces
+	if p.curToken.Type == token.LBRACE {
+		def.Fields = p.parseFieldsDefinition(typeName)
+	}
+	return def
+}
 
-	// Expect an opening brace
-	if p.curToken.Type != token.LBRACE {
+// parseInterfaceTypeDefinition parses an interface type definition
+// (e.g., "interface Node { id: ID! }").
+func (p *Parser) parseInterfaceTypeDefinition() ast.Definition {
+	p.nextToken() // Skip "interface"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected interface name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	def := &ast.InterfaceTypeDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	def.Interfaces = p.parseImplementsInterfaces()
+	if p.curToken.Type == token.LBRACE {
+		def.Fields = p.parseFieldsDefinition(def.Name)
+	}
+	return def
+}
+
+// parseImplementsInterfaces parses an optional implements clause
+// (e.g., "implements Node & Timestamped"), with an optional leading '&'.
+func (p *Parser) parseImplementsInterfaces() []string {
+	if p.curToken.Literal != "implements" {
 		return nil
 	}
+	p.nextToken() // Skip "implements"
+	if p.curToken.Type == token.AMP {
+		p.nextToken()
+	}
+	var names []string
+	for {
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected interface name, got %q", p.curToken.Literal)
+			return names
+		}
+		names = append(names, p.curToken.Literal)
+		p.nextToken()
+		if p.curToken.Type != token.AMP {
+			return names
+		}
+		p.nextToken() // Skip '&'
+	}
+}
+
+// parseFieldsDefinition parses the brace-delimited field definitions of
+// the named type.
+func (p *Parser) parseFieldsDefinition(typeName string) []*ast.Field {
 	p.nextToken() // Skip '{'
 
 	var fields []*ast.Field
@@ -326,6 +765,7 @@ func (p *Parser) skipTypeDefinition() ast.Definition {
 		if field != nil {
 			fields = append(fields, field)
 		} else {
+			p.errorf("unexpected %q in type %s", p.curToken.Literal, typeName)
 			p.nextToken()
 		}
 		if p.curToken.Type == token.COMMA {
@@ -335,81 +775,326 @@ func (p *Parser) skipTypeDefinition() ast.Definition {
 	if p.curToken.Type == token.RBRACE {
 		p.nextToken() // Skip '}'
 	}
-	return &ast.TypeDefinition{
-		Name:   typeName,
-		Fields: fields,
-	}
+	return fields
 }
 
 // parseTypeField parses a field in a type definition.
 func (p *Parser) parseTypeField() *ast.Field {
+	start := p.curToken.Pos
+	description := p.parseDescription()
 	if p.curToken.Type != token.IDENT {
 		return nil
 	}
 	field := &ast.Field{
-		Name: p.curToken.Literal,
+		Name:        p.curToken.Literal,
+		Description: description,
 	}
 	p.nextToken() // Consume the field name
 
-	// If there's an argument list, skip it
+	// If there's an argument list, parse the argument definitions
 	if p.curToken.Type == token.LPAREN {
-		p.skipParenBlock()
+		field.ArgumentDefinitions = p.parseArgumentDefinitions()
 	}
 
-	// If a colon is present, skip the type annotation
+	// If a colon is present, parse the field type
 	if p.curToken.Type == token.COLON {
-		p.skipTypeAnnotation()
+		p.nextToken() // Skip the colon
+		if field.Type = p.parseType(); field.Type == nil {
+			p.errorf("expected type for field %q, got %q", field.Name, p.curToken.Literal)
+		}
 	}
+	field.Directives = p.parseDirectives()
+	field.Deprecated, field.DeprecationReason = ast.Deprecation(field.Directives)
+	field.Loc = p.span(start)
 	return field
 }
 
-// skipParenBlock skips over a parenthesized block.
-func (p *Parser) skipParenBlock() {
-	if p.curToken.Type != token.LPAREN {
-		return
-	}
-	depth := 1
-	p.nextToken() // Skip the opening '('
-	for depth > 0 && p.curToken.Type != token.EOF {
-		if p.curToken.Type == token.LPAREN {
-			depth++
-		} else if p.curToken.Type == token.RPAREN {
-			depth--
+// parseDirectives parses a possibly empty sequence of directives.
+func (p *Parser) parseDirectives() []*ast.Directive {
+	var directives []*ast.Directive
+	for p.curToken.Type == token.AT {
+		start := p.curToken.Pos
+		p.nextToken() // Skip '@'
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected directive name, got %q", p.curToken.Literal)
+			return directives
 		}
+		d := &ast.Directive{Name: p.curToken.Literal}
 		p.nextToken()
+		if p.curToken.Type == token.LPAREN {
+			d.Arguments = p.parseArguments()
+		}
+		d.Loc = p.span(start)
+		directives = append(directives, d)
 	}
+	return directives
 }
 
-// skipTypeAnnotation skips a type annotation (: Type).
-func (p *Parser) skipTypeAnnotation() {
-	if p.curToken.Type != token.COLON {
-		return
+// parseArgumentDefinitions parses the argument list of an SDL field definition.
+func (p *Parser) parseArgumentDefinitions() []*ast.InputValueDefinition {
+	var args []*ast.InputValueDefinition
+	p.nextToken() // Skip '('
+	for p.curToken.Type != token.RPAREN && p.curToken.Type != token.EOF {
+		if p.atInputValueDefinition() {
+			args = append(args, p.parseInputValueDefinition())
+		} else if p.curToken.Type != token.COMMA {
+			p.errorf("unexpected %q in argument definitions", p.curToken.Literal)
+			p.nextToken()
+		}
+		if p.curToken.Type == token.COMMA {
+			p.nextToken()
+		}
 	}
-	p.nextToken() // Skip the colon
+	p.nextToken() // Skip ')'
+	return args
+}
 
-	// Check for list type
-	if p.curToken.Type == token.LBRACKET {
-		p.nextToken() // Consume '['
-		if p.curToken.Type == token.IDENT {
+// parseInputValueDefinition parses an argument or input field definition
+// (e.g., "first: Int = 10").
+func (p *Parser) parseInputValueDefinition() *ast.InputValueDefinition {
+	start := p.curToken.Pos
+	description := p.parseDescription()
+	iv := &ast.InputValueDefinition{Description: description, Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Type == token.COLON {
+		p.nextToken()
+		iv.Type = p.parseType()
+	}
+	if iv.Type == nil {
+		p.errorf("expected type for %q, got %q", iv.Name, p.curToken.Literal)
+	}
+	if p.curToken.Type == token.ASSIGN {
+		p.nextToken()
+		iv.DefaultValue = p.parseValue()
+	}
+	iv.Loc = p.span(start)
+	return iv
+}
+
+// parseInputObjectTypeDefinition parses an input object type definition
+// (e.g., "input UpdateUserInput { name: String! }").
+func (p *Parser) parseInputObjectTypeDefinition() ast.Definition {
+	p.nextToken() // Skip "input"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected input type name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	def := &ast.InputObjectTypeDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Type != token.LBRACE {
+		p.errorf("expected '{' after input type %s, got %q", def.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip '{'
+	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
+		if p.atInputValueDefinition() {
+			def.Fields = append(def.Fields, p.parseInputValueDefinition())
+		} else if p.curToken.Type != token.COMMA {
+			p.errorf("unexpected %q in input type %s", p.curToken.Literal, def.Name)
 			p.nextToken()
-			if p.curToken.Type == token.BANG {
-				p.nextToken()
+		}
+		if p.curToken.Type == token.COMMA {
+			p.nextToken()
+		}
+	}
+	p.nextToken() // Skip '}'
+	return def
+}
+
+// parseEnumTypeDefinition parses an enum type definition
+// (e.g., "enum Role { ADMIN USER }").
+func (p *Parser) parseEnumTypeDefinition() ast.Definition {
+	p.nextToken() // Skip "enum"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected enum name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	def := &ast.EnumTypeDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Type != token.LBRACE {
+		p.errorf("expected '{' after enum %s, got %q", def.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip '{'
+	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
+		switch {
+		case p.curToken.Type == token.IDENT, p.curToken.Type == token.STRING && p.peekToken.Type == token.IDENT:
+			start := p.curToken.Pos
+			description := p.parseDescription()
+			name := p.curToken.Literal
+			if name == "true" || name == "false" || name == "null" {
+				p.errorf("enum %s cannot have value %s", def.Name, name)
 			}
+			p.nextToken()
+			value := &ast.EnumValueDefinition{Description: description, Name: name, Directives: p.parseDirectives()}
+			value.Deprecated, value.DeprecationReason = ast.Deprecation(value.Directives)
+			value.Loc = p.span(start)
+			def.Values = append(def.Values, value)
+		case p.curToken.Type == token.COMMA:
+			p.nextToken()
+		default:
+			p.errorf("unexpected %q in enum %s", p.curToken.Literal, def.Name)
+			p.nextToken()
 		}
-		if p.curToken.Type == token.RBRACKET {
+	}
+	p.nextToken() // Skip '}'
+	return def
+}
+
+// parseUnionTypeDefinition parses a union type definition
+// (e.g., "union SearchResult = User | Post"), with an optional leading '|'.
+func (p *Parser) parseUnionTypeDefinition() ast.Definition {
+	p.nextToken() // Skip "union"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected union name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	def := &ast.UnionTypeDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Type != token.ASSIGN {
+		p.errorf("expected '=' after union %s, got %q", def.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip '='
+	if p.curToken.Type == token.PIPE {
+		p.nextToken()
+	}
+	for {
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected member type of union %s, got %q", def.Name, p.curToken.Literal)
+			return def
+		}
+		def.Types = append(def.Types, p.curToken.Literal)
+		p.nextToken()
+		if p.curToken.Type != token.PIPE {
+			return def
+		}
+		p.nextToken() // Skip '|'
+	}
+}
+
+// parseScalarTypeDefinition parses a custom scalar definition
+// (e.g., "scalar DateTime").
+func (p *Parser) parseScalarTypeDefinition() ast.Definition {
+	p.nextToken() // Skip "scalar"
+	if p.curToken.Type != token.IDENT {
+		p.errorf("expected scalar name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	def := &ast.ScalarTypeDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	def.Directives = p.parseDirectives()
+	return def
+}
+
+// parseSchemaDefinition parses a schema definition
+// (e.g., "schema { query: RootQuery mutation: RootMutation }").
+func (p *Parser) parseSchemaDefinition() ast.Definition {
+	start := p.curToken.Pos
+	p.nextToken() // Skip "schema"
+	def := &ast.SchemaDefinition{Directives: p.parseDirectives()}
+	if p.curToken.Type != token.LBRACE {
+		p.errorf("expected '{' after schema, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip '{'
+	for p.curToken.Type != token.RBRACE && p.curToken.Type != token.EOF {
+		if p.curToken.Type == token.COMMA {
 			p.nextToken()
+			continue
 		}
-		if p.curToken.Type == token.BANG {
+		opStart := p.curToken.Pos
+		operation := p.curToken.Literal
+		if operation != "query" && operation != "mutation" && operation != "subscription" {
+			p.errorf("unexpected %q in schema definition", operation)
 			p.nextToken()
+			continue
 		}
-		return
+		p.nextToken()
+		if p.curToken.Type != token.COLON {
+			p.errorf("expected ':' after %s in schema definition, got %q", operation, p.curToken.Literal)
+			continue
+		}
+		p.nextToken() // Skip ':'
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected root type for %s, got %q", operation, p.curToken.Literal)
+			continue
+		}
+		if def.RootTypeName(operation) != "" {
+			p.errorf("duplicate %s root type in schema definition", operation)
+		}
+		ot := &ast.OperationTypeDefinition{Operation: operation, Type: p.curToken.Literal}
+		p.nextToken()
+		ot.Loc = p.span(opStart)
+		def.OperationTypes = append(def.OperationTypes, ot)
 	}
+	p.nextToken() // Skip '}'
+	def.Loc = p.span(start)
+	return def
+}
 
-	// Simple type
-	if p.curToken.Type == token.IDENT {
+// directiveLocations lists the locations a directive can be defined for.
+var directiveLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true,
+	"FRAGMENT_DEFINITION": true, "FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true,
+	"VARIABLE_DEFINITION": true, "SCHEMA": true, "SCALAR": true, "OBJECT": true,
+	"FIELD_DEFINITION": true, "ARGUMENT_DEFINITION": true, "INTERFACE": true,
+	"UNION": true, "ENUM": true, "ENUM_VALUE": true, "INPUT_OBJECT": true,
+	"INPUT_FIELD_DEFINITION": true,
+}
+
+// parseDirectiveDefinition parses a directive definition
+// (e.g., "directive @auth(role: String!) repeatable on FIELD | OBJECT").
+func (p *Parser) parseDirectiveDefinition() ast.Definition {
+	start := p.curToken.Pos
+	p.nextToken() // Skip "directive"
+	if p.curToken.Type != token.AT || p.peekToken.Type != token.IDENT {
+		p.errorf("expected directive name, got %q", p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip '@'
+	def := &ast.DirectiveDefinition{Name: p.curToken.Literal}
+	p.nextToken()
+	if p.curToken.Type == token.LPAREN {
+		def.Arguments = p.parseArgumentDefinitions()
+	}
+	if p.curToken.Literal == "repeatable" {
+		def.Repeatable = true
+		p.nextToken()
+	}
+	if p.curToken.Literal != "on" {
+		p.errorf("expected \"on\" in directive definition @%s, got %q", def.Name, p.curToken.Literal)
+		p.skipDefinition()
+		return nil
+	}
+	p.nextToken() // Skip "on"
+	if p.curToken.Type == token.PIPE {
 		p.nextToken()
 	}
-	if p.curToken.Type == token.BANG {
+	for {
+		if p.curToken.Type != token.IDENT {
+			p.errorf("expected location of directive @%s, got %q", def.Name, p.curToken.Literal)
+			break
+		}
+		if !directiveLocations[p.curToken.Literal] {
+			p.errorf("unknown directive location %s", p.curToken.Literal)
+		}
+		def.Locations = append(def.Locations, p.curToken.Literal)
 		p.nextToken()
+		if p.curToken.Type != token.PIPE {
+			break
+		}
+		p.nextToken() // Skip '|'
 	}
+	def.Loc = p.span(start)
+	return def
 }
@@ -0,0 +1,18 @@
+module github.com/Protocol-Lattice/graphql/pubsub/kafka
+
+go 1.23.0
+
+toolchain go1.23.8
+
+require (
+	github.com/Protocol-Lattice/graphql v0.0.0-00010101000000-000000000000
+	github.com/segmentio/kafka-go v0.4.51
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
+
+// The adapter is developed alongside the module it belongs to.
+replace github.com/Protocol-Lattice/graphql => ../..
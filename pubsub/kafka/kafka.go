@@ -0,0 +1,126 @@
+// Package kafka feeds a pubsub.Broker from Kafka topics, so event-driven
+// backends can power GraphQL subscriptions.
+//
+//	src := kafka.New(kafka.Config{
+//		Brokers: []string{"localhost:9092"},
+//		GroupID: "graphql-gateway",
+//		Topics:  []string{"orders"},
+//	}, broker)
+//	defer src.Close()
+//	go src.Run(ctx)
+//
+// Subscription resolvers then return broker.Events(ctx, "orders").
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Protocol-Lattice/graphql/pubsub"
+	"github.com/segmentio/kafka-go"
+)
+
+// Config configures a Source.
+type Config struct {
+	// Brokers lists the Kafka bootstrap servers.
+	Brokers []string
+	// GroupID is the consumer group. Offsets are committed for the group
+	// after each message is published, so a restarted Source resumes
+	// where it left off.
+	GroupID string
+	// Topics lists the Kafka topics to consume.
+	Topics []string
+
+	// Decode converts a message into a subscription event. It defaults to
+	// decoding the value as JSON; set it to plug in Avro or Protobuf.
+	Decode func(msg kafka.Message) (interface{}, error)
+	// Topic names the pubsub topic a message is published to. It defaults
+	// to the message's Kafka topic.
+	Topic func(msg kafka.Message) string
+	// OnError is called for messages that fail to decode; they are skipped.
+	OnError func(msg kafka.Message, err error)
+
+	// Reader, if set, overrides the reader configuration built from the
+	// fields above for full control over the consumer.
+	Reader *kafka.ReaderConfig
+}
+
+// reader is the part of *kafka.Reader used by a Source.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Source consumes Kafka messages and publishes them onto a broker.
+type Source struct {
+	cfg    Config
+	broker *pubsub.Broker
+	reader reader
+}
+
+// New creates a Source that publishes the messages described by cfg onto
+// broker. Call Run to start consuming.
+func New(cfg Config, broker *pubsub.Broker) *Source {
+	readerCfg := kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		GroupID:     cfg.GroupID,
+		GroupTopics: cfg.Topics,
+	}
+	if cfg.Reader != nil {
+		readerCfg = *cfg.Reader
+	}
+	return newSource(cfg, broker, kafka.NewReader(readerCfg))
+}
+
+// newSource creates a Source reading from r.
+func newSource(cfg Config, broker *pubsub.Broker, r reader) *Source {
+	if cfg.Decode == nil {
+		cfg.Decode = decodeJSON
+	}
+	if cfg.Topic == nil {
+		cfg.Topic = func(msg kafka.Message) string { return msg.Topic }
+	}
+	return &Source{cfg: cfg, broker: broker, reader: r}
+}
+
+// Run consumes messages until ctx is done or the reader fails. It returns
+// nil when stopped by ctx.
+func (s *Source) Run(ctx context.Context) error {
+	for {
+		msg, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				return nil
+			}
+			return err
+		}
+		event, err := s.cfg.Decode(msg)
+		if err != nil {
+			if s.cfg.OnError != nil {
+				s.cfg.OnError(msg, err)
+			}
+		} else {
+			s.broker.Publish(s.cfg.Topic(msg), event)
+		}
+		if err := s.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Close closes the underlying Kafka reader.
+func (s *Source) Close() error {
+	return s.reader.Close()
+}
+
+// decodeJSON decodes a message value as JSON.
+func decodeJSON(msg kafka.Message) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(msg.Value, &v)
+	return v, err
+}
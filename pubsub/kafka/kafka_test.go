@@ -0,0 +1,83 @@
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/pubsub"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader serves queued messages, then blocks until ctx is done.
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+func TestSourcePublishesMessages(t *testing.T) {
+	broker := pubsub.New()
+	fr := &fakeReader{messages: []kafka.Message{
+		{Topic: "orders", Offset: 1, Value: []byte(`{"id":"o1"}`)},
+		{Topic: "orders", Offset: 2, Value: []byte(`not json`)},
+		{Topic: "orders", Offset: 3, Value: []byte(`{"id":"o3"}`)},
+	}}
+	var failed []int64
+	src := newSource(Config{OnError: func(msg kafka.Message, err error) {
+		failed = append(failed, msg.Offset)
+	}}, broker, fr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := broker.Subscribe(ctx, "orders")
+	done := make(chan error)
+	go func() { done <- src.Run(ctx) }()
+
+	for _, id := range []string{"o1", "o3"} {
+		select {
+		case event := <-events:
+			if want := map[string]interface{}{"id": id}; !reflect.DeepEqual(event, want) {
+				t.Errorf("expected %v, got %v", want, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	if !reflect.DeepEqual(fr.committed, []int64{1, 2, 3}) {
+		t.Errorf("expected all offsets committed, got %v", fr.committed)
+	}
+	if !reflect.DeepEqual(failed, []int64{2}) {
+		t.Errorf("expected offset 2 to fail decoding, got %v", failed)
+	}
+}
@@ -0,0 +1,121 @@
+// Package pubsub is an in-memory publish/subscribe broker for feeding
+// subscription resolvers.
+//
+// A subscription resolver typically returns the iterator from Events:
+//
+//	registry.RegisterSubscriptionResolverContext("prices", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+//		return broker.Events(ctx, "prices"), nil
+//	})
+package pubsub
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// bufferSize is the minimum number of undelivered events buffered per
+// subscriber. Events published to a subscriber whose buffer is full are
+// dropped for that subscriber so one slow client cannot stall a topic.
+const bufferSize = 16
+
+// Broker routes published events to the subscribers of named topics.
+// It is safe for concurrent use.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+}
+
+// topic holds the subscribers and retained events of a single topic.
+type topic struct {
+	subscribers map[chan interface{}]struct{}
+	retain      int
+	retained    []interface{}
+}
+
+// New creates an empty Broker.
+func New() *Broker {
+	return &Broker{topics: make(map[string]*topic)}
+}
+
+// topic returns the named topic, creating it if needed. b.mu must be held.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subscribers: make(map[chan interface{}]struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// SetRetention keeps the last n events published to the named topic and
+// replays them, oldest first, to every new subscriber before live events,
+// so late subscribers see current state immediately. Zero disables
+// retention and discards retained events.
+func (b *Broker) SetRetention(name string, n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(name)
+	t.retain = max(n, 0)
+	if len(t.retained) > t.retain {
+		t.retained = append([]interface{}(nil), t.retained[len(t.retained)-t.retain:]...)
+	}
+}
+
+// Publish delivers event to every current subscriber of the named topic.
+func (b *Broker) Publish(name string, event interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(name)
+	if t.retain > 0 {
+		t.retained = append(t.retained, event)
+		if len(t.retained) > t.retain {
+			t.retained = t.retained[1:]
+		}
+	}
+	for ch := range t.subscribers {
+		select {
+		case ch <- event:
+		default: // subscriber is not keeping up
+		}
+	}
+}
+
+// Subscribe returns a channel of the events published to the named topic,
+// starting with any retained events. The channel is closed once ctx is done.
+func (b *Broker) Subscribe(ctx context.Context, name string) <-chan interface{} {
+	b.mu.Lock()
+	t := b.topic(name)
+	ch := make(chan interface{}, max(bufferSize, t.retain))
+	for _, event := range t.retained {
+		ch <- event
+	}
+	t.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(t.subscribers, ch)
+		close(ch)
+		b.mu.Unlock()
+	}()
+	return ch
+}
+
+// Events returns an iterator over the events of the named topic, suitable
+// as the result of a subscription resolver given the resolver's ctx. The
+// iteration ends, releasing the underlying subscription, when the executor
+// stops the iterator or once ctx is done, such as when the client goes
+// away, even if nothing is published to the topic.
+func (b *Broker) Events(ctx context.Context, name string) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		for event := range b.Subscribe(ctx, name) {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	b := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := b.Subscribe(ctx, "ticks")
+
+	b.Publish("ticks", 1)
+	b.Publish("other", "ignored")
+	if got := receive(t, ch); got != 1 {
+		t.Errorf("expected 1, got %v", got)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to close after cancel")
+	}
+}
+
+func TestRetentionReplaysLastEvents(t *testing.T) {
+	b := New()
+	b.SetRetention("prices", 2)
+	for i := 1; i <= 3; i++ {
+		b.Publish("prices", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := b.Subscribe(ctx, "prices")
+	b.Publish("prices", 4)
+	for _, want := range []int{2, 3, 4} {
+		if got := receive(t, ch); got != want {
+			t.Errorf("expected %d, got %v", want, got)
+		}
+	}
+
+	b.SetRetention("prices", 1)
+	late := b.Subscribe(ctx, "prices")
+	if got := receive(t, late); got != 4 {
+		t.Errorf("expected latest event 4, got %v", got)
+	}
+}
+
+func TestEventsIterator(t *testing.T) {
+	b := New()
+	b.SetRetention("status", 1)
+	b.Publish("status", "up")
+
+	for event := range b.Events(context.Background(), "status") {
+		if event != "up" {
+			t.Errorf("expected retained event, got %v", event)
+		}
+		break
+	}
+	waitForNoSubscribers(t, b, "status")
+}
+
+func TestEventsEndWithContext(t *testing.T) {
+	b := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range b.Events(ctx, "quiet") {
+			t.Error("expected no events")
+		}
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the iteration to end with its context")
+	}
+	waitForNoSubscribers(t, b, "quiet")
+}
+
+// waitForNoSubscribers waits for the named topic to lose its subscribers.
+func waitForNoSubscribers(t *testing.T, b *Broker, name string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.mu.Lock()
+		n := len(b.topic(name).subscribers)
+		b.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected no subscribers after the iteration ended, got %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
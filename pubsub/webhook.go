@@ -0,0 +1,103 @@
+package pubsub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// defaultMaxWebhookBody is the body size limit used when Webhook.MaxBodySize is zero.
+const defaultMaxWebhookBody = 1 << 20
+
+// Webhook is an http.Handler that lets external systems publish events.
+// Each authenticated POST publishes its JSON body, decoded, onto the topic
+// named by the request path, so mount it with http.StripPrefix:
+//
+//	mux.Handle("/webhooks/", http.StripPrefix("/webhooks/", &pubsub.Webhook{
+//		Broker:       broker,
+//		Authenticate: pubsub.HMACSignature(secret, "X-Signature"),
+//	}))
+type Webhook struct {
+	// Broker receives the published events.
+	Broker *Broker
+	// Authenticate verifies a request and its body. It is required; a
+	// Webhook without it rejects every request.
+	Authenticate func(r *http.Request, body []byte) error
+	// Topics, if set, lists the only topics that may be published to.
+	Topics []string
+	// MaxBodySize limits the request body in bytes (default 1 MiB).
+	MaxBodySize int64
+}
+
+// ServeHTTP publishes the request body and responds 202 Accepted.
+func (h *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	topic := strings.Trim(r.URL.Path, "/")
+	if topic == "" || len(h.Topics) > 0 && !slices.Contains(h.Topics, topic) {
+		http.Error(w, "unknown topic", http.StatusNotFound)
+		return
+	}
+
+	limit := h.MaxBodySize
+	if limit <= 0 {
+		limit = defaultMaxWebhookBody
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+	if h.Authenticate == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.Authenticate(r, body); err != nil {
+		http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var event interface{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	h.Broker.Publish(topic, event)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// HMACSignature returns a Webhook authenticator that requires header to
+// hold the hex HMAC-SHA256 of the body keyed with secret, optionally
+// prefixed with "sha256=" as sent by GitHub and similar providers.
+func HMACSignature(secret []byte, header string) func(r *http.Request, body []byte) error {
+	return func(r *http.Request, body []byte) error {
+		sig := strings.TrimPrefix(r.Header.Get(header), "sha256=")
+		if sig == "" {
+			return errors.New("missing signature")
+		}
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return errors.New("malformed signature")
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWebhookPublishes(t *testing.T) {
+	b := New()
+	secret := []byte("s3cret")
+	h := http.StripPrefix("/webhooks/", &Webhook{
+		Broker:       b,
+		Authenticate: HMACSignature(secret, "X-Signature"),
+		Topics:       []string{"builds"},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := b.Subscribe(ctx, "builds")
+
+	body := `{"status":"passed"}`
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	sig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		path, sig string
+		want      int
+	}{
+		{"/webhooks/builds", sig, http.StatusAccepted},
+		{"/webhooks/builds", "sha256=00", http.StatusUnauthorized},
+		{"/webhooks/builds", "", http.StatusUnauthorized},
+		{"/webhooks/payments", sig, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", tt.path, strings.NewReader(body))
+		r.Header.Set("X-Signature", tt.sig)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s with signature %q: expected %d, got %d", tt.path, tt.sig, tt.want, w.Code)
+		}
+	}
+
+	want := map[string]interface{}{"status": "passed"}
+	if got := receive(t, events); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected extra event %v", event)
+	default:
+	}
+}
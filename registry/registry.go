@@ -9,6 +9,9 @@ var globalExecutor = executor.New()
 // This is re-exported for convenience.
 type ResolverFunc = executor.ResolverFunc
 
+// ContextResolverFunc defines the signature of context-aware resolvers.
+type ContextResolverFunc = executor.ContextResolverFunc
+
 // RegisterQueryResolver registers a resolver for a query field in the global executor.
 func RegisterQueryResolver(field string, resolver ResolverFunc) {
 	globalExecutor.RegisterQueryResolver(field, resolver)
@@ -24,6 +27,21 @@ func RegisterSubscriptionResolver(field string, resolver ResolverFunc) {
 	globalExecutor.RegisterSubscriptionResolver(field, resolver)
 }
 
+// RegisterQueryResolverContext registers a context-aware query resolver in the global executor.
+func RegisterQueryResolverContext(field string, resolver ContextResolverFunc) {
+	globalExecutor.RegisterQueryResolverContext(field, resolver)
+}
+
+// RegisterMutationResolverContext registers a context-aware mutation resolver in the global executor.
+func RegisterMutationResolverContext(field string, resolver ContextResolverFunc) {
+	globalExecutor.RegisterMutationResolverContext(field, resolver)
+}
+
+// RegisterSubscriptionResolverContext registers a context-aware subscription resolver in the global executor.
+func RegisterSubscriptionResolverContext(field string, resolver ContextResolverFunc) {
+	globalExecutor.RegisterSubscriptionResolverContext(field, resolver)
+}
+
 // GetGlobalExecutor returns the global executor instance.
 // This allows the handler package to access the registered resolvers.
 func GetGlobalExecutor() *executor.Executor {
@@ -0,0 +1,473 @@
+// Package schemadiff compares two schemas parsed from SDL and classifies
+// the changes between them, so that deployments can be gated on the
+// absence of breaking changes.
+package schemadiff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+)
+
+// Severity classifies the impact of a change on existing clients.
+type Severity int
+
+const (
+	// Safe changes cannot break existing clients.
+	Safe Severity = iota
+	// Dangerous changes are valid for existing operations but may change
+	// their behavior, e.g. a new enum value a client does not handle.
+	Dangerous
+	// Breaking changes make existing operations invalid or change the
+	// shape of their results.
+	Breaking
+)
+
+// String returns the severity in upper case, e.g. "BREAKING".
+func (s Severity) String() string {
+	switch s {
+	case Safe:
+		return "SAFE"
+	case Dangerous:
+		return "DANGEROUS"
+	case Breaking:
+		return "BREAKING"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ChangeType identifies the kind of a change.
+type ChangeType string
+
+// Change types reported by Diff.
+const (
+	TypeRemoved              ChangeType = "TYPE_REMOVED"
+	TypeAdded                ChangeType = "TYPE_ADDED"
+	TypeKindChanged          ChangeType = "TYPE_KIND_CHANGED"
+	FieldRemoved             ChangeType = "FIELD_REMOVED"
+	FieldAdded               ChangeType = "FIELD_ADDED"
+	FieldTypeChanged         ChangeType = "FIELD_TYPE_CHANGED"
+	FieldDeprecated          ChangeType = "FIELD_DEPRECATED"
+	ArgumentRemoved          ChangeType = "ARGUMENT_REMOVED"
+	ArgumentAdded            ChangeType = "ARGUMENT_ADDED"
+	RequiredArgumentAdded    ChangeType = "REQUIRED_ARGUMENT_ADDED"
+	ArgumentTypeChanged      ChangeType = "ARGUMENT_TYPE_CHANGED"
+	ArgumentDefaultChanged   ChangeType = "ARGUMENT_DEFAULT_CHANGED"
+	InputFieldRemoved        ChangeType = "INPUT_FIELD_REMOVED"
+	InputFieldAdded          ChangeType = "INPUT_FIELD_ADDED"
+	RequiredInputFieldAdded  ChangeType = "REQUIRED_INPUT_FIELD_ADDED"
+	InputFieldTypeChanged    ChangeType = "INPUT_FIELD_TYPE_CHANGED"
+	EnumValueRemoved         ChangeType = "ENUM_VALUE_REMOVED"
+	EnumValueAdded           ChangeType = "ENUM_VALUE_ADDED"
+	UnionMemberRemoved       ChangeType = "UNION_MEMBER_REMOVED"
+	UnionMemberAdded         ChangeType = "UNION_MEMBER_ADDED"
+	InterfaceRemoved         ChangeType = "INTERFACE_REMOVED"
+	InterfaceAdded           ChangeType = "INTERFACE_ADDED"
+	DirectiveRemoved         ChangeType = "DIRECTIVE_REMOVED"
+	DirectiveAdded           ChangeType = "DIRECTIVE_ADDED"
+	DirectiveLocationRemoved ChangeType = "DIRECTIVE_LOCATION_REMOVED"
+	RootOperationTypeChanged ChangeType = "ROOT_OPERATION_TYPE_CHANGED"
+)
+
+// Change is a single difference between two schemas.
+type Change struct {
+	Type     ChangeType
+	Severity Severity
+	Path     string // Schema coordinate, e.g. "Query.user(id:)"
+	Message  string // Human-readable description
+}
+
+// String returns the change as "SEVERITY: message".
+func (c Change) String() string {
+	return c.Severity.String() + ": " + c.Message
+}
+
+// HasBreaking reports whether changes include a breaking change.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Severity == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the changes from oldSchema to newSchema. Type extensions
+// in either document are merged into their types first. Changes are
+// ordered by type name, then by kind of change.
+func Diff(oldSchema, newSchema *ast.Document) ([]Change, error) {
+	oldSchema, err := ast.MergeExtensions(oldSchema)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %w", err)
+	}
+	newSchema, err = ast.MergeExtensions(newSchema)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %w", err)
+	}
+	d := &differ{}
+	d.diffRootTypes(oldSchema, newSchema)
+	d.diffTypes(namedTypes(oldSchema), namedTypes(newSchema))
+	d.diffDirectives(directives(oldSchema), directives(newSchema))
+	return d.changes, nil
+}
+
+// differ accumulates changes.
+type differ struct {
+	changes []Change
+}
+
+// add records a change.
+func (d *differ) add(typ ChangeType, severity Severity, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{Type: typ, Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// diffRootTypes compares the root operation types.
+func (d *differ) diffRootTypes(oldSchema, newSchema *ast.Document) {
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		before, after := oldSchema.RootTypeName(op), newSchema.RootTypeName(op)
+		if before != after && before != "" && hasType(oldSchema, before) {
+			d.add(RootOperationTypeChanged, Breaking, "schema."+op, "%s root type changed from %s to %s", op, before, orNone(after))
+		}
+	}
+}
+
+// diffTypes compares the named types of both schemas.
+func (d *differ) diffTypes(before, after map[string]ast.Definition) {
+	for _, name := range sortedKeys(before) {
+		old := before[name]
+		cur, ok := after[name]
+		switch {
+		case !ok:
+			d.add(TypeRemoved, Breaking, name, "type %s was removed", name)
+		case kind(old) != kind(cur):
+			d.add(TypeKindChanged, Breaking, name, "type %s changed from %s to %s", name, kind(old), kind(cur))
+		default:
+			d.diffType(old, cur)
+		}
+	}
+	for _, name := range sortedKeys(after) {
+		if _, ok := before[name]; !ok {
+			d.add(TypeAdded, Safe, name, "type %s was added", name)
+		}
+	}
+}
+
+// diffType compares two definitions of the same name and kind.
+func (d *differ) diffType(old, cur ast.Definition) {
+	switch old := old.(type) {
+	case *ast.TypeDefinition:
+		cur := cur.(*ast.TypeDefinition)
+		d.diffInterfaces(old.Name, old.Interfaces, cur.Interfaces)
+		d.diffFields(old.Name, old.Fields, cur.Fields)
+	case *ast.InterfaceTypeDefinition:
+		cur := cur.(*ast.InterfaceTypeDefinition)
+		d.diffInterfaces(old.Name, old.Interfaces, cur.Interfaces)
+		d.diffFields(old.Name, old.Fields, cur.Fields)
+	case *ast.InputObjectTypeDefinition:
+		d.diffInputFields(old.Name, old.Fields, cur.(*ast.InputObjectTypeDefinition).Fields)
+	case *ast.EnumTypeDefinition:
+		d.diffEnumValues(old, cur.(*ast.EnumTypeDefinition))
+	case *ast.UnionTypeDefinition:
+		d.diffUnionMembers(old, cur.(*ast.UnionTypeDefinition))
+	}
+}
+
+// diffInterfaces compares the interfaces implemented by a type.
+func (d *differ) diffInterfaces(typeName string, before, after []string) {
+	for _, name := range before {
+		if !contains(after, name) {
+			d.add(InterfaceRemoved, Breaking, typeName, "%s no longer implements %s", typeName, name)
+		}
+	}
+	for _, name := range after {
+		if !contains(before, name) {
+			d.add(InterfaceAdded, Dangerous, typeName, "%s now implements %s", typeName, name)
+		}
+	}
+}
+
+// diffFields compares the fields of an object or interface type.
+func (d *differ) diffFields(typeName string, before, after []*ast.Field) {
+	for _, old := range before {
+		path := typeName + "." + old.Name
+		cur := findField(after, old.Name)
+		if cur == nil {
+			d.add(FieldRemoved, Breaking, path, "field %s was removed", path)
+			continue
+		}
+		if !safeOutputChange(old.Type, cur.Type) {
+			d.add(FieldTypeChanged, Breaking, path, "field %s changed type from %s to %s", path, old.Type, cur.Type)
+		} else if old.Type.String() != cur.Type.String() {
+			d.add(FieldTypeChanged, Safe, path, "field %s changed type from %s to %s", path, old.Type, cur.Type)
+		}
+		if cur.Deprecated && !old.Deprecated {
+			d.add(FieldDeprecated, Safe, path, "field %s was deprecated", path)
+		}
+		d.diffArguments(path, old.ArgumentDefinitions, cur.ArgumentDefinitions)
+	}
+	for _, cur := range after {
+		if findField(before, cur.Name) == nil {
+			path := typeName + "." + cur.Name
+			d.add(FieldAdded, Safe, path, "field %s was added", path)
+		}
+	}
+}
+
+// diffArguments compares the arguments of a field or directive at path.
+func (d *differ) diffArguments(path string, before, after []*ast.InputValueDefinition) {
+	for _, old := range before {
+		argPath := path + "(" + old.Name + ":)"
+		cur := findInputValue(after, old.Name)
+		if cur == nil {
+			d.add(ArgumentRemoved, Breaking, argPath, "argument %s was removed", argPath)
+			continue
+		}
+		if !safeInputChange(old.Type, cur.Type) {
+			d.add(ArgumentTypeChanged, Breaking, argPath, "argument %s changed type from %s to %s", argPath, old.Type, cur.Type)
+		} else if old.Type.String() != cur.Type.String() {
+			d.add(ArgumentTypeChanged, Safe, argPath, "argument %s changed type from %s to %s", argPath, old.Type, cur.Type)
+		}
+		if old.DefaultValue != nil && old.DefaultValue.String() != cur.DefaultValue.String() {
+			d.add(ArgumentDefaultChanged, Dangerous, argPath, "argument %s changed default value from %s to %s", argPath, old.DefaultValue, cur.DefaultValue)
+		}
+	}
+	for _, cur := range after {
+		if findInputValue(before, cur.Name) != nil {
+			continue
+		}
+		argPath := path + "(" + cur.Name + ":)"
+		if required(cur) {
+			d.add(RequiredArgumentAdded, Breaking, argPath, "required argument %s was added", argPath)
+		} else {
+			d.add(ArgumentAdded, Safe, argPath, "argument %s was added", argPath)
+		}
+	}
+}
+
+// diffInputFields compares the fields of an input object type.
+func (d *differ) diffInputFields(typeName string, before, after []*ast.InputValueDefinition) {
+	for _, old := range before {
+		path := typeName + "." + old.Name
+		cur := findInputValue(after, old.Name)
+		if cur == nil {
+			d.add(InputFieldRemoved, Breaking, path, "input field %s was removed", path)
+			continue
+		}
+		if !safeInputChange(old.Type, cur.Type) {
+			d.add(InputFieldTypeChanged, Breaking, path, "input field %s changed type from %s to %s", path, old.Type, cur.Type)
+		} else if old.Type.String() != cur.Type.String() {
+			d.add(InputFieldTypeChanged, Safe, path, "input field %s changed type from %s to %s", path, old.Type, cur.Type)
+		}
+	}
+	for _, cur := range after {
+		if findInputValue(before, cur.Name) != nil {
+			continue
+		}
+		path := typeName + "." + cur.Name
+		if required(cur) {
+			d.add(RequiredInputFieldAdded, Breaking, path, "required input field %s was added", path)
+		} else {
+			d.add(InputFieldAdded, Dangerous, path, "input field %s was added", path)
+		}
+	}
+}
+
+// diffEnumValues compares the values of an enum type.
+func (d *differ) diffEnumValues(old, cur *ast.EnumTypeDefinition) {
+	has := func(values []*ast.EnumValueDefinition, name string) bool {
+		for _, v := range values {
+			if v.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range old.Values {
+		if !has(cur.Values, v.Name) {
+			d.add(EnumValueRemoved, Breaking, old.Name+"."+v.Name, "enum value %s.%s was removed", old.Name, v.Name)
+		}
+	}
+	for _, v := range cur.Values {
+		if !has(old.Values, v.Name) {
+			d.add(EnumValueAdded, Dangerous, old.Name+"."+v.Name, "enum value %s.%s was added", old.Name, v.Name)
+		}
+	}
+}
+
+// diffUnionMembers compares the member types of a union.
+func (d *differ) diffUnionMembers(old, cur *ast.UnionTypeDefinition) {
+	for _, member := range old.Types {
+		if !contains(cur.Types, member) {
+			d.add(UnionMemberRemoved, Breaking, old.Name, "%s was removed from union %s", member, old.Name)
+		}
+	}
+	for _, member := range cur.Types {
+		if !contains(old.Types, member) {
+			d.add(UnionMemberAdded, Dangerous, old.Name, "%s was added to union %s", member, old.Name)
+		}
+	}
+}
+
+// diffDirectives compares the directive definitions of both schemas.
+func (d *differ) diffDirectives(before, after map[string]*ast.DirectiveDefinition) {
+	for _, name := range sortedKeys(before) {
+		old := before[name]
+		path := "@" + name
+		cur, ok := after[name]
+		if !ok {
+			d.add(DirectiveRemoved, Breaking, path, "directive %s was removed", path)
+			continue
+		}
+		for _, loc := range old.Locations {
+			if !contains(cur.Locations, loc) {
+				d.add(DirectiveLocationRemoved, Breaking, path, "location %s was removed from directive %s", loc, path)
+			}
+		}
+		d.diffArguments(path, old.Arguments, cur.Arguments)
+	}
+	for _, name := range sortedKeys(after) {
+		if _, ok := before[name]; !ok {
+			d.add(DirectiveAdded, Safe, "@"+name, "directive @%s was added", name)
+		}
+	}
+}
+
+// safeOutputChange reports whether a field type may change from before to
+// after without breaking clients: wrappers must match, except that a
+// nullable position may become non-null.
+func safeOutputChange(before, after *ast.Type) bool {
+	if before == nil || after == nil {
+		return before == after
+	}
+	if before.NonNull && !after.NonNull {
+		return false
+	}
+	if before.IsList != after.IsList {
+		return false
+	}
+	if before.IsList {
+		return safeOutputChange(before.Elem, after.Elem)
+	}
+	return before.Name == after.Name
+}
+
+// safeInputChange reports whether an argument or input field type may
+// change from before to after without breaking clients: wrappers must
+// match, except that a non-null position may become nullable.
+func safeInputChange(before, after *ast.Type) bool {
+	if before == nil || after == nil {
+		return before == after
+	}
+	if !before.NonNull && after.NonNull {
+		return false
+	}
+	if before.IsList != after.IsList {
+		return false
+	}
+	if before.IsList {
+		return safeInputChange(before.Elem, after.Elem)
+	}
+	return before.Name == after.Name
+}
+
+// required reports whether an argument or input field must be provided.
+func required(iv *ast.InputValueDefinition) bool {
+	return iv.Type != nil && iv.Type.NonNull && iv.DefaultValue == nil
+}
+
+// namedTypes indexes the named type definitions of doc by name.
+func namedTypes(doc *ast.Document) map[string]ast.Definition {
+	types := make(map[string]ast.Definition)
+	for _, def := range doc.Definitions {
+		if kind(def) != "" {
+			types[def.TokenLiteral()] = def
+		}
+	}
+	return types
+}
+
+// hasType reports whether doc defines the named type.
+func hasType(doc *ast.Document, name string) bool {
+	_, ok := namedTypes(doc)[name]
+	return ok
+}
+
+// directives indexes the directive definitions of doc by name.
+func directives(doc *ast.Document) map[string]*ast.DirectiveDefinition {
+	defs := make(map[string]*ast.DirectiveDefinition)
+	for _, def := range doc.Definitions {
+		if dir, ok := def.(*ast.DirectiveDefinition); ok {
+			defs[dir.Name] = dir
+		}
+	}
+	return defs
+}
+
+// kind returns the kind of a named type definition, or "" for other
+// definitions.
+func kind(def ast.Definition) string {
+	switch def.(type) {
+	case *ast.TypeDefinition:
+		return "object type"
+	case *ast.InterfaceTypeDefinition:
+		return "interface"
+	case *ast.UnionTypeDefinition:
+		return "union"
+	case *ast.EnumTypeDefinition:
+		return "enum"
+	case *ast.InputObjectTypeDefinition:
+		return "input object type"
+	case *ast.ScalarTypeDefinition:
+		return "scalar"
+	}
+	return ""
+}
+
+// findField returns the field named name, or nil.
+func findField(fields []*ast.Field, name string) *ast.Field {
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// findInputValue returns the argument or input field named name, or nil.
+func findInputValue(values []*ast.InputValueDefinition, name string) *ast.InputValueDefinition {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// contains reports whether names includes name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// orNone returns name, or "none" if it is empty.
+func orNone(name string) string {
+	if name == "" {
+		return "none"
+	}
+	return name
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
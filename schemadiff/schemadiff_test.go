@@ -0,0 +1,163 @@
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+func parse(t *testing.T, src string) *ast.Document {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return doc
+}
+
+func TestDiff(t *testing.T) {
+	oldSchema := parse(t, `
+type Query {
+  user(id: ID!, limit: Int = 10): User
+  users: [User!]
+  legacy: String
+}
+
+type User {
+  id: ID!
+  name: String
+  email: String!
+}
+
+input UserFilter {
+  name: String!
+}
+
+enum Role { ADMIN USER }
+
+union SearchResult = User
+
+type Post { id: ID! }
+`)
+	newSchema := parse(t, `
+type Query {
+  user(id: ID!, limit: Int = 20, verbose: Boolean!): User
+  users: [User]
+  legacy: String @deprecated
+  posts: [Post]
+}
+
+type User {
+  id: ID!
+  name: String!
+  email: String
+}
+
+input UserFilter {
+  name: String
+  role: Role!
+}
+
+enum Role { ADMIN USER GUEST }
+
+union SearchResult = User | Post
+
+type Post { id: ID! }
+
+type Comment { id: ID! }
+`)
+	changes, err := Diff(oldSchema, newSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		typ      ChangeType
+		severity Severity
+		path     string
+	}{
+		{FieldTypeChanged, Breaking, "Query.users"},
+		{FieldDeprecated, Safe, "Query.legacy"},
+		{ArgumentDefaultChanged, Dangerous, "Query.user(limit:)"},
+		{RequiredArgumentAdded, Breaking, "Query.user(verbose:)"},
+		{FieldAdded, Safe, "Query.posts"},
+		{EnumValueAdded, Dangerous, "Role.GUEST"},
+		{UnionMemberAdded, Dangerous, "SearchResult"},
+		{FieldTypeChanged, Safe, "User.name"},
+		{FieldTypeChanged, Breaking, "User.email"},
+		{InputFieldTypeChanged, Safe, "UserFilter.name"},
+		{RequiredInputFieldAdded, Breaking, "UserFilter.role"},
+		{TypeAdded, Safe, "Comment"},
+	}
+	got := make(map[string]Change)
+	for _, c := range changes {
+		got[string(c.Type)+" "+c.Path] = c
+	}
+	for _, w := range want {
+		c, ok := got[string(w.typ)+" "+w.path]
+		if !ok {
+			t.Errorf("missing %s at %s", w.typ, w.path)
+			continue
+		}
+		if c.Severity != w.severity {
+			t.Errorf("%s at %s: expected %s, got %s", w.typ, w.path, w.severity, c.Severity)
+		}
+	}
+	if len(changes) != len(want) {
+		t.Errorf("expected %d changes, got %d: %v", len(want), len(changes), changes)
+	}
+	if !HasBreaking(changes) {
+		t.Error("expected breaking changes")
+	}
+}
+
+func TestDiffRemovals(t *testing.T) {
+	oldSchema := parse(t, `
+type Query { user: User, search: SearchResult }
+type User { id: ID! }
+type Post { id: ID! }
+union SearchResult = User | Post
+enum Role { ADMIN USER }
+`)
+	newSchema := parse(t, `
+type Query { user: User, search: SearchResult }
+type User { id: ID! }
+union SearchResult = User
+scalar Role
+`)
+	changes, err := Diff(oldSchema, newSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []ChangeType
+	for _, c := range changes {
+		if c.Severity != Breaking {
+			t.Errorf("expected %s to be breaking", c.Type)
+		}
+		types = append(types, c.Type)
+	}
+	want := []ChangeType{TypeRemoved, TypeKindChanged, UnionMemberRemoved}
+	if len(types) != len(want) {
+		t.Fatalf("expected %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("change %d: expected %s, got %s", i, want[i], types[i])
+		}
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	src := `type Query { user(id: ID!): User }
+type User { id: ID! name: String }
+extend type User { email: String }`
+	changes, err := Diff(parse(t, src), parse(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
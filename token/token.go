@@ -1,5 +1,7 @@
 package token
 
+import "strconv"
+
 // TokenType represents the type of a token in the GraphQL lexer.
 type TokenType string
 
@@ -11,27 +13,55 @@ const (
 	// Identifiers and literals
 	IDENT  TokenType = "IDENT"  // Identifiers (field names, type names, etc.)
 	INT    TokenType = "INT"    // Integer literals
+	FLOAT  TokenType = "FLOAT"  // Float literals
 	STRING TokenType = "STRING" // String literals
 
 	// Symbols
-	ASSIGN    TokenType = "="  // Assignment operator
-	COLON     TokenType = ":"  // Colon separator
-	COMMA     TokenType = ","  // Comma separator
-	SEMICOLON TokenType = ";"  // Semicolon separator
-	LPAREN    TokenType = "("  // Left parenthesis
-	RPAREN    TokenType = ")"  // Right parenthesis
-	LBRACE    TokenType = "{"  // Left brace
-	RBRACE    TokenType = "}"  // Right brace
-	LBRACKET  TokenType = "["  // Left bracket
-	RBRACKET  TokenType = "]"  // Right bracket
+	ASSIGN    TokenType = "=" // Assignment operator
+	COLON     TokenType = ":" // Colon separator
+	COMMA     TokenType = "," // Comma separator
+	SEMICOLON TokenType = ";" // Semicolon separator
+	LPAREN    TokenType = "(" // Left parenthesis
+	RPAREN    TokenType = ")" // Right parenthesis
+	LBRACE    TokenType = "{" // Left brace
+	RBRACE    TokenType = "}" // Right brace
+	LBRACKET  TokenType = "[" // Left bracket
+	RBRACKET  TokenType = "]" // Right bracket
 
 	// GraphQL extras
-	DOLLAR TokenType = "$" // Variable prefix
-	BANG   TokenType = "!" // Non-null marker
+	DOLLAR TokenType = "$"   // Variable prefix
+	BANG   TokenType = "!"   // Non-null marker
+	AT     TokenType = "@"   // Directive prefix
+	SPREAD TokenType = "..." // Fragment spread
+	PIPE   TokenType = "|"   // Union member separator
+	AMP    TokenType = "&"   // Implemented interface separator
 )
 
 // Token represents a single token in the GraphQL source.
 type Token struct {
 	Type    TokenType // The type of the token
 	Literal string    // The literal value of the token
+	Pos     Position  // Position of the token's first character
+	End     Position  // Position just past the token's last character
+}
+
+// Position is a location in GraphQL source. Lines and columns start at 1;
+// columns count characters, not bytes.
+type Position struct {
+	Offset int // Byte offset from the start of the source
+	Line   int // Line number
+	Column int // Column number
+}
+
+// IsValid reports whether the position is known.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "line:column", or "-" if it is unknown.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
 }
@@ -0,0 +1,24 @@
+// Package transport defines the connection abstraction used to stream
+// GraphQL subscription events to clients. Keeping it free of net/http and
+// any concrete WebSocket library lets the core packages (lexer, parser,
+// executor) build for targets such as GOOS=js GOARCH=wasm.
+package transport
+
+// Conn is a message-oriented, bidirectional connection to a single client.
+type Conn interface {
+	// ReadMessage blocks until the next message from the client arrives.
+	ReadMessage() ([]byte, error)
+	// WriteMessage sends a raw text message to the client.
+	WriteMessage(data []byte) error
+	// WriteJSON encodes v as JSON and sends it to the client.
+	WriteJSON(v interface{}) error
+	// Close closes the underlying connection.
+	Close() error
+}
+
+// ReadLimiter is implemented by connections that can bound the size of the
+// messages they read. Reading a larger message fails and closes the
+// connection.
+type ReadLimiter interface {
+	SetReadLimit(n int64)
+}
@@ -0,0 +1,65 @@
+// Package websocket implements the transport.Conn abstraction on top of
+// gorilla/websocket.
+package websocket
+
+import (
+	"net/http"
+
+	"github.com/Protocol-Lattice/graphql/transport"
+	"github.com/gorilla/websocket"
+)
+
+// Upgrader upgrades HTTP connections to WebSocket connections.
+type Upgrader struct {
+	upgrader websocket.Upgrader
+}
+
+// New creates an Upgrader that accepts connections from any origin.
+func New() *Upgrader {
+	return &Upgrader{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Upgrade upgrades the HTTP request to a WebSocket connection.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (transport.Conn, error) {
+	c, err := u.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c: c}, nil
+}
+
+// conn adapts a gorilla WebSocket connection to transport.Conn.
+type conn struct {
+	c *websocket.Conn
+}
+
+// ReadMessage reads the next message from the WebSocket.
+func (c *conn) ReadMessage() ([]byte, error) {
+	_, msg, err := c.c.ReadMessage()
+	return msg, err
+}
+
+// WriteMessage writes a text message to the WebSocket.
+func (c *conn) WriteMessage(data []byte) error {
+	return c.c.WriteMessage(websocket.TextMessage, data)
+}
+
+// WriteJSON writes v as a JSON text message to the WebSocket.
+func (c *conn) WriteJSON(v interface{}) error {
+	return c.c.WriteJSON(v)
+}
+
+// SetReadLimit limits the size of the messages read to n bytes; a larger
+// message closes the connection with a message too big close frame.
+func (c *conn) SetReadLimit(n int64) {
+	c.c.SetReadLimit(n)
+}
+
+// Close closes the WebSocket connection.
+func (c *conn) Close() error {
+	return c.c.Close()
+}
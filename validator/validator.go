@@ -0,0 +1,240 @@
+// Package validator checks GraphQL operations against a schema parsed from SDL.
+package validator
+
+import (
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/errcode"
+)
+
+// Validate checks every operation in doc against schema and returns all
+// violations found as *errcode.Error values. A nil result means the
+// document is valid. Type extensions in schema are merged into the types
+// they extend.
+func Validate(schema, doc *ast.Document) []error {
+	if merged, err := ast.MergeExtensions(schema); err == nil {
+		schema = merged
+	}
+	v := &validator{
+		schema:    schema,
+		types:     make(map[string]*ast.TypeDefinition),
+		inputs:    map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true},
+		fragments: make(map[string]*ast.FragmentDefinition),
+	}
+	for _, def := range schema.Definitions {
+		switch def := def.(type) {
+		case *ast.TypeDefinition:
+			v.types[def.Name] = def
+		case *ast.InterfaceTypeDefinition:
+			// Fields selected on an interface are looked up like an object's.
+			v.types[def.Name] = &ast.TypeDefinition{Name: def.Name, Fields: def.Fields}
+		case *ast.UnionTypeDefinition:
+			// Unions have no fields of their own; members are selected
+			// through fragments.
+			v.types[def.Name] = &ast.TypeDefinition{Name: def.Name}
+		case *ast.ScalarTypeDefinition:
+			v.inputs[def.Name] = true
+		case *ast.EnumTypeDefinition:
+			v.inputs[def.Name] = true
+		case *ast.InputObjectTypeDefinition:
+			v.inputs[def.Name] = true
+		}
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			v.fragments[frag.Name] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			v.validateOperation(op)
+		}
+	}
+	return v.errors
+}
+
+// validator accumulates errors while walking a document.
+type validator struct {
+	schema    *ast.Document
+	types     map[string]*ast.TypeDefinition
+	inputs    map[string]bool // names of the types variables may have
+	fragments map[string]*ast.FragmentDefinition
+	validated map[string]bool // fragments validated in the operation
+	vars      map[string]bool
+	errors    []error
+}
+
+// report records a validation error with the given code and message
+// parameters (alternating names and values).
+func (v *validator) report(code errcode.Code, params ...interface{}) {
+	v.errors = append(v.errors, errcode.New(code, params...))
+}
+
+// validateOperation validates a single operation against its root type.
+func (v *validator) validateOperation(op *ast.OperationDefinition) {
+	rootName := v.schema.RootTypeName(op.Operation)
+	root, ok := v.types[rootName]
+	if !ok {
+		v.report(errcode.OperationNotSupported, "operation", op.Operation)
+		return
+	}
+	v.vars = make(map[string]bool)
+	v.validated = make(map[string]bool)
+	for _, varDef := range op.VariableDefinitions {
+		v.vars[varDef.Variable] = true
+		if name := varDef.Type.NamedType(); !v.inputs[name] {
+			v.report(errcode.UnknownType, "type", name)
+		}
+	}
+	if op.SelectionSet != nil {
+		v.validateSelectionSet(root, op.SelectionSet)
+	}
+}
+
+// validateSelectionSet validates each field selected on parent.
+func (v *validator) validateSelectionSet(parent *ast.TypeDefinition, ss *ast.SelectionSet) {
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *ast.FragmentSpread:
+			v.validateFragmentSpread(sel)
+			continue
+		case *ast.InlineFragment:
+			v.validateInlineFragment(parent, sel)
+			continue
+		}
+		field, ok := sel.(*ast.Field)
+		if !ok {
+			continue
+		}
+		for _, arg := range field.Arguments {
+			v.validateValue(arg.Value)
+		}
+		if field.Name == "__typename" {
+			// Selectable on every composite type.
+			if field.SelectionSet != nil {
+				v.report(errcode.SelectionNotAllowed, "field", field.Name, "type", "String!")
+			}
+			continue
+		}
+		def := parent.Field(field.Name)
+		if def == nil {
+			v.report(errcode.FieldNotFound, "field", field.Name, "type", parent.Name)
+			continue
+		}
+		v.validateArguments(parent, field, def)
+		if def.Type == nil {
+			continue
+		}
+		fieldType, composite := v.types[def.Type.NamedType()]
+		switch {
+		case composite && field.SelectionSet == nil:
+			v.report(errcode.SelectionRequired, "field", field.Name, "type", def.Type.String())
+		case !composite && field.SelectionSet != nil:
+			v.report(errcode.SelectionNotAllowed, "field", field.Name, "type", def.Type.String())
+		case composite:
+			v.validateSelectionSet(fieldType, field.SelectionSet)
+		}
+	}
+}
+
+// validateFragmentSpread validates the selection of the spread fragment
+// against its type condition. Each fragment is validated once per
+// operation however often it is spread, which also breaks cycles.
+func (v *validator) validateFragmentSpread(spread *ast.FragmentSpread) {
+	frag, ok := v.fragments[spread.Name]
+	if !ok {
+		v.report(errcode.FragmentNotFound, "fragment", spread.Name)
+		return
+	}
+	if v.validated[frag.Name] {
+		return
+	}
+	v.validated[frag.Name] = true
+	typeDef, ok := v.types[frag.TypeCondition]
+	if !ok {
+		v.report(errcode.UnknownType, "type", frag.TypeCondition)
+		return
+	}
+	if frag.SelectionSet != nil {
+		v.validateSelectionSet(typeDef, frag.SelectionSet)
+	}
+}
+
+// validateInlineFragment validates the selection of an inline fragment
+// against its type condition, or against parent if it has none.
+func (v *validator) validateInlineFragment(parent *ast.TypeDefinition, inline *ast.InlineFragment) {
+	typeDef := parent
+	if inline.TypeCondition != "" {
+		var ok bool
+		if typeDef, ok = v.types[inline.TypeCondition]; !ok {
+			v.report(errcode.UnknownType, "type", inline.TypeCondition)
+			return
+		}
+	}
+	if inline.SelectionSet != nil {
+		v.validateSelectionSet(typeDef, inline.SelectionSet)
+	}
+}
+
+// validateArguments reports arguments of field that def does not declare,
+// and non-null arguments of def that field omits or sets to null, unless
+// the argument declares a default value.
+func (v *validator) validateArguments(parent *ast.TypeDefinition, field, def *ast.Field) {
+	for _, arg := range field.Arguments {
+		if lookupArgumentDefinition(def, arg.Name) == nil {
+			v.report(errcode.ArgumentNotFound, "field", field.Name, "argument", arg.Name, "type", parent.Name)
+		}
+	}
+	for _, argDef := range def.ArgumentDefinitions {
+		if argDef.Type == nil || !argDef.Type.NonNull || argDef.DefaultValue != nil {
+			continue
+		}
+		arg := lookupArgument(field, argDef.Name)
+		switch {
+		case arg == nil:
+			v.report(errcode.ArgumentRequired, "field", field.Name, "argument", argDef.Name, "type", argDef.Type.String())
+		case arg.Value != nil && arg.Value.Kind == "Null":
+			v.report(errcode.ArgumentNull, "field", field.Name, "argument", argDef.Name, "type", argDef.Type.String())
+		}
+	}
+}
+
+// validateValue checks that every variable used in val is defined by the operation.
+func (v *validator) validateValue(val *ast.Value) {
+	if val == nil {
+		return
+	}
+	switch val.Kind {
+	case "Variable":
+		if !v.vars[val.Literal] {
+			v.report(errcode.VariableNotDefined, "variable", val.Literal)
+		}
+	case "Object":
+		for _, fieldVal := range val.ObjectFields {
+			v.validateValue(fieldVal)
+		}
+	case "Array":
+		for _, elem := range val.List {
+			v.validateValue(elem)
+		}
+	}
+}
+
+// lookupArgumentDefinition finds the argument named name declared by def.
+func lookupArgumentDefinition(def *ast.Field, name string) *ast.InputValueDefinition {
+	for _, argDef := range def.ArgumentDefinitions {
+		if argDef.Name == name {
+			return argDef
+		}
+	}
+	return nil
+}
+
+// lookupArgument finds the argument named name on field.
+func lookupArgument(field *ast.Field, name string) *ast.Argument {
+	for i := range field.Arguments {
+		if field.Arguments[i].Name == name {
+			return &field.Arguments[i]
+		}
+	}
+	return nil
+}
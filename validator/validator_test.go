@@ -0,0 +1,181 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Protocol-Lattice/graphql/ast"
+	"github.com/Protocol-Lattice/graphql/lexer"
+	"github.com/Protocol-Lattice/graphql/parser"
+)
+
+const testSchema = `type Query {
+  user(id: ID!): User
+  users(first: Int! = 10, after: String): [User]
+}
+
+type User {
+  id: ID!
+  name: String
+}
+`
+
+func parse(t *testing.T, src string) *ast.Document {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return doc
+}
+
+func TestValidateRequiredArguments(t *testing.T) {
+	schema := parse(t, testSchema)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user(id: "1") { id } }`, ""},
+		{`{ users { id } }`, ""},
+		{`{ user { id } }`, `field "user" argument "id" of type "ID!" is required but not provided`},
+	}
+	for _, tt := range tests {
+		errs := Validate(schema, parse(t, tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+}
+
+func TestValidateFragments(t *testing.T) {
+	schema := parse(t, testSchema)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user(id: "1") { ...F } } fragment F on User { id name }`, ""},
+		{`{ user(id: "1") { ...F } } fragment F on User { ...F id }`, ""},
+		{`{ user(id: "1") { ...Missing } }`, `unknown fragment "Missing"`},
+		{`{ user(id: "1") { ...F } } fragment F on Account { id }`, `unknown type "Account"`},
+		{`{ user(id: "1") { ...F } } fragment F on User { email }`, `cannot query field "email" on type "User"`},
+		{`{ user(id: "1") { ... { id } ... on User { name } } }`, ""},
+		{`{ user(id: "1") { ... on User { email } } }`, `cannot query field "email" on type "User"`},
+		{`{ user(id: "1") { ... on Account { id } } }`, `unknown type "Account"`},
+	}
+	for _, tt := range tests {
+		errs := Validate(schema, parse(t, tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+}
+
+func TestValidateSpreadsFragmentsOnce(t *testing.T) {
+	// Each fragment spreads the next twice, so expanding every spread
+	// would validate the last fragment 2^25 times.
+	var sb strings.Builder
+	sb.WriteString(`{ user(id: "1") { ...F0 } }`)
+	for i := 0; i < 26; i++ {
+		fmt.Fprintf(&sb, " fragment F%d on User { email", i)
+		if i < 25 {
+			fmt.Fprintf(&sb, " ...F%d ...F%d", i+1, i+1)
+		}
+		sb.WriteString(" }")
+	}
+	start := time.Now()
+	errs := Validate(parse(t, testSchema), parse(t, sb.String()))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected each fragment to be validated once, took %v", elapsed)
+	}
+	if len(errs) != 26 {
+		t.Errorf("expected one error per fragment, got %d", len(errs))
+	}
+}
+
+func TestValidateInterfaceSelections(t *testing.T) {
+	schema := parse(t, `interface Node { id: ID! }
+type Query { node(id: ID!): Node }
+type User implements Node { id: ID! name: String }`)
+	if errs := Validate(schema, parse(t, `{ node(id: "1") { id ... on User { name } } }`)); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	errs := Validate(schema, parse(t, `{ node(id: "1") { name } }`))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `cannot query field "name" on type "Node"`) {
+		t.Errorf("expected field error on the interface, got %v", errs)
+	}
+}
+
+func TestValidateUnionSelections(t *testing.T) {
+	schema := parse(t, `union SearchResult = User | Post
+type Query { search: [SearchResult] }
+type User { name: String }
+type Post { title: String }`)
+	if errs := Validate(schema, parse(t, `{ __typename search { __typename ... on User { name } ... on Post { title } } }`)); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	tests := map[string]string{
+		`{ search }`:          `field "search" of type "[SearchResult]" must have a selection of subfields`,
+		`{ search { name } }`: `cannot query field "name" on type "SearchResult"`,
+	}
+	for query, want := range tests {
+		errs := Validate(schema, parse(t, query))
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), want) {
+			t.Errorf("%s: expected %q, got %v", query, want, errs)
+		}
+	}
+}
+
+func TestValidateCustomRootTypes(t *testing.T) {
+	schema := parse(t, `schema { query: RootQuery }
+type RootQuery { hello: String }
+type Mutation { ignored: String }`)
+	if errs := Validate(schema, parse(t, `{ hello }`)); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	errs := Validate(schema, parse(t, `mutation { ignored }`))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "schema is not configured for mutation operations") {
+		t.Errorf("expected undeclared mutation root to be rejected, got %v", errs)
+	}
+}
+
+func TestValidateArgumentsAndVariables(t *testing.T) {
+	schema := parse(t, testSchema+`enum Role { ADMIN }
+input Filter { role: Role }
+`)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`query ($id: ID!, $r: Role, $f: Filter) { user(id: $id) { id } }`, ""},
+		{`{ user(id: "1", name: "x") { id } }`, `unknown argument "name" on field "Query.user"`},
+		{`query ($id: Identifier!) { user(id: $id) { id } }`, `unknown type "Identifier"`},
+		{`query ($u: User) { user(id: "1") { id } }`, `unknown type "User"`},
+	}
+	for _, tt := range tests {
+		errs := Validate(schema, parse(t, tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+}
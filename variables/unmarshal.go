@@ -0,0 +1,168 @@
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Unmarshal stores the values of a variables or arguments map in the
+// struct or map pointed to by v. It is the inverse of Marshal: struct
+// fields are named the same way, embedded structs are flattened, RFC 3339
+// strings fill time.Time fields and file maps fill Upload fields. Values
+// are converted to the field types where possible, such as float64 to int;
+// other conversions go through encoding/json, so types implementing
+// json.Unmarshaler are honored. Keys without a matching field are ignored.
+func Unmarshal(vars map[string]interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("variables: cannot unmarshal into non-pointer %T", v)
+	}
+	if err := assign(rv.Elem(), vars); err != nil {
+		return fmt.Errorf("variables: %w", err)
+	}
+	return nil
+}
+
+// assign stores the variables value src in dst.
+func assign(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.SetZero()
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	switch {
+	case sv.Type().AssignableTo(dst.Type()):
+		dst.Set(sv)
+		return nil
+	case dst.Kind() == reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := assign(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case dst.Type() == timeType:
+		s, ok := src.(string)
+		if !ok {
+			break
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("%q is not an RFC 3339 time", s)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case dst.Type() == uploadType:
+		m, ok := src.(map[string]interface{})
+		if !ok {
+			break
+		}
+		filename, _ := m["filename"].(string)
+		data, _ := m["data"].([]byte)
+		dst.Set(reflect.ValueOf(Upload{Filename: filename, Data: data}))
+		return nil
+	case dst.Kind() == reflect.Struct:
+		m, ok := src.(map[string]interface{})
+		if !ok {
+			break
+		}
+		return assignStruct(dst, m)
+	case dst.Kind() == reflect.Map && dst.Type().Key().Kind() == reflect.String:
+		m, ok := src.(map[string]interface{})
+		if !ok {
+			break
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(m))
+		for key, value := range m {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(elem, value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			out.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(out)
+		return nil
+	case dst.Kind() == reflect.Slice:
+		list, ok := src.([]interface{})
+		if !ok {
+			break
+		}
+		out := reflect.MakeSlice(dst.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := assign(out.Index(i), item); err != nil {
+				return fmt.Errorf("%d: %w", i, err)
+			}
+		}
+		dst.Set(out)
+		return nil
+	case isNumber(sv.Kind()) && isNumber(dst.Kind()):
+		converted := sv.Convert(dst.Type())
+		if !sv.Equal(converted.Convert(sv.Type())) {
+			return fmt.Errorf("%v cannot be represented as %s", src, dst.Type())
+		}
+		dst.Set(converted)
+		return nil
+	case sv.Kind() == dst.Kind() && sv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(sv.Convert(dst.Type()))
+		return nil
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst.Addr().Interface()); err != nil {
+		return fmt.Errorf("cannot unmarshal %T into %s", src, dst.Type())
+	}
+	return nil
+}
+
+// assignStruct stores the entries of m in the exported fields of the struct
+// dst, flattening embedded structs without a name tag.
+func assignStruct(dst reflect.Value, m map[string]interface{}) error {
+	typ := dst.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name, _, tagged := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fv := dst.Field(i)
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if !sf.IsExported() {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(ft))
+				}
+				fv = fv.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType && ft != uploadType {
+				if err := assignStruct(fv, m); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		value, ok := m[name]
+		if !ok {
+			continue
+		}
+		if err := assign(fv, value); err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// isNumber reports whether values of kind k are integers or floats.
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
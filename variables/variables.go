@@ -0,0 +1,218 @@
+// Package variables converts Go values to and from the map[string]interface{}
+// form used for GraphQL operation variables and resolver arguments.
+package variables
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Upload is a file value. It marshals to the same shape the multipart upload
+// handler injects into variables, so resolvers see identical input whether a
+// file arrives over HTTP or from a test.
+type Upload struct {
+	Filename string
+	Data     []byte
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uploadType        = reflect.TypeOf(Upload{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Marshal converts a struct (or pointer to struct, or map with string keys)
+// into a variables map.
+//
+// Struct fields are named by their `graphql` tag, then their `json` tag,
+// and otherwise by the Go field name in lower camel case ("UserID" becomes
+// "userID"). A tag of "-" skips the field and the "omitempty" option is
+// honored. time.Time values become RFC 3339 strings, Upload values become
+// file maps, and types implementing json.Marshaler or
+// encoding.TextMarshaler are converted through those interfaces.
+func Marshal(v interface{}) (map[string]interface{}, error) {
+	out, err := convert(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return map[string]interface{}{}, nil
+	}
+	m, ok := out.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("variables: cannot marshal %T into a variables map", v)
+	}
+	return m, nil
+}
+
+// convert converts a reflected value into its variables representation.
+func convert(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Type() {
+	case timeType:
+		return rv.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case uploadType:
+		u := rv.Interface().(Upload)
+		return map[string]interface{}{"filename": u.Filename, "data": u.Data}, nil
+	}
+	if rv.Type().Implements(jsonMarshalerType) {
+		data, err := rv.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var out interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		if err := convertStruct(rv, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("variables: map key type %s is not a string", rv.Type().Key())
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem, err := convert(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[iter.Key().String()] = elem
+		}
+		return m, nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			elem, err := convert(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case reflect.Func, reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return nil, fmt.Errorf("variables: unsupported type %s", rv.Type())
+	}
+	return rv.Interface(), nil
+}
+
+// convertStruct adds the exported fields of the struct rv to m, flattening
+// embedded structs without a name tag.
+func convertStruct(rv reflect.Value, m map[string]interface{}) error {
+	typ := rv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name, omitEmpty, tagged := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType && ft != uploadType {
+				if err := convertStruct(fv, m); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		val, err := convert(fv)
+		if err != nil {
+			return fmt.Errorf("variables: field %s: %v", sf.Name, err)
+		}
+		m[name] = val
+	}
+	return nil
+}
+
+// fieldName returns the variable name for a struct field, whether the
+// omitempty option is set, and whether the name came from a tag.
+func fieldName(sf reflect.StructField) (name string, omitEmpty, tagged bool) {
+	for _, key := range []string{"graphql", "json"} {
+		tag, ok := sf.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		if parts[0] != "" {
+			return parts[0], omitEmpty, true
+		}
+	}
+	return lowerCamel(sf.Name), omitEmpty, false
+}
+
+// lowerCamel lowercases the leading initialism or letter of a Go identifier:
+// "Name" -> "name", "ID" -> "id", "UserID" -> "userID", "HTTPServer" -> "httpServer".
+func lowerCamel(s string) string {
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n-- // keep the start of the next word capitalized
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
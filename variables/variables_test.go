@@ -0,0 +1,100 @@
+package variables
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Zip string `json:"zip"`
+}
+
+type input struct {
+	address
+	UserID    string `json:"user_id" graphql:"userID"`
+	Name      string `json:"name,omitempty"`
+	Age       int
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+	Avatar    *Upload   `json:"avatar"`
+	Secret    string    `json:"-"`
+	internal  string
+}
+
+func TestMarshal(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := Marshal(&input{
+		address:   address{Zip: "00-001"},
+		UserID:    "42",
+		Age:       30,
+		Tags:      []string{"a", "b"},
+		CreatedAt: created,
+		Avatar:    &Upload{Filename: "me.png", Data: []byte("png")},
+		Secret:    "hidden",
+		internal:  "x",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"zip":       "00-001",
+		"userID":    "42",
+		"age":       30,
+		"tags":      []interface{}{"a", "b"},
+		"createdAt": "2024-01-02T03:04:05Z",
+		"avatar":    map[string]interface{}{"filename": "me.png", "data": []byte("png")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected variables:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestMarshalRejectsScalar(t *testing.T) {
+	if _, err := Marshal(5); err == nil {
+		t.Error("expected error marshaling a scalar")
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	for in, want := range map[string]string{"Name": "name", "ID": "id", "UserID": "userID", "HTTPServer": "httpServer", "x": "x"} {
+		if got := lowerCamel(in); got != want {
+			t.Errorf("lowerCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var got input
+	err := Unmarshal(map[string]interface{}{
+		"zip":       "00-001",
+		"userID":    "42",
+		"age":       30.0,
+		"tags":      []interface{}{"a", "b"},
+		"createdAt": "2024-01-02T03:04:05Z",
+		"avatar":    map[string]interface{}{"filename": "me.png", "data": []byte("png")},
+		"Secret":    "ignored",
+	}, &got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := input{
+		address:   address{Zip: "00-001"},
+		UserID:    "42",
+		Age:       30,
+		Tags:      []string{"a", "b"},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Avatar:    &Upload{Filename: "me.png", Data: []byte("png")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected value:\n got %#v\nwant %#v", got, want)
+	}
+
+	var bad input
+	if err := Unmarshal(map[string]interface{}{"age": 1.5}, &bad); err == nil || err.Error() != "variables: field Age: 1.5 cannot be represented as int" {
+		t.Errorf("unexpected error for a fractional int: %v", err)
+	}
+	if err := Unmarshal(nil, bad); err == nil {
+		t.Error("expected error unmarshaling into a non-pointer")
+	}
+}